@@ -12,7 +12,6 @@ import (
 	"github.com/gen2brain/beeep"
 	"github.com/wailsapp/wails/v2/pkg/options"
 	"github.com/wailsapp/wails/v2/pkg/runtime"
-	"golang.org/x/sys/windows"
 )
 
 // App struct
@@ -36,6 +35,10 @@ func (a *App) startup(ctx context.Context) {
 
 	runtime.LogInfo(appContext, "Starting application")
 
+	for _, warning := range configWarnings {
+		runtime.LogWarning(appContext, warning)
+	}
+
 	// Set window position
 	if *config.WindowStartPositionX != -100000 && *config.WindowStartPositionY != -100000 {
 		runtime.LogInfo(appContext, "Setting window position")
@@ -151,7 +154,8 @@ func (a *App) beforeClose(ctx context.Context) (prevent bool) {
 
 // shutdown is called at application termination
 func (a *App) shutdown(ctx context.Context) {
-	// Perform your teardown here
+	closeParselSorgu()
+	closeBrowserSession()
 }
 
 // onSecondInstanceLaunch is called when the application is launched from a second instance
@@ -213,32 +217,14 @@ func (a *App) RestartApplication(admin bool, args []string) error {
 	}
 
 	if admin {
-		verb := "runas"
-		showCmd := 1 // SW_NORMAL
 		runtime.LogDebug(a.ctx, "Attempting to restart with elevated privileges")
 
-		executablePtr, err := windows.UTF16PtrFromString(executable)
-		if err != nil {
-			runtime.LogError(a.ctx, "failed to convert executable path to UTF16: "+err.Error())
-			return err
-		}
-
-		// Convert arguments to a single string
-		argStr := strings.Join(args, " ")
-
-		argPtr, err := windows.UTF16PtrFromString(argStr)
+		err = restartElevated(executable, args)
 		if err != nil {
-			runtime.LogError(a.ctx, "failed to convert arguments to UTF16: "+err.Error())
+			runtime.LogError(a.ctx, "failed to restart with elevated privileges: "+err.Error())
 			return err
 		}
 
-		// Execute with elevated privileges
-		err = windows.ShellExecute(0, windows.StringToUTF16Ptr(verb), executablePtr, argPtr, nil, int32(showCmd))
-		if err != nil {
-			runtime.LogError(a.ctx, "ShellExecute failed: "+err.Error())
-			return fmt.Errorf("ShellExecute failed: %w", err)
-		}
-
 		runtime.LogDebug(a.ctx, "Successfully requested elevated privileges")
 		a.beforeClose(a.ctx)
 
@@ -28,7 +28,7 @@ func path_init() error {
 
 	appFolder = path.Join(appData, "folder-creator")
 
-	pdfToTextPath = path.Join(appFolder, "xpdf-tools", "xpdf-tools-win-4.05", "bin64", "pdftotext.exe")
+	pdfToTextPath = defaultPdfToTextPath()
 
 	logsFolder = path.Join(appFolder, "logs")
 	savedConfigFolder = path.Join(appFolder, "savedconfigs")
@@ -68,7 +68,7 @@ func path_init() error {
 }
 
 func get_logs_folder() (string, error) {
-	logsFolder = path.Join(os.Getenv("APPDATA"), "folder-creator", "logs")
+	logsFolder = path.Join(get_user_config_dir(), "folder-creator", "logs")
 
 	// Create folder if it doesn't exist
 	if _, err := os.Stat(logsFolder); os.IsNotExist(err) {
@@ -81,11 +81,21 @@ func get_logs_folder() (string, error) {
 }
 
 func get_config_path() string {
-	configPath = path.Join(os.Getenv("APPDATA"), "folder-creator", "config.json")
+	configPath = path.Join(get_user_config_dir(), "folder-creator", "config.json")
 
 	return configPath
 }
 
+// get_user_config_dir returns %APPDATA% on Windows and the XDG config directory elsewhere
+func get_user_config_dir() string {
+	appData, err := os.UserConfigDir()
+	if err != nil {
+		return os.Getenv("APPDATA")
+	}
+
+	return appData
+}
+
 // Create folder if it doesn't exist, return error
 func create_folder(folder string) error {
 	if _, err := os.Stat(folder); os.IsNotExist(err) {
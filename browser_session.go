@@ -0,0 +1,318 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/chromedp/cdproto/browser"
+	"github.com/chromedp/chromedp"
+	"github.com/wailsapp/wails/v2/pkg/runtime"
+)
+
+const parselSorguURL = "https://parselsorgu.tkgm.gov.tr/"
+
+// browserSession is a Chrome instance kept open across rows and runs with a pool of tabs on the
+// parcel query site. Each tab has its own browser context so downloads don't mix.
+type browserSession struct {
+	mu       sync.Mutex
+	headless bool
+	size     int
+
+	browserCtx    context.Context
+	cancelAlloc   context.CancelFunc
+	cancelBrowser context.CancelFunc
+
+	idle    chan *browserTab
+	created int
+	nextID  int
+	closed  bool
+}
+
+// browserTab is a page of the session with its own download directory
+type browserTab struct {
+	id          int
+	ctx         context.Context
+	cancel      context.CancelFunc
+	downloadDir string
+
+	// The page is reloaded before its next use, after a query left it showing a result
+	dirty bool
+}
+
+var (
+	sharedBrowserSessionMu sync.Mutex
+	sharedBrowserSession   *browserSession
+)
+
+// getBrowserSession returns the shared session, starting a new one if there is none, it crashed
+// or it was started with another headless setting or pool size
+func getBrowserSession(headless bool, size int) (*browserSession, error) {
+	sharedBrowserSessionMu.Lock()
+	defer sharedBrowserSessionMu.Unlock()
+
+	if s := sharedBrowserSession; s != nil {
+		if s.headless == headless && s.size == size && s.alive() {
+			return s, nil
+		}
+
+		runtime.LogInfo(appContext, "Restarting browser session")
+		s.Close()
+		sharedBrowserSession = nil
+	}
+
+	s, err := newBrowserSession(headless, size)
+	if err != nil {
+		return nil, err
+	}
+
+	sharedBrowserSession = s
+
+	return s, nil
+}
+
+// closeBrowserSession closes the shared session, if any
+func closeBrowserSession() {
+	sharedBrowserSessionMu.Lock()
+	defer sharedBrowserSessionMu.Unlock()
+
+	if sharedBrowserSession != nil {
+		sharedBrowserSession.Close()
+		sharedBrowserSession = nil
+	}
+}
+
+func newBrowserSession(headless bool, size int) (*browserSession, error) {
+	if size < 1 {
+		size = 1
+	}
+
+	s := &browserSession{headless: headless, size: size, idle: make(chan *browserTab, size)}
+
+	opts := append(chromedp.DefaultExecAllocatorOptions[:],
+		chromedp.Flag("headless", headless),
+		chromedp.Flag("disable-gpu", headless),
+	)
+
+	var allocCtx context.Context
+	allocCtx, s.cancelAlloc = chromedp.NewExecAllocator(context.Background(), opts...)
+	s.browserCtx, s.cancelBrowser = chromedp.NewContext(allocCtx, chromedp.WithLogf(log.Printf))
+
+	// Start the browser, tabs are opened on demand
+	if err := chromedp.Run(s.browserCtx); err != nil {
+		s.Close()
+		return nil, fmt.Errorf("failed to start browser: %w", err)
+	}
+
+	return s, nil
+}
+
+// alive reports whether the browser process is still running
+func (s *browserSession) alive() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return !s.closed && s.browserCtx.Err() == nil
+}
+
+// acquire returns an idle tab, opening a new one while the pool isn't full, and waits otherwise
+func (s *browserSession) acquire(ctx context.Context) (*browserTab, error) {
+	for {
+		var tab *browserTab
+
+		select {
+		case tab = <-s.idle:
+		default:
+			s.mu.Lock()
+			canCreate := !s.closed && s.created < s.size
+			if canCreate {
+				s.created++
+			}
+			s.mu.Unlock()
+
+			if canCreate {
+				tab, err := s.openTab()
+				if err != nil {
+					s.discard(nil)
+					return nil, err
+				}
+				return tab, nil
+			}
+
+			select {
+			case tab = <-s.idle:
+			case <-ctx.Done():
+				return nil, ctx.Err()
+			}
+		}
+
+		if !s.alive() {
+			s.discard(tab)
+			return nil, newParselError(ParselErrorUnavailable, nil, "browser closed")
+		}
+
+		if err := s.check(tab); err != nil {
+			runtime.LogWarning(appContext, fmt.Sprintf("Browser tab %d failed health check: %s", tab.id, err.Error()))
+			s.discard(tab)
+			continue
+		}
+
+		return tab, nil
+	}
+}
+
+// release returns tab to the pool, a used tab is reloaded before it is used again
+func (s *browserSession) release(tab *browserTab, used bool) {
+	tab.dirty = tab.dirty || used
+
+	s.mu.Lock()
+	closed := s.closed
+	s.mu.Unlock()
+
+	if closed {
+		tab.cancel()
+		return
+	}
+
+	s.idle <- tab
+}
+
+// discard closes a broken tab and frees its place in the pool
+func (s *browserSession) discard(tab *browserTab) {
+	if tab != nil {
+		tab.cancel()
+		os.RemoveAll(tab.downloadDir)
+	}
+
+	s.mu.Lock()
+	s.created--
+	s.mu.Unlock()
+}
+
+func (s *browserSession) openTab() (*browserTab, error) {
+	s.mu.Lock()
+	s.nextID++
+	id := s.nextID
+	s.mu.Unlock()
+
+	tab := &browserTab{id: id, downloadDir: filepath.Join(appFolder, "downloads", fmt.Sprint(id))}
+
+	if err := os.MkdirAll(tab.downloadDir, 0755); err != nil {
+		return nil, err
+	}
+
+	tab.ctx, tab.cancel = chromedp.NewContext(s.browserCtx, chromedp.WithNewBrowserContext())
+
+	if err := s.prepare(tab); err != nil {
+		tab.cancel()
+		return nil, err
+	}
+
+	c := chromedp.FromContext(tab.ctx)
+
+	err := chromedp.Run(tab.ctx,
+		browser.SetDownloadBehavior(browser.SetDownloadBehaviorBehaviorAllowAndName).
+			WithBrowserContextID(c.BrowserContextID).
+			WithDownloadPath(tab.downloadDir).
+			WithEventsEnabled(true),
+	)
+	if err != nil {
+		tab.cancel()
+		return nil, err
+	}
+
+	runtime.LogInfo(appContext, fmt.Sprintf("Opened browser tab %d", id))
+
+	return tab, nil
+}
+
+// prepare loads the parcel query site and accepts its terms if they are shown
+func (s *browserSession) prepare(tab *browserTab) error {
+	ctx, cancel := context.WithTimeout(tab.ctx, 60*time.Second)
+	defer cancel()
+
+	err := chromedp.Run(ctx,
+		chromedp.Navigate(parselSorguURL),
+		chromedp.WaitReady(`body`, chromedp.ByQuery),
+		chromedp.ActionFunc(func(ctx context.Context) error {
+			// The terms dialog appears once per browser context, the form is ready without it
+			deadline := time.Now().Add(10 * time.Second)
+			for time.Now().Before(deadline) {
+				var state string
+				if err := chromedp.Evaluate(`document.querySelector("#terms-ok") ? "terms" : document.querySelector("#province-select") ? "ready" : ""`, &state).Do(ctx); err != nil {
+					return err
+				}
+				switch state {
+				case "terms":
+					return chromedp.Click(`#terms-ok`, chromedp.ByQuery).Do(ctx)
+				case "ready":
+					return nil
+				}
+				time.Sleep(250 * time.Millisecond)
+			}
+			return nil
+		}),
+	)
+	if err != nil {
+		return classifyParselError(err)
+	}
+
+	tab.dirty = false
+
+	return nil
+}
+
+// check makes sure the tab responds, reloading it if a query left it in an unknown state
+func (s *browserSession) check(tab *browserTab) error {
+	if tab.ctx.Err() != nil {
+		return tab.ctx.Err()
+	}
+
+	ctx, cancel := context.WithTimeout(tab.ctx, 5*time.Second)
+	defer cancel()
+
+	var state string
+	if err := chromedp.Run(ctx, chromedp.Evaluate(`document.readyState`, &state)); err != nil {
+		return err
+	}
+
+	if tab.dirty {
+		return s.prepare(tab)
+	}
+
+	return nil
+}
+
+// Close closes every tab and the browser
+func (s *browserSession) Close() {
+	s.mu.Lock()
+	if s.closed {
+		s.mu.Unlock()
+		return
+	}
+	s.closed = true
+	s.mu.Unlock()
+
+	for {
+		select {
+		case tab := <-s.idle:
+			tab.cancel()
+		default:
+			if s.cancelBrowser != nil {
+				if err := chromedp.Cancel(s.browserCtx); err != nil && !errors.Is(err, context.Canceled) {
+					runtime.LogWarning(appContext, "Failed to close browser: "+err.Error())
+				}
+				s.cancelBrowser()
+			}
+			if s.cancelAlloc != nil {
+				s.cancelAlloc()
+			}
+			return
+		}
+	}
+}
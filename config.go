@@ -4,6 +4,7 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"io"
 	"os"
 	"reflect"
 	"strconv"
@@ -12,51 +13,73 @@ import (
 )
 
 type Config struct {
-	Theme                   *string `json:"theme"`                   // system, light, dark
-	UseSystemTitleBar       *bool   `json:"useSystemTitleBar"`       // true, false
-	EnableLogging           *bool   `json:"enableLogging"`           // true, false
-	EnableTrace             *bool   `json:"enableTrace"`             // true, false
-	EnableDebug             *bool   `json:"enableDebug"`             // true, false
-	EnableInfo              *bool   `json:"enableInfo"`              // true, false
-	EnableWarn              *bool   `json:"enableWarn"`              // true, false
-	EnableError             *bool   `json:"enableError"`             // true, false
-	EnableFatal             *bool   `json:"enableFatal"`             // true, false
-	MaxLogFiles             *int    `json:"maxLogFiles"`             // int
-	Language                *string `json:"language"`                // en-US, tr-TR
-	SaveWindowStatus        *bool   `json:"saveWindowStatus"`        // true, false
-	WindowStartState        *int    `json:"windowStartState"`        // 0 = Normal, 1 = Maximized, 2 = Minimized, 3 = Fullscreen
-	WindowStartPositionX    *int    `json:"windowStartPositionX"`    // x
-	WindowStartPositionY    *int    `json:"windowStartPositionY"`    // y
-	WindowStartSizeX        *int    `json:"windowStartSizeX"`        // x
-	WindowStartSizeY        *int    `json:"windowStartSizeY"`        // y
-	WindowScale             *int    `json:"windowScale"`             // %
-	Opacity                 *int    `json:"opacity"`                 // %
-	WindowEffect            *int    `json:"windowEffect"`            // 0 = Auto, 1 = None, 2 = Mica, 3 = Acrylic, 4 = Tabbed
-	CheckForUpdates         *bool   `json:"checkForUpdates"`         // true, false
-	LastUpdateCheck         *int    `json:"lastUpdateCheck"`         // unix timestamp
-	FolderNamePattern       *string `json:"folderNamePattern"`       // string
-	CreateFolder            *bool   `json:"createFolder"`            // true, false
-	WordFileNamePattern     *string `json:"wordFileNamePattern"`     // string
-	FileNamePattern         *string `json:"fileNamePattern"`         // string
-	IlCellName              *string `json:"ilCellName"`              // string
-	IlceCellName            *string `json:"ilceCellName"`            // string
-	MahalleCellName         *string `json:"mahalleCellName"`         // string
-	AdaCellName             *string `json:"adaCellName"`             // string
-	ParselCellName          *string `json:"parselCellName"`          // string
-	AlanCellName            *string `json:"alanCellName"`            // string
-	PaftaCellName           *string `json:"paftaCellName"`           // string
-	ParselSorguHeadless     *bool   `json:"parselSorguHeadless"`     // true, false
-	CiltCellName            *string `json:"ciltCellName"`            // string
-	SayfaCellName           *string `json:"sayfaCellName"`           // string
-	TapuNamePattern         *string `json:"tapuNamePattern"`         // string
-	MevkiCellName           *string `json:"mevkiCellName"`           // string
-	AlanCellNameTapu        *string `json:"alanCellNameTapu"`        // string
-	ExcelHeaderMatchPattern *string `json:"excelHeaderMatchPattern"` // string
-	ExcelCellModifyPattern  *string `json:"excelCellModifyPattern"`  // string
-	MevkiCellNameSorgu      *string `json:"mevkiCellNameSorgu"`      // string
-	CinsCellName            *string `json:"cinsCellName"`            // string
-	TabId                   *string `json:"tabId"`                   // string
-	WordReplaceRules        *string `json:"wordReplaceRules"`        // string
+	Theme                   *string  `json:"theme"`                   // system, light, dark
+	UseSystemTitleBar       *bool    `json:"useSystemTitleBar"`       // true, false
+	EnableLogging           *bool    `json:"enableLogging"`           // true, false
+	EnableTrace             *bool    `json:"enableTrace"`             // true, false
+	EnableDebug             *bool    `json:"enableDebug"`             // true, false
+	EnableInfo              *bool    `json:"enableInfo"`              // true, false
+	EnableWarn              *bool    `json:"enableWarn"`              // true, false
+	EnableError             *bool    `json:"enableError"`             // true, false
+	EnableFatal             *bool    `json:"enableFatal"`             // true, false
+	MaxLogFiles             *int     `json:"maxLogFiles"`             // int
+	Language                *string  `json:"language"`                // en-US, tr-TR
+	SaveWindowStatus        *bool    `json:"saveWindowStatus"`        // true, false
+	WindowStartState        *int     `json:"windowStartState"`        // 0 = Normal, 1 = Maximized, 2 = Minimized, 3 = Fullscreen
+	WindowStartPositionX    *int     `json:"windowStartPositionX"`    // x
+	WindowStartPositionY    *int     `json:"windowStartPositionY"`    // y
+	WindowStartSizeX        *int     `json:"windowStartSizeX"`        // x
+	WindowStartSizeY        *int     `json:"windowStartSizeY"`        // y
+	WindowScale             *int     `json:"windowScale"`             // %
+	Opacity                 *int     `json:"opacity"`                 // %
+	WindowEffect            *int     `json:"windowEffect"`            // 0 = Auto, 1 = None, 2 = Mica, 3 = Acrylic, 4 = Tabbed
+	CheckForUpdates         *bool    `json:"checkForUpdates"`         // true, false
+	LastUpdateCheck         *int     `json:"lastUpdateCheck"`         // unix timestamp
+	FolderNamePattern       *string  `json:"folderNamePattern"`       // string
+	CreateFolder            *bool    `json:"createFolder"`            // true, false
+	WordFileNamePattern     *string  `json:"wordFileNamePattern"`     // string
+	FileNamePattern         *string  `json:"fileNamePattern"`         // string
+	IlCellName              *string  `json:"ilCellName"`              // string
+	IlceCellName            *string  `json:"ilceCellName"`            // string
+	MahalleCellName         *string  `json:"mahalleCellName"`         // string
+	AdaCellName             *string  `json:"adaCellName"`             // string
+	ParselCellName          *string  `json:"parselCellName"`          // string
+	ParselSorguHeadless     *bool    `json:"parselSorguHeadless"`     // true, false
+	ParselSorguBackend      *string  `json:"parselSorguBackend"`      // http, browser
+	ParselCacheTTL          *int     `json:"parselCacheTTL"`          // days, 0 disables the cache
+	ParselExportFormats     *string  `json:"parselExportFormats"`     // geojson,kml,dxf
+	ParselExportOnCreate    *bool    `json:"parselExportOnCreate"`    // true, false
+	ParselCRS               *string  `json:"parselCRS"`               // auto, EPSG:5253 - EPSG:5259, EPSG:2319 - EPSG:2325, ...
+	ParselYuzolcumCellName  *string  `json:"parselYuzolcumCellName"`  // string, tapu area compared to the computed one
+	ParselAreaTolerance     *float64 `json:"parselAreaTolerance"`     // %
+	ParselMaxRetries        *int     `json:"parselMaxRetries"`        // retries of timeouts and unavailable site
+	ParselStatusCellName    *string  `json:"parselStatusCellName"`    // string, empty to skip
+	ParselNameMinScore      *int     `json:"parselNameMinScore"`      // %, minimum similarity of il/ilçe/mahalle names
+	ParselBrowserTabs       *int     `json:"parselBrowserTabs"`       // tabs kept open by the browser backend
+	ParselConcurrency       *int     `json:"parselConcurrency"`       // rows queried at once, browser queries also wait for a free tab
+	ParselRequestsPerMinute *int     `json:"parselRequestsPerMinute"` // 0 for no limit
+	ParselXCellName         *string  `json:"parselXCellName"`         // string, longitude or easting
+	ParselYCellName         *string  `json:"parselYCellName"`         // string, latitude or northing
+	ParselPointCRS          *string  `json:"parselPointCRS"`          // string, EPSG code of the coordinate columns
+	ParselNeighborProbe     *bool    `json:"parselNeighborProbe"`     // true to query the parcels around, false for cached ones only
+	TapuNamePattern         *string  `json:"tapuNamePattern"`         // string
+	TapuFieldMapping        *string  `json:"tapuFieldMapping"`        // string
+	TapuMinConfidence       *int     `json:"tapuMinConfidence"`       // %
+	ExcelBackup             *bool    `json:"excelBackup"`             // true to copy the workbook to Yedekler before overwriting it
+	ExcelSaveToNewFile      *bool    `json:"excelSaveToNewFile"`      // true to keep the workbook and save a timestamped copy
+	ExcelCheckpointRows     *int     `json:"excelCheckpointRows"`     // rows between saves of long runs, 0 saves only at the end
+	ExcelResume             *bool    `json:"excelResume"`             // true to skip rows an interrupted run or the workbook already has
+	ExcelColumnTypes        *string  `json:"excelColumnTypes"`        // column type rules like "Ada->tamsayi,Alan (m2)->ondalik:2,Tarih->tarih"
+	TakbisReportSheet       *string  `json:"takbisReportSheet"`       // sheet the TAKBIS match report is written to, empty to skip it
+	TabId                   *string  `json:"tabId"`                   // string
+	WordReplaceRules        *string  `json:"wordReplaceRules"`        // string
+	PdfTextBackend          *string  `json:"pdfTextBackend"`          // builtin, xpdf
+
+	// Set through SetParselFields, SetTakbisMapping and the preset methods, SetConfigField only
+	// takes plain values
+	ParselFields         *[]ParselFieldRule        `json:"parselFields"`         // parcel fields written to columns
+	TakbisMapping        *TakbisMapping            `json:"takbisMapping"`        // TAKBIS match and cell change rules
+	TakbisMappingPresets *map[string]TakbisMapping `json:"takbisMappingPresets"` // saved mappings by name
 }
 
 func GetDefaultConfig() Config {
@@ -91,20 +114,39 @@ func GetDefaultConfig() Config {
 	defaultMahalleCellName := "Mahalle"
 	defaultAdaCellName := "Ada"
 	defaultParselCellName := "Parsel"
-	defaultAlanCellName := "Alan (m2)"
-	defaultPaftaCellName := "Pafta"
 	defaultParselSorguHeadless := true
-	defaultCiltCellName := "Cilt"
-	defaultSayfaCellName := "Sayfa"
+	defaultParselSorguBackend := ParselBackendHTTP
+	defaultParselCacheTTL := 30
+	defaultParselExportFormats := "geojson,kml,dxf"
+	defaultParselExportOnCreate := false
+	defaultParselCRS := "auto"
+	defaultParselYuzolcumCellName := ""
+	defaultParselAreaTolerance := 1.0
+	defaultParselMaxRetries := 3
+	defaultParselStatusCellName := ""
+	defaultParselNameMinScore := 80
+	defaultParselBrowserTabs := 1
+	defaultParselConcurrency := 1
+	defaultParselRequestsPerMinute := 30
+	defaultParselXCellName := "Boylam"
+	defaultParselYCellName := "Enlem"
+	defaultParselPointCRS := "EPSG:4326"
+	defaultParselNeighborProbe := false
 	defaultTapuNamePattern := "{Dosya No}_{{Mahalle}}_{Ada}_{Parsel}({Davacı})/Dosya_Uyap/Tapu/evrak_*.pdf"
-	defaultMevkiCellName := "Mevki"
-	defaultAlanCellNameTapu := "Alan (m2)"
-	defaultExcelHeaderMatchPattern := "Mahalle:Mahalle Ad,Ada:Ada No,Parsel:Parsel No"
-	defaultExcelCellModifyPattern := "Cins->Cins,Mevki->Mevki,Yüzölçüm->Alan (m2),Cilt No->Cilt,Sayfa No->Sayfa,Kadastro Pafta->Pafta"
-	defaultMevkiCellNameSorgu := "Mevki"
-	defaultCinsCellName := "Cins"
+	defaultTapuFieldMapping := "Cilt->Cilt,Sayfa->Sayfa,Mevki->Mevki,Alan->Alan (m2)"
+	defaultTapuMinConfidence := 50
+	defaultParselFields := initialParselFields()
+	defaultTakbisMapping := initialTakbisMapping()
+	defaultTakbisMappingPresets := map[string]TakbisMapping{}
+	defaultExcelBackup := true
+	defaultExcelSaveToNewFile := false
+	defaultExcelCheckpointRows := 25
+	defaultExcelResume := false
+	defaultExcelColumnTypes := "Alan (m2)->ondalik:2"
+	defaultTakbisReportSheet := ""
 	defaultTabId := "packs"
 	defaultWordReplaceRules := `(K)->(Köyü),(M)->""`
+	defaultPdfTextBackend := PdfTextBackendBuiltin
 
 	return Config{
 		Theme:                   &defaultTheme,
@@ -138,25 +180,47 @@ func GetDefaultConfig() Config {
 		MahalleCellName:         &defaultMahalleCellName,
 		AdaCellName:             &defaultAdaCellName,
 		ParselCellName:          &defaultParselCellName,
-		AlanCellName:            &defaultAlanCellName,
-		PaftaCellName:           &defaultPaftaCellName,
 		ParselSorguHeadless:     &defaultParselSorguHeadless,
-		CiltCellName:            &defaultCiltCellName,
-		SayfaCellName:           &defaultSayfaCellName,
+		ParselSorguBackend:      &defaultParselSorguBackend,
+		ParselCacheTTL:          &defaultParselCacheTTL,
+		ParselExportFormats:     &defaultParselExportFormats,
+		ParselExportOnCreate:    &defaultParselExportOnCreate,
+		ParselCRS:               &defaultParselCRS,
+		ParselYuzolcumCellName:  &defaultParselYuzolcumCellName,
+		ParselAreaTolerance:     &defaultParselAreaTolerance,
+		ParselMaxRetries:        &defaultParselMaxRetries,
+		ParselStatusCellName:    &defaultParselStatusCellName,
+		ParselNameMinScore:      &defaultParselNameMinScore,
+		ParselBrowserTabs:       &defaultParselBrowserTabs,
+		ParselConcurrency:       &defaultParselConcurrency,
+		ParselRequestsPerMinute: &defaultParselRequestsPerMinute,
+		ParselXCellName:         &defaultParselXCellName,
+		ParselYCellName:         &defaultParselYCellName,
+		ParselPointCRS:          &defaultParselPointCRS,
+		ParselNeighborProbe:     &defaultParselNeighborProbe,
 		TapuNamePattern:         &defaultTapuNamePattern,
-		MevkiCellName:           &defaultMevkiCellName,
-		AlanCellNameTapu:        &defaultAlanCellNameTapu,
-		ExcelHeaderMatchPattern: &defaultExcelHeaderMatchPattern,
-		ExcelCellModifyPattern:  &defaultExcelCellModifyPattern,
-		MevkiCellNameSorgu:      &defaultMevkiCellNameSorgu,
-		CinsCellName:            &defaultCinsCellName,
+		TapuFieldMapping:        &defaultTapuFieldMapping,
+		TapuMinConfidence:       &defaultTapuMinConfidence,
+		ParselFields:            &defaultParselFields,
+		TakbisMapping:           &defaultTakbisMapping,
+		TakbisMappingPresets:    &defaultTakbisMappingPresets,
+		ExcelBackup:             &defaultExcelBackup,
+		ExcelSaveToNewFile:      &defaultExcelSaveToNewFile,
+		ExcelCheckpointRows:     &defaultExcelCheckpointRows,
+		ExcelResume:             &defaultExcelResume,
+		ExcelColumnTypes:        &defaultExcelColumnTypes,
+		TakbisReportSheet:       &defaultTakbisReportSheet,
 		TabId:                   &defaultTabId,
 		WordReplaceRules:        &defaultWordReplaceRules,
+		PdfTextBackend:          &defaultPdfTextBackend,
 	}
 }
 
 var config Config = GetDefaultConfig()
 
+// configWarnings are problems found while reading the config, logged once the app has started
+var configWarnings []string
+
 func config_init() error {
 	err := CreateConfigIfNotExist()
 	if err != nil {
@@ -340,15 +404,22 @@ func ReadConfig(path string) error {
 	}
 
 	defer file.Close()
-	decoder := json.NewDecoder(file)
+	data, err := io.ReadAll(file)
+	if err != nil {
+		return err
+	}
 
 	config = Config{}
 
-	err = decoder.Decode(&config)
+	err = json.Unmarshal(data, &config)
 	if err != nil {
 		return err
 	}
 
+	migrateTapuFieldMapping(data)
+	migrateParselFields(data)
+	migrateTakbisMapping(data)
+
 	return nil
 }
 
@@ -1,7 +1,6 @@
 package main
 
 import (
-	"os/exec"
 	"strings"
 
 	"github.com/wailsapp/wails/v2/pkg/runtime"
@@ -43,6 +42,39 @@ func (a *App) SaveConfigDialog() {
 	a.SendNotification("", "settings.config_saved", path, "success")
 }
 
+func (a *App) ExportParselCacheDialog() {
+	path, err := runtime.SaveFileDialog(a.ctx, runtime.SaveDialogOptions{
+		Title:                "Parsel önbelleğini dışa aktar",
+		DefaultFilename:      "parsel_cache.json",
+		CanCreateDirectories: true,
+		Filters: []runtime.FileFilter{
+			{
+				DisplayName: "JSON",
+				Pattern:     "*.json",
+			},
+		},
+	})
+
+	if err != nil {
+		runtime.LogWarning(a.ctx, err.Error())
+		return
+	}
+
+	if path == "" {
+		runtime.LogInfo(a.ctx, "No path given, not exporting parcel cache")
+		return
+	}
+
+	err = a.ExportParselCache(path)
+
+	if err != nil {
+		a.SendNotification("Parsel önbelleği dışa aktarılamadı", "", "", "error")
+		return
+	}
+
+	a.SendNotification("Parsel önbelleği dışa aktarıldı", "", strings.ReplaceAll(path, "\\", "\\\\"), "success")
+}
+
 func (a *App) GetLoadConfigPath() string {
 	path, err := runtime.OpenFileDialog(a.ctx, runtime.OpenDialogOptions{
 		Title:                "Load configuration",
@@ -87,15 +119,17 @@ func (a *App) GetExcelFilesDialog() []string {
 func (a *App) OpenFileInExplorer(path string) {
 	runtime.LogInfo(a.ctx, "Opening file in explorer: "+path)
 
-	cmd := exec.Command(`explorer`, `/select,`, path)
-	cmd.Run()
+	err := revealInFileManager(path)
+
+	if err != nil {
+		runtime.LogWarning(a.ctx, err.Error())
+	}
 }
 
 func (a *App) OpenFile(path string) {
 	runtime.LogInfo(a.ctx, "Opening file: "+path)
 
-	cmd := exec.Command("rundll32.exe", "url.dll,FileProtocolHandler", path)
-	err := cmd.Run()
+	err := openWithDefaultApp(path)
 
 	if err != nil {
 		runtime.LogWarning(a.ctx, err.Error())
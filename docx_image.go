@@ -0,0 +1,176 @@
+package main
+
+import (
+	"archive/zip"
+	"bytes"
+	"fmt"
+	"image/png"
+	"io"
+	"os"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+const (
+	docxDocumentPath     = "word/document.xml"
+	docxRelsPath         = "word/_rels/document.xml.rels"
+	docxContentTypesPath = "[Content_Types].xml"
+
+	// EMUs per centimetre, the unit of drawing sizes in Word documents
+	emuPerCm = 360000
+)
+
+// docxImagePlaceholder is the text replaced by an image, like {image:Kroki}
+func docxImagePlaceholder(name string) string {
+	return "{image:" + name + "}"
+}
+
+// docxHasImagePlaceholder reports whether the document body contains the image placeholder
+func docxHasImagePlaceholder(path string, name string) (bool, error) {
+	files, err := readDocxParts(path)
+	if err != nil {
+		return false, err
+	}
+
+	return bytes.Contains(files[docxDocumentPath], []byte(docxImagePlaceholder(name))), nil
+}
+
+// readDocxParts reads every part of a docx file into memory
+func readDocxParts(path string) (map[string][]byte, error) {
+	reader, err := zip.OpenReader(path)
+	if err != nil {
+		return nil, err
+	}
+	defer reader.Close()
+
+	parts := make(map[string][]byte, len(reader.File))
+	for _, file := range reader.File {
+		rc, err := file.Open()
+		if err != nil {
+			return nil, err
+		}
+		data, err := io.ReadAll(rc)
+		rc.Close()
+		if err != nil {
+			return nil, err
+		}
+		parts[file.Name] = data
+	}
+
+	return parts, nil
+}
+
+var docxRelationshipIDPattern = regexp.MustCompile(`Id="rId(\d+)"`)
+
+// insertDocxImage replaces every {image:name} placeholder of the document body with the PNG,
+// widthCm wide. The placeholder has to be typed in one go, Word splits text it edited later
+// into separate runs which aren't matched.
+func insertDocxImage(path string, name string, pngData []byte, widthCm float64) error {
+	placeholder := docxImagePlaceholder(name)
+
+	parts, err := readDocxParts(path)
+	if err != nil {
+		return err
+	}
+
+	document := string(parts[docxDocumentPath])
+	if !strings.Contains(document, placeholder) {
+		return nil
+	}
+
+	imageConfig, err := png.DecodeConfig(bytes.NewReader(pngData))
+	if err != nil {
+		return err
+	}
+
+	// Pick the next free relationship id and media name
+	rels := string(parts[docxRelsPath])
+	nextID := 1
+	for _, match := range docxRelationshipIDPattern.FindAllStringSubmatch(rels, -1) {
+		var id int
+		fmt.Sscan(match[1], &id)
+		nextID = max(nextID, id+1)
+	}
+	relID := fmt.Sprintf("rId%d", nextID)
+
+	mediaName := "image_" + sanitizeCellFolder(name) + ".png"
+	for i := 2; parts["word/media/"+mediaName] != nil; i++ {
+		mediaName = fmt.Sprintf("image_%s%d.png", sanitizeCellFolder(name), i)
+	}
+
+	parts["word/media/"+mediaName] = pngData
+
+	relationship := fmt.Sprintf(`<Relationship Id="%s" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/image" Target="media/%s"/>`, relID, mediaName)
+	parts[docxRelsPath] = []byte(strings.Replace(rels, "</Relationships>", relationship+"</Relationships>", 1))
+
+	contentTypes := string(parts[docxContentTypesPath])
+	if !strings.Contains(strings.ToLower(contentTypes), `extension="png"`) {
+		contentTypes = strings.Replace(contentTypes, "</Types>", `<Default Extension="png" ContentType="image/png"/></Types>`, 1)
+		parts[docxContentTypesPath] = []byte(contentTypes)
+	}
+
+	widthEMU := int(widthCm * emuPerCm)
+	heightEMU := widthEMU * imageConfig.Height / imageConfig.Width
+
+	// A drawing may sit between the texts of a run, so the run and its formatting stay intact
+	for i := 0; strings.Contains(document, placeholder); i++ {
+		drawing := docxInlineDrawing(relID, 1000+i, name, widthEMU, heightEMU)
+		document = strings.Replace(document, placeholder, `</w:t>`+drawing+`<w:t xml:space="preserve">`, 1)
+	}
+
+	document = ensureDocxNamespace(document, "wp", "http://schemas.openxmlformats.org/drawingml/2006/wordprocessingDrawing")
+	document = ensureDocxNamespace(document, "r", "http://schemas.openxmlformats.org/officeDocument/2006/relationships")
+	parts[docxDocumentPath] = []byte(document)
+
+	return writeDocxParts(path, parts)
+}
+
+func docxInlineDrawing(relID string, id int, name string, width, height int) string {
+	return fmt.Sprintf(`<w:drawing><wp:inline distT="0" distB="0" distL="0" distR="0"><wp:extent cx="%[3]d" cy="%[4]d"/><wp:docPr id="%[2]d" name="%[5]s"/>`+
+		`<a:graphic xmlns:a="http://schemas.openxmlformats.org/drawingml/2006/main"><a:graphicData uri="http://schemas.openxmlformats.org/drawingml/2006/picture">`+
+		`<pic:pic xmlns:pic="http://schemas.openxmlformats.org/drawingml/2006/picture"><pic:nvPicPr><pic:cNvPr id="%[2]d" name="%[5]s.png"/><pic:cNvPicPr/></pic:nvPicPr>`+
+		`<pic:blipFill><a:blip r:embed="%[1]s"/><a:stretch><a:fillRect/></a:stretch></pic:blipFill>`+
+		`<pic:spPr><a:xfrm><a:off x="0" y="0"/><a:ext cx="%[3]d" cy="%[4]d"/></a:xfrm><a:prstGeom prst="rect"><a:avLst/></a:prstGeom></pic:spPr>`+
+		`</pic:pic></a:graphicData></a:graphic></wp:inline></w:drawing>`, relID, id, width, height, xmlEscape(name))
+}
+
+// ensureDocxNamespace declares prefix on the document element if the template doesn't already
+func ensureDocxNamespace(document, prefix, uri string) string {
+	if strings.Contains(document, "xmlns:"+prefix+"=") {
+		return document
+	}
+
+	return strings.Replace(document, "<w:document ", `<w:document xmlns:`+prefix+`="`+uri+`" `, 1)
+}
+
+// writeDocxParts writes the parts back to path with [Content_Types].xml first as Word expects
+func writeDocxParts(path string, parts map[string][]byte) error {
+	var buffer bytes.Buffer
+	writer := zip.NewWriter(&buffer)
+
+	names := make([]string, 0, len(parts))
+	for name := range parts {
+		if name != docxContentTypesPath {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	names = append([]string{docxContentTypesPath}, names...)
+
+	for _, name := range names {
+		w, err := writer.Create(name)
+		if err != nil {
+			return err
+		}
+		if _, err := w.Write(parts[name]); err != nil {
+			return err
+		}
+	}
+
+	if err := writer.Close(); err != nil {
+		return err
+	}
+
+	return os.WriteFile(path, buffer.Bytes(), 0644)
+}
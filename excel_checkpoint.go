@@ -0,0 +1,167 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"github.com/xuri/excelize/v2"
+)
+
+// Enrichment runs that can be checkpointed, a progress file only resumes a run of the same task
+const (
+	ExcelTaskParsel      = "parsel"
+	ExcelTaskParselPoint = "parsel-koordinat"
+	ExcelTaskTapu        = "tapu"
+	ExcelTaskTakbis      = "takbis"
+)
+
+// excelProgress is the progress file of an enrichment run, kept next to the workbook until the
+// run finishes so an interrupted run can pick up where it stopped
+type excelProgress struct {
+	Task      string    `json:"task"`
+	Source    string    `json:"source"`
+	Target    string    `json:"target"` // where checkpoints are saved, empty before the first one
+	Done      []int     `json:"done"`   // 0-based data rows
+	UpdatedAt time.Time `json:"updatedAt"`
+}
+
+// excelCheckpoint saves the workbook and the progress file every ExcelCheckpointRows finished rows
+type excelCheckpoint struct {
+	progress excelProgress
+	done     map[int]bool
+	pending  int
+	resumed  bool // continues an unfinished run
+}
+
+func excelProgressPath(path string) string {
+	dir, name := filepath.Split(path)
+	return filepath.Join(dir, ".~"+name+".progress.json")
+}
+
+// newExcelCheckpoint starts the checkpoints of task on the workbook at path. With ExcelResume
+// set, an unfinished run of the same task is continued and the returned path is the workbook
+// it saved to, which has to be read instead of path.
+func newExcelCheckpoint(task, path string) (*excelCheckpoint, string) {
+	checkpoint := &excelCheckpoint{
+		progress: excelProgress{Task: task, Source: path},
+		done:     make(map[int]bool),
+	}
+
+	if !*config.ExcelResume {
+		return checkpoint, path
+	}
+
+	data, err := os.ReadFile(excelProgressPath(path))
+	if err != nil {
+		return checkpoint, path
+	}
+
+	var progress excelProgress
+	if err := json.Unmarshal(data, &progress); err != nil || progress.Task != task || progress.Target == "" {
+		return checkpoint, path
+	}
+	if _, err := os.Stat(progress.Target); err != nil {
+		return checkpoint, path
+	}
+
+	checkpoint.progress = progress
+	checkpoint.resumed = true
+	for _, row := range progress.Done {
+		checkpoint.done[row] = true
+	}
+
+	return checkpoint, progress.Target
+}
+
+// isDone reports whether an earlier run already finished the 0-based data row
+func (c *excelCheckpoint) isDone(row int) bool {
+	return c.done[row]
+}
+
+// batches splits rows into groups of ExcelCheckpointRows, one group if checkpoints are disabled
+func (c *excelCheckpoint) batches(rows []int) [][]int {
+	size := *config.ExcelCheckpointRows
+	if size <= 0 {
+		size = max(len(rows), 1)
+	}
+
+	var batches [][]int
+	for start := 0; start < len(rows); start += size {
+		batches = append(batches, rows[start:min(start+size, len(rows))])
+	}
+
+	return batches
+}
+
+// markDone records the row as finished and saves once ExcelCheckpointRows rows piled up
+func (c *excelCheckpoint) markDone(excel *excelize.File, row int) error {
+	if !c.done[row] {
+		c.done[row] = true
+		c.progress.Done = append(c.progress.Done, row)
+	}
+
+	c.pending++
+	if *config.ExcelCheckpointRows <= 0 || c.pending < *config.ExcelCheckpointRows {
+		return nil
+	}
+
+	_, err := c.save(excel)
+	return err
+}
+
+// save writes the workbook and the progress file. The first save of a run goes through
+// saveExcel, which backs up the original or picks the new file, later ones replace that file.
+func (c *excelCheckpoint) save(excel *excelize.File) (string, error) {
+	if c.progress.Target == "" {
+		target, err := saveExcel(excel, c.progress.Source)
+		if err != nil {
+			return "", err
+		}
+		c.progress.Target = target
+	} else if err := writeExcelAtomic(excel, c.progress.Target); err != nil {
+		return "", err
+	}
+
+	c.pending = 0
+
+	sort.Ints(c.progress.Done)
+	c.progress.UpdatedAt = time.Now()
+
+	data, err := json.MarshalIndent(c.progress, "", "  ")
+	if err != nil {
+		return "", err
+	}
+
+	if err := os.WriteFile(excelProgressPath(c.progress.Source), data, 0644); err != nil {
+		return "", fmt.Errorf("failed to write progress file: %w", err)
+	}
+
+	return c.progress.Target, nil
+}
+
+// finish saves the workbook one last time and removes the progress file
+func (c *excelCheckpoint) finish(excel *excelize.File) (string, error) {
+	target, err := c.save(excel)
+	if err != nil {
+		return "", err
+	}
+
+	if err := os.Remove(excelProgressPath(c.progress.Source)); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return target, err
+	}
+
+	return target, nil
+}
+
+// resumeMessage tells how many rows were skipped, empty if none were
+func resumeMessage(skipped int) string {
+	if skipped == 0 {
+		return ""
+	}
+	return fmt.Sprintf(" (%d satır daha önce tamamlanmıştı)", skipped)
+}
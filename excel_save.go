@@ -0,0 +1,141 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/xuri/excelize/v2"
+)
+
+const excelBackupFolder = "Yedekler"
+
+// ErrExcelLocked is returned when another program, usually Excel itself, holds the workbook open
+var ErrExcelLocked = errors.New("excel file is open in another program")
+
+// excelLockFiles are the owner files Excel and LibreOffice keep next to a workbook while it's open
+func excelLockFiles(path string) []string {
+	dir, name := filepath.Split(path)
+	return []string{
+		filepath.Join(dir, "~$"+name),
+		filepath.Join(dir, ".~lock."+name+"#"),
+	}
+}
+
+// checkExcelWritable fails early with ErrExcelLocked if the workbook is open elsewhere, so a long
+// run doesn't end in a save that can't happen
+func checkExcelWritable(path string) error {
+	for _, lockFile := range excelLockFiles(path) {
+		if _, err := os.Stat(lockFile); err == nil {
+			return fmt.Errorf("%w: close %s and try again", ErrExcelLocked, filepath.Base(path))
+		}
+	}
+
+	file, err := os.OpenFile(path, os.O_RDWR, 0)
+	if err != nil {
+		if isFileLocked(err) {
+			return fmt.Errorf("%w: close %s and try again", ErrExcelLocked, filepath.Base(path))
+		}
+		return err
+	}
+
+	return file.Close()
+}
+
+// checkExcelSavable runs checkExcelWritable unless the result goes to a new file
+func checkExcelSavable(path string) error {
+	if *config.ExcelSaveToNewFile {
+		return nil
+	}
+	return checkExcelWritable(path)
+}
+
+// timestampedPath returns path with the time inserted before its extension, like
+// Parseller_2024-05-01_153000.xlsx
+func timestampedPath(dir, path string, t time.Time) string {
+	ext := filepath.Ext(path)
+	base := strings.TrimSuffix(filepath.Base(path), ext)
+
+	candidate := filepath.Join(dir, base+"_"+t.Format("2006-01-02_150405")+ext)
+	for i := 2; ; i++ {
+		if _, err := os.Stat(candidate); os.IsNotExist(err) {
+			return candidate
+		}
+		candidate = filepath.Join(dir, fmt.Sprintf("%s_%s_%d%s", base, t.Format("2006-01-02_150405"), i, ext))
+	}
+}
+
+// saveExcel writes the workbook without ever leaving a half written file. With
+// ExcelSaveToNewFile the original is kept and a timestamped copy is written next to it,
+// otherwise the original is backed up to the Yedekler folder first if ExcelBackup is set.
+// Returns the path written to.
+func saveExcel(excel *excelize.File, path string) (string, error) {
+	target := path
+	now := time.Now()
+
+	if *config.ExcelSaveToNewFile {
+		target = timestampedPath(filepath.Dir(path), path, now)
+	} else {
+		if err := checkExcelWritable(path); err != nil {
+			return "", err
+		}
+
+		if *config.ExcelBackup {
+			backupDir := filepath.Join(filepath.Dir(path), excelBackupFolder)
+			if err := os.MkdirAll(backupDir, 0755); err != nil {
+				return "", err
+			}
+			if err := copyFile(path, timestampedPath(backupDir, path, now)); err != nil {
+				return "", fmt.Errorf("failed to back up %s: %w", filepath.Base(path), err)
+			}
+		}
+	}
+
+	if err := writeExcelAtomic(excel, target); err != nil {
+		return "", err
+	}
+
+	return target, nil
+}
+
+// writeExcelAtomic writes the workbook to a temporary file and renames it over target
+func writeExcelAtomic(excel *excelize.File, target string) error {
+	// The temporary file is in the same folder so the rename doesn't cross devices
+	temp, err := os.CreateTemp(filepath.Dir(target), ".~"+filepath.Base(target)+"-*.tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(temp.Name())
+
+	if err := excel.Write(temp); err != nil {
+		temp.Close()
+		return err
+	}
+	if err := temp.Sync(); err != nil {
+		temp.Close()
+		return err
+	}
+	if err := temp.Close(); err != nil {
+		return err
+	}
+
+	if err := os.Rename(temp.Name(), target); err != nil {
+		if isFileLocked(err) {
+			return fmt.Errorf("%w: close %s and try again", ErrExcelLocked, filepath.Base(target))
+		}
+		return err
+	}
+
+	return nil
+}
+
+// excelSaveError turns ErrExcelLocked into a message for the user, other errors are returned as is
+func excelSaveError(err error) string {
+	if errors.Is(err, ErrExcelLocked) {
+		return "Excel dosyası başka bir programda açık, dosyayı kapatıp tekrar deneyin"
+	}
+	return err.Error()
+}
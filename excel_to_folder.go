@@ -254,6 +254,18 @@ func (a *App) CreateFolders(excelPath string, wordPath string, copyFolderPath st
 
 	folderNames := generateFolderNames(folderNamePattern, headers, rows)
 
+	var exportFormats []string
+	if *config.ParselExportOnCreate {
+		exportFormats, err = parseParselExportFormats(*config.ParselExportFormats)
+		if err != nil {
+			runtime.LogError(a.ctx, err.Error())
+			return err.Error()
+		}
+	}
+
+	// Parcels are only queried for geometry exports and {image:Kroki} placeholders
+	defer closeParselSorgu()
+
 	for i, folderName := range folderNames {
 		var targetFolderPath string
 
@@ -274,9 +286,24 @@ func (a *App) CreateFolders(excelPath string, wordPath string, copyFolderPath st
 			}
 		}
 
+		// The row's parcel is queried at most once
+		var collection FeatureCollection
+		var queryErr error
+		queried := false
+		parcel := func() (FeatureCollection, error) {
+			if !queried {
+				collection, queryErr = a.queryParsel(parselParamsFromRow(headers, rows[i]), *config.ParselSorguHeadless)
+				queried = true
+			}
+			return collection, queryErr
+		}
+
 		if wordPath != "" {
-			if err := createWordDocument(wordPath, wordFileNamePattern, headers, rows[i], targetFolderPath, wordReplaceRules); err != nil {
+			documentPath, err := createWordDocument(wordPath, wordFileNamePattern, headers, rows[i], targetFolderPath, wordReplaceRules)
+			if err != nil {
 				runtime.LogError(a.ctx, "Failed to create word document: "+err.Error())
+			} else if err := insertParselSketch(documentPath, parcel); err != nil {
+				runtime.LogError(a.ctx, "Failed to insert parcel sketch: "+err.Error())
 			}
 		}
 
@@ -286,6 +313,16 @@ func (a *App) CreateFolders(excelPath string, wordPath string, copyFolderPath st
 			}
 		}
 
+		if len(exportFormats) > 0 {
+			collection, err := parcel()
+			if err == nil {
+				_, err = exportParselGeometry(collection, targetFolderPath, exportFormats)
+			}
+			if err != nil {
+				runtime.LogError(a.ctx, "Failed to export parcel geometry: "+err.Error())
+			}
+		}
+
 		runtime.WindowExecJS(appContext, `window.setExcelMessage("`+fmt.Sprintf("%d/%d", i+1, len(folderNames))+`");`)
 	}
 
@@ -329,12 +366,13 @@ func (a *App) CreateFoldersV2(excelPath string, copyFolderPath string, targetPat
 	return ""
 }
 
-func createWordDocument(filePath string, wordFileNamePattern string, headers []string, row []string, targetPath string, wordReplaceRules string) error {
+// createWordDocument fills the template's placeholders from row and returns the written file's path
+func createWordDocument(filePath string, wordFileNamePattern string, headers []string, row []string, targetPath string, wordReplaceRules string) (string, error) {
 	r, err := docx.ReadDocxFile(filePath)
 
 	if err != nil {
 		runtime.LogError(appContext, "Failed to read docx file: "+err.Error())
-		return err
+		return "", err
 	}
 
 	docx1 := r.Editable()
@@ -354,7 +392,7 @@ func createWordDocument(filePath string, wordFileNamePattern string, headers []s
 		splittedRule := strings.Split(rule, "->")
 
 		if len(splittedRule) != 2 {
-			return errors.New("wordReplaceRules is not valid")
+			return "", errors.New("wordReplaceRules is not valid")
 		}
 
 		if splittedRule[1] == `""` {
@@ -366,13 +404,13 @@ func createWordDocument(filePath string, wordFileNamePattern string, headers []s
 		err3 := docx1.ReplaceHeader(splittedRule[0], splittedRule[1])
 
 		if err1 != nil {
-			return err1
+			return "", err1
 		}
 		if err2 != nil {
-			return err2
+			return "", err2
 		}
 		if err3 != nil {
-			return err3
+			return "", err3
 		}
 	}
 
@@ -381,16 +419,18 @@ func createWordDocument(filePath string, wordFileNamePattern string, headers []s
 
 	fileName := generatePatternName(wordFileNamePattern, headers, row)
 
-	docx1.WriteToFile(filepath.Join(targetPath, fileName) + ".docx")
+	documentPath := filepath.Join(targetPath, fileName) + ".docx"
+
+	docx1.WriteToFile(documentPath)
 
 	err = r.Close()
 
 	if err != nil {
 		runtime.LogError(appContext, "Failed to close docx file: "+err.Error())
-		return err
+		return "", err
 	}
 
-	return nil
+	return documentPath, nil
 }
 
 func createUdfDocument(filePath string, fileNamePattern string, headers []string, row []string, targetPath string) error {
@@ -569,7 +609,8 @@ func copyFolderContentsV2(src, dest string, headers []string, row []string) erro
 		}
 
 		if filepath.Ext(relativePath) == ".docx" {
-			return createWordDocument(path, filepath.Base(path), headers, row, dest, "")
+			_, err := createWordDocument(path, filepath.Base(path), headers, row, dest, "")
+			return err
 		} else if filepath.Ext(relativePath) == ".udf" {
 			return createUdfDocument(path, filepath.Base(path), headers, row, dest)
 		}
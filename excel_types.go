@@ -0,0 +1,307 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/wailsapp/wails/v2/pkg/runtime"
+	"github.com/xuri/excelize/v2"
+)
+
+// Column types of ExcelColumnTypes
+const (
+	ExcelColumnText    = "metin"
+	ExcelColumnInteger = "tamsayi"
+	ExcelColumnDecimal = "ondalik"
+	ExcelColumnDate    = "tarih"
+)
+
+// excelDateLayouts are the date formats a date column accepts, the first one is also how dates
+// are shown in cells without a number format
+var excelDateLayouts = []string{
+	"02.01.2006",
+	"2.1.2006",
+	"02/01/2006",
+	"2/1/2006",
+	"2006-01-02",
+	"02.01.2006 15:04",
+	"02.01.2006 15:04:05",
+	"2006-01-02 15:04:05",
+	"2006-01-02T15:04:05Z07:00",
+}
+
+// excelColumnType is the type a column is written as, precision is only used by decimal
+// columns and is -1 to keep every digit
+type excelColumnType struct {
+	kind      string
+	precision int
+}
+
+// parseExcelColumnTypes reads rules like "Ada->tamsayi,Alan (m2)->ondalik:2,Tarih->tarih",
+// English type names are accepted too
+func parseExcelColumnTypes(rule string) (map[string]excelColumnType, error) {
+	types := make(map[string]excelColumnType)
+
+	for _, pair := range strings.Split(rule, ",") {
+		if strings.TrimSpace(pair) == "" {
+			continue
+		}
+
+		kv := strings.Split(pair, "->")
+		if len(kv) != 2 || strings.TrimSpace(kv[0]) == "" {
+			return nil, fmt.Errorf("invalid column type rule: %s", pair)
+		}
+
+		kind, precision, hasPrecision := strings.Cut(strings.TrimSpace(kv[1]), ":")
+		columnType := excelColumnType{precision: -1}
+
+		switch strings.ToLower(strings.TrimSpace(kind)) {
+		case "metin", "text":
+			columnType.kind = ExcelColumnText
+		case "tamsayi", "tamsayı", "integer":
+			columnType.kind = ExcelColumnInteger
+		case "ondalik", "ondalık", "decimal":
+			columnType.kind = ExcelColumnDecimal
+		case "tarih", "date":
+			columnType.kind = ExcelColumnDate
+		default:
+			return nil, fmt.Errorf("unknown column type %s, expected one of metin, tamsayi, ondalik, tarih", strings.TrimSpace(kind))
+		}
+
+		if hasPrecision {
+			if columnType.kind != ExcelColumnDecimal {
+				return nil, fmt.Errorf("only decimal columns take a precision: %s", pair)
+			}
+
+			digits, err := strconv.Atoi(strings.TrimSpace(precision))
+			if err != nil || digits < 0 || digits > 15 {
+				return nil, fmt.Errorf("invalid precision in column type rule: %s", pair)
+			}
+			columnType.precision = digits
+		}
+
+		types[strings.TrimSpace(kv[0])] = columnType
+	}
+
+	return types, nil
+}
+
+// excelCellWriter writes values to a sheet by the column types of ExcelColumnTypes. Columns
+// without a rule keep the type of the value, so text is never turned into a number. The style of
+// the cell is kept, a number format is only added to cells that have no style at all.
+type excelCellWriter struct {
+	excel  *excelize.File
+	sheet  string
+	types  map[int]excelColumnType // by 0-based column
+	styles map[string]int          // number format -> style of cells without a style
+}
+
+// newExcelCellWriter resolves the column type rules against headers, so it has to be created
+// after any missing columns are added
+func newExcelCellWriter(excel *excelize.File, sheet string, headers []string) (*excelCellWriter, error) {
+	rules, err := parseExcelColumnTypes(*config.ExcelColumnTypes)
+	if err != nil {
+		return nil, err
+	}
+
+	w := &excelCellWriter{
+		excel:  excel,
+		sheet:  sheet,
+		types:  make(map[int]excelColumnType),
+		styles: make(map[string]int),
+	}
+
+	for header, columnType := range rules {
+		if index := headerIndex(headers, header); index != -1 {
+			w.types[index] = columnType
+		}
+	}
+
+	return w, nil
+}
+
+// set writes value, a string, int, float64 or time.Time, to the 0-based column of the 1-based
+// excel row. A value that doesn't fit the column's type is written as text and logged.
+func (w *excelCellWriter) set(col, row int, value interface{}) error {
+	cell, err := excelize.CoordinatesToCellName(col+1, row)
+	if err != nil {
+		return err
+	}
+
+	columnType, ok := w.types[col]
+	if !ok {
+		switch v := value.(type) {
+		case int:
+			return w.excel.SetCellInt(w.sheet, cell, v)
+		case float64:
+			return w.excel.SetCellFloat(w.sheet, cell, v, -1, 64)
+		case time.Time:
+			return w.setDate(cell, v)
+		default:
+			return w.excel.SetCellStr(w.sheet, cell, excelText(value))
+		}
+	}
+
+	switch columnType.kind {
+	case ExcelColumnInteger:
+		if number, err := excelInt(value); err == nil {
+			return w.excel.SetCellInt(w.sheet, cell, number)
+		}
+	case ExcelColumnDecimal:
+		if number, err := excelFloat(value); err == nil {
+			return w.setDecimal(cell, number, columnType.precision)
+		}
+	case ExcelColumnDate:
+		if date, err := excelDate(value); err == nil {
+			return w.setDate(cell, date)
+		}
+	case ExcelColumnText:
+		return w.excel.SetCellStr(w.sheet, cell, excelText(value))
+	}
+
+	runtime.LogWarningf(appContext, "%s doesn't fit the %s type of cell %s, writing it as text", excelText(value), columnType.kind, cell)
+
+	return w.excel.SetCellStr(w.sheet, cell, excelText(value))
+}
+
+func (w *excelCellWriter) setDecimal(cell string, number float64, precision int) error {
+	if err := w.excel.SetCellFloat(w.sheet, cell, number, precision, 64); err != nil {
+		return err
+	}
+
+	if precision <= 0 {
+		return w.ensureNumberFormat(cell, "0")
+	}
+	return w.ensureNumberFormat(cell, "0."+strings.Repeat("0", precision))
+}
+
+func (w *excelCellWriter) setDate(cell string, date time.Time) error {
+	// Set the format first, excelize gives unstyled date cells a date and time format otherwise
+	if err := w.ensureNumberFormat(cell, "dd.mm.yyyy"); err != nil {
+		return err
+	}
+	return w.excel.SetCellValue(w.sheet, cell, date)
+}
+
+// ensureNumberFormat gives the cell the number format if it has no style of its own, its row's
+// or its column's
+func (w *excelCellWriter) ensureNumberFormat(cell, format string) error {
+	style, err := w.excel.GetCellStyle(w.sheet, cell)
+	if err != nil || style != 0 {
+		return err
+	}
+
+	id, ok := w.styles[format]
+	if !ok {
+		id, err = w.excel.NewStyle(&excelize.Style{CustomNumFmt: &format})
+		if err != nil {
+			return err
+		}
+		w.styles[format] = id
+	}
+
+	return w.excel.SetCellStyle(w.sheet, cell, cell, id)
+}
+
+func excelText(value interface{}) string {
+	switch v := value.(type) {
+	case string:
+		return v
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	case time.Time:
+		return v.Format(excelDateLayouts[0])
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+func excelInt(value interface{}) (int, error) {
+	switch v := value.(type) {
+	case int:
+		return v, nil
+	case float64:
+		if v != math.Trunc(v) {
+			return 0, fmt.Errorf("%v is not an integer", v)
+		}
+		return int(v), nil
+	case string:
+		return strconv.Atoi(strings.TrimSpace(v))
+	default:
+		return 0, fmt.Errorf("%v is not an integer", v)
+	}
+}
+
+func excelFloat(value interface{}) (float64, error) {
+	switch v := value.(type) {
+	case int:
+		return float64(v), nil
+	case float64:
+		return v, nil
+	case string:
+		return excelNumber(v)
+	default:
+		return 0, fmt.Errorf("%v is not a number", v)
+	}
+}
+
+func excelDate(value interface{}) (time.Time, error) {
+	switch v := value.(type) {
+	case time.Time:
+		return v, nil
+	case string:
+		for _, layout := range excelDateLayouts {
+			if date, err := time.Parse(layout, strings.TrimSpace(v)); err == nil {
+				return date, nil
+			}
+		}
+	}
+
+	return time.Time{}, fmt.Errorf("%v is not a date", value)
+}
+
+// excelNumber parses the number in a cell's text. Plain numbers like "1234.567" are read as they
+// are, text with a comma or several dots as a formatted number whose last separator is the
+// decimal one, so "1.234,56" and "1,234.56" are both 1234.56. Unlike parseTurkishNumber a lone
+// dot is never a thousands separator, cells hold the numbers programs wrote.
+func excelNumber(value string) (float64, error) {
+	text := strings.TrimSpace(value)
+	text = strings.TrimSuffix(text, "m²")
+	text = strings.TrimSuffix(text, "m2")
+	text = strings.Join(strings.Fields(text), "")
+
+	comma, dot := strings.LastIndexByte(text, ','), strings.LastIndexByte(text, '.')
+
+	switch {
+	case comma == -1 && strings.Count(text, ".") <= 1:
+	case comma > dot && strings.Count(text, ",") == 1:
+		text = strings.Replace(strings.ReplaceAll(text, ".", ""), ",", ".", 1)
+	case dot > comma && strings.Count(text, ".") == 1:
+		text = strings.ReplaceAll(text, ",", "")
+	case dot == -1:
+		text = strings.ReplaceAll(text, ",", "")
+	case comma == -1:
+		text = strings.ReplaceAll(text, ".", "")
+	default:
+		return 0, fmt.Errorf("%s is not a number", value)
+	}
+
+	number, err := strconv.ParseFloat(text, 64)
+	if err != nil || math.IsNaN(number) || math.IsInf(number, 0) {
+		return 0, fmt.Errorf("%s is not a number", value)
+	}
+	return number, nil
+}
+
+// excelNumberText returns the number a text is if writing it as one loses nothing, so "0123"
+// and "12,5" stay text
+func excelNumberText(value string) (int, bool) {
+	number, err := strconv.Atoi(value)
+	if err != nil || strconv.Itoa(number) != value {
+		return 0, false
+	}
+	return number, true
+}
@@ -0,0 +1,35 @@
+package main
+
+import "testing"
+
+func TestExcelNumber(t *testing.T) {
+	tests := []struct {
+		value string
+		want  float64
+	}{
+		{"0.125", 0.125},
+		{"1234.567", 1234.567},
+		{"1,234.57", 1234.57},
+		{"1.234,56", 1234.56},
+		{"1.234,56 m²", 1234.56},
+		{"1250,5", 1250.5},
+		{"1.234.567", 1234567},
+		{"1,234,567", 1234567},
+		{"1.234.567,8", 1234567.8},
+		{" 850 ", 850},
+		{"-12,5", -12.5},
+	}
+
+	for _, test := range tests {
+		got, err := excelNumber(test.value)
+		if err != nil || got != test.want {
+			t.Errorf("excelNumber(%q) = %v, %v, want %v", test.value, got, err, test.want)
+		}
+	}
+
+	for _, value := range []string{"", "Tarla", "NaN", "Inf", "1,2.3,4", "1.2,3,4"} {
+		if got, err := excelNumber(value); err == nil {
+			t.Errorf("excelNumber(%q) = %v, want an error", value, got)
+		}
+	}
+}
@@ -3,6 +3,7 @@ import { Button } from "./ui/button";
 import {
   AddTapuToExcel,
   GetExcelFileDialog,
+  GetLastTapuReport,
   GetTargetFolderDialog,
   OpenFile,
   OpenFileInExplorer,
@@ -11,14 +12,27 @@ import { LoaderCircle, X } from "lucide-react";
 import { LogDebug } from "@/wailsjs/runtime/runtime";
 import { Input } from "./ui/input";
 import { useConfig } from "@/contexts/config-provider";
+import { main } from "@/wailsjs/go/models";
+
+// fileName returns the last element of a Windows or Unix path
+const fileName = (path: string) => path.split(/[\\/]/).pop() ?? path;
+
+const percent = (confidence: number) => `%${Math.round(confidence * 100)}`;
+
+// needsReview tells whether a row's tapu wasn't written, is one of several PDFs or conflicts
+// with them
+const needsReview = (result: main.TapuRowResult) =>
+  result.best === -1 ||
+  (result.matches?.length ?? 0) > 1 ||
+  (result.conflicts?.length ?? 0) > 0;
 
 export function CiltSayfa() {
   const { config, setConfigField } = useConfig();
 
-  const [ciltCellName, setCiltCellName] = useState<string>("");
-  const [sayfaCellName, setSayfaCellName] = useState<string>("");
-  const [mevkiCellName, setMevkiCellName] = useState<string>("");
-  const [alanCellNameTapu, setAlanCellNameTapu] = useState<string>("");
+  const [tapuFieldMapping, setTapuFieldMapping] = useState<string>("");
+  const [adaCellName, setAdaCellName] = useState<string>("");
+  const [parselCellName, setParselCellName] = useState<string>("");
+  const [mahalleCellName, setMahalleCellName] = useState<string>("");
 
   const [excelPath, setExcelPath] = useState<string>("");
   const [folderPath, setFolderPath] = useState<string>("");
@@ -26,13 +40,14 @@ export function CiltSayfa() {
 
   const [message, setMessage] = useState<string>("");
   const [running, setRunning] = useState<boolean>(false);
+  const [report, setReport] = useState<main.TapuRowResult[]>([]);
 
   useEffect(() => {
-    setCiltCellName(config?.ciltCellName!);
-    setSayfaCellName(config?.sayfaCellName!);
+    setTapuFieldMapping(config?.tapuFieldMapping!);
+    setAdaCellName(config?.adaCellName!);
+    setParselCellName(config?.parselCellName!);
+    setMahalleCellName(config?.mahalleCellName!);
     setTapuNamePattern(config?.tapuNamePattern!);
-    setMevkiCellName(config?.mevkiCellName!);
-    setAlanCellNameTapu(config?.alanCellNameTapu!);
   }, [config]);
 
   window.setCiltMessage = (message: string) => {
@@ -59,10 +74,10 @@ export function CiltSayfa() {
       excelPath,
       folderPath,
       tapuNamePattern,
-      ciltCellName,
-      sayfaCellName,
-      mevkiCellName,
-      alanCellNameTapu
+      tapuFieldMapping,
+      adaCellName,
+      parselCellName,
+      mahalleCellName
     )
       .then((error) => {
         if (error !== "") {
@@ -71,6 +86,9 @@ export function CiltSayfa() {
       })
       .finally(() => {
         setRunning(false);
+        GetLastTapuReport().then((report) => {
+          setReport(report ?? []);
+        });
       });
   };
 
@@ -78,51 +96,55 @@ export function CiltSayfa() {
     <div className="flex flex-col justify-center items-center gap-12 w-full h-full">
       <div className="flex flex-row">
         <div className="flex flex-col items-center gap-2 w-full">
-          <label>Cilt Sütunu</label>
+          <label>Ada Sütunu</label>
           <Input
             className="w-[90%]"
-            value={ciltCellName}
+            value={adaCellName}
             onChange={(e) => {
-              setConfigField("ciltCellName", e.target.value);
-              setCiltCellName(e.target.value);
+              setConfigField("adaCellName", e.target.value);
+              setAdaCellName(e.target.value);
             }}
           />
         </div>
 
         <div className="flex flex-col items-center gap-2 w-full">
-          <label>Sayfa Sütunu</label>
+          <label>Parsel Sütunu</label>
           <Input
             className="w-[90%]"
-            value={sayfaCellName}
+            value={parselCellName}
             onChange={(e) => {
-              setConfigField("sayfaCellName", e.target.value);
-              setSayfaCellName(e.target.value);
+              setConfigField("parselCellName", e.target.value);
+              setParselCellName(e.target.value);
             }}
           />
         </div>
 
         <div className="flex flex-col items-center gap-2 w-full">
-          <label>Mevki Sütunu</label>
+          <label>Mahalle Sütunu</label>
           <Input
             className="w-[90%]"
-            value={mevkiCellName}
+            value={mahalleCellName}
             onChange={(e) => {
-              setConfigField("mevkiCellName", e.target.value);
-              setMevkiCellName(e.target.value);
+              setConfigField("mahalleCellName", e.target.value);
+              setMahalleCellName(e.target.value);
             }}
           />
         </div>
+      </div>
 
-        <div className="flex flex-col items-center gap-2 w-full">
-          <label>Alan Sütunu</label>
-          <Input
-            className="w-[90%]"
-            value={alanCellNameTapu}
-            onChange={(e) => {
-              setConfigField("alanCellNameTapu", e.target.value);
-              setAlanCellNameTapu(e.target.value);
-            }}
-          />
+      <div className="flex flex-col items-center gap-2 w-full">
+        <label>Tapu Alanı Eşleştirme Kuralı</label>
+        <Input
+          className="w-[90%]"
+          value={tapuFieldMapping}
+          onChange={(e) => {
+            setConfigField("tapuFieldMapping", e.target.value);
+            setTapuFieldMapping(e.target.value);
+          }}
+        />
+        <div className="text-muted-foreground text-xs">
+          İl, İlçe, Mahalle, Mevki, Pafta, Ada, Parsel, Nitelik, Alan, Cilt,
+          Sayfa, Edinme Sebebi, Tarih, Yevmiye, Malikler, Malik Adları, Hisseler
         </div>
       </div>
 
@@ -196,12 +218,7 @@ export function CiltSayfa() {
           disabled={
             !folderPath ||
             !tapuNamePattern ||
-            !(
-              sayfaCellName ||
-              ciltCellName ||
-              mevkiCellName ||
-              alanCellNameTapu
-            ) ||
+            !tapuFieldMapping ||
             !excelPath ||
             running
           }
@@ -216,6 +233,34 @@ export function CiltSayfa() {
         </Button>
       </div>
       <div className="h-8 text-lg">{message}</div>
+      {report.some(needsReview) && (
+        <div className="flex flex-col gap-1 max-h-48 overflow-y-auto text-left text-sm">
+          <div className="font-medium">
+            Tapusu yazılmayan, birden fazla PDF'i olan veya çakışan satırlar
+          </div>
+          {report.filter(needsReview).map((result) => (
+            <div key={result.row}>
+              Satır {result.row}:{" "}
+              {result.best === -1
+                ? result.message || "Tapu yazılmadı"
+                : `${fileName(result.matches[result.best].path)} kullanıldı (${percent(result.matches[result.best].confidence)})`}
+              {(result.matches?.length ?? 0) > 1 &&
+                ` - Adaylar: ${result.matches
+                  .map((match) =>
+                    match.error
+                      ? `${fileName(match.path)} (okunamadı)`
+                      : `${fileName(match.path)} (${percent(match.confidence)})`
+                  )
+                  .join(", ")}`}
+              {result.conflicts?.map(
+                (conflict) =>
+                  ` - ${conflict.field}: ${conflict.values.join(" / ")}`
+              )}
+              {result.best !== -1 && result.message && ` - ${result.message}`}
+            </div>
+          ))}
+        </div>
+      )}
     </div>
   );
 }
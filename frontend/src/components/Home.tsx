@@ -30,6 +30,9 @@ export function Home() {
   const [wordFileNamePattern, setWordFileNamePattern] = useState<string>("");
   const [fileNamePattern, setFileNamePattern] = useState<string>("");
   const [wordReplaceRules, setWordReplaceRules] = useState<string>("");
+  const [parselExportOnCreate, setParselExportOnCreate] =
+    useState<boolean>(false);
+  const [parselExportFormats, setParselExportFormats] = useState<string>("");
 
   const [running, setRunning] = useState<boolean>(false);
   const [message, setMessage] = useState<string>("");
@@ -40,6 +43,8 @@ export function Home() {
     setWordFileNamePattern(config?.wordFileNamePattern!);
     setFileNamePattern(config?.fileNamePattern!);
     setWordReplaceRules(config?.wordReplaceRules!);
+    setParselExportOnCreate(config?.parselExportOnCreate!);
+    setParselExportFormats(config?.parselExportFormats!);
   }, [config]);
 
   const handleExcelFileDialog = () => {
@@ -268,6 +273,25 @@ export function Home() {
               }}
             />
           </div>
+          <div className="flex justify-center items-center gap-2">
+            Parsel geometrisi
+            <Switch
+              checked={parselExportOnCreate}
+              onCheckedChange={() => {
+                setConfigField("parselExportOnCreate", !parselExportOnCreate);
+                setParselExportOnCreate(!parselExportOnCreate);
+              }}
+            />
+            <Input
+              className={`w-36 h-8 ${!parselExportOnCreate ? "hidden" : ""}`}
+              placeholder="geojson,kml,dxf,png,svg"
+              value={parselExportFormats}
+              onChange={(e) => {
+                setConfigField("parselExportFormats", e.target.value);
+                setParselExportFormats(e.target.value);
+              }}
+            />
+          </div>
         </div>
         <div className="flex flex-col items-center gap-2 w-full">
           <label>Word Dosyası Adı</label>
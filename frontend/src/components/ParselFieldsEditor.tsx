@@ -0,0 +1,72 @@
+import { Plus, X } from "lucide-react";
+import { Button } from "./ui/button";
+import { Input } from "./ui/input";
+import { main } from "@/wailsjs/go/models";
+
+interface ParselFieldsEditorProps {
+  rules: main.ParselFieldRule[];
+  fields: string[];
+  onChange: (rules: main.ParselFieldRule[]) => void;
+}
+
+export function ParselFieldsEditor(props: ParselFieldsEditorProps) {
+  const { rules, fields } = props;
+
+  const setRule = (index: number, rule: Partial<main.ParselFieldRule>) => {
+    props.onChange(
+      rules.map((r, i) =>
+        i === index ? main.ParselFieldRule.createFrom({ ...r, ...rule }) : r
+      )
+    );
+  };
+
+  return (
+    <div className="flex flex-col items-center gap-2 w-[90%]">
+      <datalist id="parsel-fields">
+        {fields.map((field) => (
+          <option key={field} value={field} />
+        ))}
+      </datalist>
+
+      {rules.map((rule, index) => (
+        <div className="flex items-center gap-2 w-full" key={index}>
+          <Input
+            placeholder="Parsel bilgisi"
+            list="parsel-fields"
+            value={rule.field}
+            onChange={(e) => setRule(index, { field: e.target.value })}
+          />
+          <span>→</span>
+          <Input
+            placeholder="Hedef sütun"
+            value={rule.column}
+            onChange={(e) => setRule(index, { column: e.target.value })}
+          />
+          <Button
+            variant={"destructive"}
+            className="rounded-sm w-4 h-4 shrink-0"
+            size={"icon"}
+            onClick={() => props.onChange(rules.filter((_, i) => i !== index))}
+          >
+            <X className="p-0.5" />
+          </Button>
+        </div>
+      ))}
+      <Button
+        variant={"outline"}
+        size={"sm"}
+        onClick={() =>
+          props.onChange([
+            ...rules,
+            main.ParselFieldRule.createFrom({ field: "", column: "" }),
+          ])
+        }
+      >
+        <Plus className="mr-1 w-4 h-4" /> Bilgi Ekle
+      </Button>
+      <div className="text-muted-foreground text-xs">
+        Listede olmayan, sitenin döndürdüğü diğer bilgiler de adıyla yazılabilir
+      </div>
+    </div>
+  );
+}
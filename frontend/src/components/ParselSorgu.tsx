@@ -1,36 +1,65 @@
 import { useEffect, useState } from "react";
 import { Button } from "./ui/button";
 import {
+  AddParselFieldsFromCoordinates,
   AddParselSorguFields,
+  ClearParselCache,
+  ExportParselCacheDialog,
   GetExcelFileDialog,
+  GetParselFields,
+  GetParselNameReport,
+  GetSupportedCRS,
   OpenFile,
+  SetParselFields,
 } from "@/wailsjs/go/main/App";
+import { main } from "@/wailsjs/go/models";
 import { LoaderCircle, X } from "lucide-react";
 import { LogDebug } from "@/wailsjs/runtime/runtime";
 import { Input } from "./ui/input";
 import { useConfig } from "@/contexts/config-provider";
 import { Switch } from "./ui/switch";
+import { Combobox } from "./ui/combobox";
+import { ParselFieldsEditor } from "./ParselFieldsEditor";
 
 export function ParselSorguComp() {
-  const { config, setConfigField } = useConfig();
+  const { config, setConfig, setConfigField } = useConfig();
 
   const [ilCellName, setIlCellName] = useState<string>("");
   const [ilceCellName, setIlceCellName] = useState<string>("");
   const [mahalleCellName, setMahalleCellName] = useState<string>("");
   const [adaCellName, setAdaCellName] = useState<string>("");
   const [parselCellName, setParselCellName] = useState<string>("");
-  const [alanCellName, setAlanCellName] = useState<string>("");
-  const [paftaCellName, setPaftaCellName] = useState<string>("");
-  const [cinsCellName, setCinsCellName] = useState<string>("");
-  const [mevkiCellNameSorgu, setMevkiCellNameSorgu] = useState<string>("");
+  const [parselFieldRules, setParselFieldRules] = useState<
+    main.ParselFieldRule[]
+  >([]);
+  const [parselFields, setParselFields] = useState<string[]>([]);
 
   const [parselSorguHeadless, setParselSorguHeadless] =
     useState<boolean>(false);
+  const [parselSorguBackend, setParselSorguBackend] = useState<string>("http");
+  const [parselCacheTTL, setParselCacheTTL] = useState<number>(30);
+  const [parselCRS, setParselCRS] = useState<string>("");
+  const [optionalCellNames, setOptionalCellNames] = useState<
+    Record<string, string>
+  >({});
+  const [parselAreaTolerance, setParselAreaTolerance] = useState<number>(1);
+  const [parselMaxRetries, setParselMaxRetries] = useState<number>(3);
+  const [parselBrowserTabs, setParselBrowserTabs] = useState<number>(1);
+  const [parselConcurrency, setParselConcurrency] = useState<number>(1);
+  const [parselRequestsPerMinute, setParselRequestsPerMinute] =
+    useState<number>(30);
+  const [crsList, setCrsList] = useState<main.CRS[]>([]);
+  const [parselXCellName, setParselXCellName] = useState<string>("");
+  const [parselYCellName, setParselYCellName] = useState<string>("");
+  const [parselPointCRS, setParselPointCRS] = useState<string>("");
+  const [parselNeighborProbe, setParselNeighborProbe] =
+    useState<boolean>(true);
 
   const [excelPath, setExcelPath] = useState<string>("");
 
   const [message, setMessage] = useState<string>("");
   const [running, setRunning] = useState<boolean>(false);
+  const [nameReport, setNameReport] = useState<main.ParselNameIssue[]>([]);
 
   useEffect(() => {
     setIlCellName(config?.ilCellName!);
@@ -38,13 +67,40 @@ export function ParselSorguComp() {
     setMahalleCellName(config?.mahalleCellName!);
     setAdaCellName(config?.adaCellName!);
     setParselCellName(config?.parselCellName!);
-    setAlanCellName(config?.alanCellName!);
-    setPaftaCellName(config?.paftaCellName!);
-    setCinsCellName(config?.cinsCellName!);
-    setMevkiCellNameSorgu(config?.mevkiCellNameSorgu!);
+    setParselFieldRules(config?.parselFields ?? []);
     setParselSorguHeadless(config?.parselSorguHeadless!);
+    setParselSorguBackend(config?.parselSorguBackend!);
+    setParselCacheTTL(config?.parselCacheTTL!);
+    setParselCRS(config?.parselCRS!);
+    setOptionalCellNames({
+      parselYuzolcumCellName: config?.parselYuzolcumCellName!,
+      parselStatusCellName: config?.parselStatusCellName!,
+    });
+    setParselAreaTolerance(config?.parselAreaTolerance!);
+    setParselMaxRetries(config?.parselMaxRetries!);
+    setParselBrowserTabs(config?.parselBrowserTabs!);
+    setParselConcurrency(config?.parselConcurrency!);
+    setParselRequestsPerMinute(config?.parselRequestsPerMinute!);
+    setParselXCellName(config?.parselXCellName!);
+    setParselYCellName(config?.parselYCellName!);
+    setParselPointCRS(config?.parselPointCRS!);
+    setParselNeighborProbe(config?.parselNeighborProbe!);
   }, [config]);
 
+  const optionalFields: { key: keyof main.Config; label: string }[] = [
+    { key: "parselYuzolcumCellName", label: "Tapu Yüzölçüm Sütunu" },
+    { key: "parselStatusCellName", label: "Durum Sütunu" },
+  ];
+
+  useEffect(() => {
+    GetSupportedCRS().then((list) => {
+      setCrsList(list);
+    });
+    GetParselFields().then((fields) => {
+      setParselFields(fields);
+    });
+  }, []);
+
   window.setParselMessage = (message: string) => {
     LogDebug("window.setParselMessage: " + message);
 
@@ -57,11 +113,36 @@ export function ParselSorguComp() {
     });
   };
 
+  const updateParselFieldRules = (rules: main.ParselFieldRule[]) => {
+    setParselFieldRules(rules);
+    SetParselFields(rules);
+    setConfig((prev) =>
+      prev ? ({ ...prev, parselFields: rules } as main.Config) : prev
+    );
+  };
+
   const handleHeadless = () => {
     setConfigField("parselSorguHeadless", !parselSorguHeadless);
     setParselSorguHeadless(!parselSorguHeadless);
   };
 
+  const handleNeighborProbe = () => {
+    setConfigField("parselNeighborProbe", !parselNeighborProbe);
+    setParselNeighborProbe(!parselNeighborProbe);
+  };
+
+  const handleBackend = () => {
+    const backend = parselSorguBackend === "browser" ? "http" : "browser";
+    setConfigField("parselSorguBackend", backend);
+    setParselSorguBackend(backend);
+  };
+
+  const handleClearCache = () => {
+    ClearParselCache().then(() => {
+      setMessage("Önbellek temizlendi");
+    });
+  };
+
   const handleRun = () => {
     setRunning(true);
     AddParselSorguFields(
@@ -71,10 +152,27 @@ export function ParselSorguComp() {
       mahalleCellName,
       adaCellName,
       parselCellName,
-      alanCellName,
-      paftaCellName,
-      cinsCellName,
-      mevkiCellNameSorgu,
+      parselSorguHeadless
+    ).finally(() => {
+      setRunning(false);
+      GetParselNameReport().then((report) => {
+        setNameReport(report ?? []);
+      });
+    });
+  };
+
+  const handleRunCoordinates = () => {
+    setRunning(true);
+    AddParselFieldsFromCoordinates(
+      excelPath,
+      parselXCellName,
+      parselYCellName,
+      parselPointCRS,
+      ilCellName,
+      ilceCellName,
+      mahalleCellName,
+      adaCellName,
+      parselCellName,
       parselSorguHeadless
     ).finally(() => {
       setRunning(false);
@@ -143,51 +241,113 @@ export function ParselSorguComp() {
             }}
           />
         </div>
+      </div>
+
+      <div className="flex flex-col items-center gap-2 w-full">
+        <label>Parsel Bilgisi Sütunları</label>
+        <ParselFieldsEditor
+          rules={parselFieldRules}
+          fields={parselFields}
+          onChange={updateParselFieldRules}
+        />
+      </div>
+
+      <div className="flex flex-row">
+        {optionalFields.map((field) => (
+          <div
+            key={field.key}
+            className="flex flex-col items-center gap-2 w-full"
+          >
+            <label>{field.label}</label>
+            <Input
+              className="w-[90%]"
+              placeholder="Boş bırakılabilir"
+              value={optionalCellNames[field.key] ?? ""}
+              onChange={(e) => {
+                setConfigField(field.key, e.target.value);
+                setOptionalCellNames({
+                  ...optionalCellNames,
+                  [field.key]: e.target.value,
+                });
+              }}
+            />
+          </div>
+        ))}
 
         <div className="flex flex-col items-center gap-2 w-full">
-          <label>Alan Sütunu</label>
+          <label>Alan Toleransı (%)</label>
           <Input
             className="w-[90%]"
-            value={alanCellName}
+            type="number"
+            min={0}
+            step={0.1}
+            value={parselAreaTolerance}
             onChange={(e) => {
-              setConfigField("alanCellName", e.target.value);
-              setAlanCellName(e.target.value);
+              const tolerance = Math.max(0, parseFloat(e.target.value) || 0);
+              setConfigField("parselAreaTolerance", tolerance);
+              setParselAreaTolerance(tolerance);
             }}
           />
         </div>
 
         <div className="flex flex-col items-center gap-2 w-full">
-          <label>Pafta Sütunu</label>
+          <label>Tekrar Deneme</label>
           <Input
             className="w-[90%]"
-            value={paftaCellName}
+            type="number"
+            min={0}
+            value={parselMaxRetries}
             onChange={(e) => {
-              setConfigField("paftaCellName", e.target.value);
-              setPaftaCellName(e.target.value);
+              const retries = Math.max(0, parseInt(e.target.value) || 0);
+              setConfigField("parselMaxRetries", retries);
+              setParselMaxRetries(retries);
             }}
           />
         </div>
 
         <div className="flex flex-col items-center gap-2 w-full">
-          <label>Cins Sütunu</label>
+          <label>Eşzamanlı Sorgu</label>
           <Input
             className="w-[90%]"
-            value={cinsCellName}
+            type="number"
+            min={1}
+            value={parselConcurrency}
             onChange={(e) => {
-              setConfigField("cinsCellName", e.target.value);
-              setCinsCellName(e.target.value);
+              const concurrency = Math.max(1, parseInt(e.target.value) || 1);
+              setConfigField("parselConcurrency", concurrency);
+              setParselConcurrency(concurrency);
             }}
           />
         </div>
 
         <div className="flex flex-col items-center gap-2 w-full">
-          <label>Mevki Sütunu</label>
+          <label>Dakikada Sorgu (0: sınırsız)</label>
+          <Input
+            className="w-[90%]"
+            type="number"
+            min={0}
+            value={parselRequestsPerMinute}
+            onChange={(e) => {
+              const perMinute = Math.max(0, parseInt(e.target.value) || 0);
+              setConfigField("parselRequestsPerMinute", perMinute);
+              setParselRequestsPerMinute(perMinute);
+            }}
+          />
+        </div>
+
+        <div
+          className={`flex flex-col items-center gap-2 w-full ${parselSorguBackend !== "browser" ? "hidden" : ""}`}
+        >
+          <label>Tarayıcı Sekmesi</label>
           <Input
             className="w-[90%]"
-            value={mevkiCellNameSorgu}
+            type="number"
+            min={1}
+            value={parselBrowserTabs}
             onChange={(e) => {
-              setConfigField("mevkiCellNameSorgu", e.target.value);
-              setMevkiCellNameSorgu(e.target.value);
+              const tabs = Math.max(1, parseInt(e.target.value) || 1);
+              setConfigField("parselBrowserTabs", tabs);
+              setParselBrowserTabs(tabs);
             }}
           />
         </div>
@@ -222,12 +382,80 @@ export function ParselSorguComp() {
 
       <div className="flex flex-col gap-2 text-center">
         <div className="flex flex-col items-center gap-8">
+          <div className="flex flex-row gap-12">
+            <div className="flex flex-col items-center gap-2 font-medium text-lg">
+              Tarayıcı ile
+              <Switch
+                checked={parselSorguBackend === "browser"}
+                onCheckedChange={handleBackend}
+              />
+            </div>
+            <div className="flex flex-col items-center gap-2 font-medium text-lg">
+              Gizli
+              <Switch
+                checked={parselSorguHeadless}
+                disabled={parselSorguBackend !== "browser"}
+                onCheckedChange={handleHeadless}
+              />
+            </div>
+            <div className="flex flex-col items-center gap-2 font-medium text-lg">
+              Komşuları Sorgula
+              <Switch
+                checked={parselNeighborProbe}
+                disabled={
+                  !parselFieldRules.some(
+                    (rule) => rule.field.toLowerCase() === "komsuparseller"
+                  )
+                }
+                onCheckedChange={handleNeighborProbe}
+              />
+            </div>
+            <div className="flex flex-col items-center gap-2 font-medium text-lg">
+              Önbellek (gün)
+              <Input
+                className="w-20 text-center"
+                type="number"
+                min={0}
+                value={parselCacheTTL}
+                onChange={(e) => {
+                  const ttl = Math.max(0, parseInt(e.target.value) || 0);
+                  setConfigField("parselCacheTTL", ttl);
+                  setParselCacheTTL(ttl);
+                }}
+              />
+            </div>
+          </div>
           <div className="flex flex-col items-center gap-2 font-medium text-lg">
-            Gizli
-            <Switch
-              checked={parselSorguHeadless}
-              onCheckedChange={handleHeadless}
-            />
+            Koordinat Sistemi
+            {parselCRS && crsList.length > 0 && (
+              <Combobox
+                initialValue={parselCRS}
+                mandatory
+                elements={[
+                  { value: "auto", label: "Otomatik (TM)" },
+                  ...crsList.map((crs) => ({
+                    value: "EPSG:" + crs.code,
+                    label: crs.name,
+                  })),
+                ]}
+                placeholder="Koordinat sistemi seçin"
+                searchPlaceholder="Ara..."
+                nothingFoundMessage="Bulunamadı"
+                onChange={(value: string) => {
+                  if (value && value !== parselCRS) {
+                    setConfigField("parselCRS", value);
+                  }
+                }}
+              />
+            )}
+          </div>
+          <div className="flex flex-row gap-4">
+            <Button variant={"outline"} onClick={handleClearCache}>
+              Önbelleği Temizle
+            </Button>
+            <Button variant={"outline"} onClick={ExportParselCacheDialog}>
+              Önbelleği Dışa Aktar
+            </Button>
           </div>
           <Button
             disabled={
@@ -236,12 +464,7 @@ export function ParselSorguComp() {
               !mahalleCellName ||
               !adaCellName ||
               !parselCellName ||
-              !(
-                alanCellName ||
-                paftaCellName ||
-                cinsCellName ||
-                mevkiCellNameSorgu
-              ) ||
+              !parselFieldRules.some((rule) => rule.field && rule.column) ||
               !excelPath ||
               running
             }
@@ -254,8 +477,90 @@ export function ParselSorguComp() {
               "Sütunları Ekle"
             )}
           </Button>
+          <div className="flex flex-row items-end gap-4">
+            <div className="flex flex-col items-center gap-2">
+              <label>X / Boylam Sütunu</label>
+              <Input
+                className="w-40"
+                value={parselXCellName}
+                onChange={(e) => {
+                  setConfigField("parselXCellName", e.target.value);
+                  setParselXCellName(e.target.value);
+                }}
+              />
+            </div>
+            <div className="flex flex-col items-center gap-2">
+              <label>Y / Enlem Sütunu</label>
+              <Input
+                className="w-40"
+                value={parselYCellName}
+                onChange={(e) => {
+                  setConfigField("parselYCellName", e.target.value);
+                  setParselYCellName(e.target.value);
+                }}
+              />
+            </div>
+            {parselPointCRS && crsList.length > 0 && (
+              <Combobox
+                initialValue={parselPointCRS}
+                mandatory
+                elements={crsList.map((crs) => ({
+                  value: "EPSG:" + crs.code,
+                  label: crs.name,
+                }))}
+                placeholder="Koordinat sistemi seçin"
+                searchPlaceholder="Ara..."
+                nothingFoundMessage="Bulunamadı"
+                onChange={(value: string) => {
+                  if (value && value !== parselPointCRS) {
+                    setConfigField("parselPointCRS", value);
+                    setParselPointCRS(value);
+                  }
+                }}
+              />
+            )}
+          </div>
+          <Button
+            disabled={
+              !parselXCellName ||
+              !parselYCellName ||
+              !adaCellName ||
+              !parselCellName ||
+              !excelPath ||
+              running
+            }
+            onClick={handleRunCoordinates}
+            className="w-64"
+          >
+            {running ? (
+              <LoaderCircle className="w-6 h-6 animate-spin" />
+            ) : (
+              "Koordinatlardan Ada/Parsel Bul"
+            )}
+          </Button>
         </div>
         <div className="h-8 text-lg">{message}</div>
+        {nameReport.length > 0 && (
+          <div className="flex flex-col gap-1 max-h-48 overflow-y-auto text-left text-sm">
+            <div className="font-medium">
+              Eşleştirilemeyen il/ilçe/mahalle adları
+            </div>
+            {nameReport.map((issue) => (
+              <div key={issue.row}>
+                Satır {issue.row}:{" "}
+                {issue.kind === "ambiguous" ? "Belirsiz" : "Bulunamadı"} -{" "}
+                {issue.candidates
+                  .map(
+                    (candidate) =>
+                      `${candidate.name} (%${Math.round(
+                        candidate.score * 100
+                      )})`
+                  )
+                  .join(", ")}
+              </div>
+            ))}
+          </div>
+        )}
       </div>
     </div>
   );
@@ -0,0 +1,14 @@
+import { useTranslation } from "react-i18next";
+import { SwitchConfig } from "./Presets/SwitchConfig";
+
+export function ExcelBackupSetting() {
+  const { t } = useTranslation();
+
+  return (
+    <SwitchConfig
+      configKey="excelBackup"
+      label={t("settings.setting.excel_backup.label")}
+      description={t("settings.setting.excel_backup.description")}
+    />
+  );
+}
@@ -0,0 +1,64 @@
+import { useEffect, useState } from "react";
+import { useTranslation } from "react-i18next";
+import {
+  SettingsItem,
+  SettingContent,
+  SettingDescription,
+  SettingLabel,
+} from "@/components/ui/settings-group";
+import { Input } from "@/components/ui/input";
+import { useConfig } from "@/contexts/config-provider";
+
+export function ExcelCheckpointRowsSetting() {
+  const { config, setConfigField } = useConfig();
+  const { t } = useTranslation();
+  const [{ isLoading, excelCheckpointRows }, setState] = useState({
+    isLoading: true,
+    excelCheckpointRows: "",
+  });
+
+  useEffect(() => {
+    if (isLoading && config?.excelCheckpointRows !== undefined) {
+      setState({
+        isLoading: false,
+        excelCheckpointRows: config.excelCheckpointRows.toString(),
+      });
+    }
+  }, [isLoading, config?.excelCheckpointRows]);
+
+  const handleExcelCheckpointRowsChange = (textValue: string) => {
+    const parsedValue = parseInt(textValue);
+    const value = isNaN(parsedValue)
+      ? 25
+      : Math.max(0, Math.min(10000, parsedValue));
+    setConfigField("excelCheckpointRows", value);
+    setState((prevState) => ({
+      ...prevState,
+      excelCheckpointRows: textValue === "" ? "" : value.toString(),
+    }));
+  };
+
+  return (
+    <SettingsItem loading={isLoading} configKey="excelCheckpointRows">
+      <div>
+        <SettingLabel>
+          {t("settings.setting.excel_checkpoint_rows.label")}
+        </SettingLabel>
+        <SettingDescription>
+          {t("settings.setting.excel_checkpoint_rows.description")}
+        </SettingDescription>
+      </div>
+      <SettingContent>
+        <Input
+          type="number"
+          placeholder="25"
+          value={excelCheckpointRows}
+          onChange={(e) => handleExcelCheckpointRowsChange(e.target.value)}
+          min={0}
+          max={10000}
+          onKeyDown={(e) => e.key.match(/[-+]/) && e.preventDefault()}
+        />
+      </SettingContent>
+    </SettingsItem>
+  );
+}
@@ -0,0 +1,53 @@
+import { useEffect, useState } from "react";
+import { useTranslation } from "react-i18next";
+import {
+  SettingsItem,
+  SettingContent,
+  SettingDescription,
+  SettingLabel,
+} from "@/components/ui/settings-group";
+import { Input } from "@/components/ui/input";
+import { useConfig } from "@/contexts/config-provider";
+
+export function ExcelColumnTypesSetting() {
+  const { config, setConfigField } = useConfig();
+  const { t } = useTranslation();
+  const [{ isLoading, excelColumnTypes }, setState] = useState({
+    isLoading: true,
+    excelColumnTypes: "",
+  });
+
+  useEffect(() => {
+    if (isLoading && config?.excelColumnTypes !== undefined) {
+      setState({
+        isLoading: false,
+        excelColumnTypes: config.excelColumnTypes,
+      });
+    }
+  }, [isLoading, config?.excelColumnTypes]);
+
+  const handleExcelColumnTypesChange = (value: string) => {
+    setConfigField("excelColumnTypes", value);
+    setState((prevState) => ({ ...prevState, excelColumnTypes: value }));
+  };
+
+  return (
+    <SettingsItem loading={isLoading} configKey="excelColumnTypes">
+      <div>
+        <SettingLabel>
+          {t("settings.setting.excel_column_types.label")}
+        </SettingLabel>
+        <SettingDescription>
+          {t("settings.setting.excel_column_types.description")}
+        </SettingDescription>
+      </div>
+      <SettingContent>
+        <Input
+          placeholder="Ada->tamsayi,Alan (m2)->ondalik:2,Tarih->tarih"
+          value={excelColumnTypes}
+          onChange={(e) => handleExcelColumnTypesChange(e.target.value)}
+        />
+      </SettingContent>
+    </SettingsItem>
+  );
+}
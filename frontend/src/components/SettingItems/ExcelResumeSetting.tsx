@@ -0,0 +1,14 @@
+import { useTranslation } from "react-i18next";
+import { SwitchConfig } from "./Presets/SwitchConfig";
+
+export function ExcelResumeSetting() {
+  const { t } = useTranslation();
+
+  return (
+    <SwitchConfig
+      configKey="excelResume"
+      label={t("settings.setting.excel_resume.label")}
+      description={t("settings.setting.excel_resume.description")}
+    />
+  );
+}
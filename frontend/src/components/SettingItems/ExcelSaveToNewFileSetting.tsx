@@ -0,0 +1,14 @@
+import { useTranslation } from "react-i18next";
+import { SwitchConfig } from "./Presets/SwitchConfig";
+
+export function ExcelSaveToNewFileSetting() {
+  const { t } = useTranslation();
+
+  return (
+    <SwitchConfig
+      configKey="excelSaveToNewFile"
+      label={t("settings.setting.excel_save_to_new_file.label")}
+      description={t("settings.setting.excel_save_to_new_file.description")}
+    />
+  );
+}
@@ -14,6 +14,11 @@ import { WindowOpacitySetting } from "./SettingItems/WindowOpacitySetting";
 import { SaveWindowStatusSetting } from "./SettingItems/SaveWindowStatusSetting";
 import { CheckForUpdatesSetting } from "./SettingItems/CheckForUpdatesSetting";
 import { UpdateSetting } from "./SettingItems/UpdateSetting";
+import { ExcelBackupSetting } from "./SettingItems/ExcelBackupSetting";
+import { ExcelSaveToNewFileSetting } from "./SettingItems/ExcelSaveToNewFileSetting";
+import { ExcelCheckpointRowsSetting } from "./SettingItems/ExcelCheckpointRowsSetting";
+import { ExcelResumeSetting } from "./SettingItems/ExcelResumeSetting";
+import { ExcelColumnTypesSetting } from "./SettingItems/ExcelColumnTypesSetting";
 import { useEffect, useState } from "react";
 import { useStorage } from "@/contexts/storage-provider";
 
@@ -87,7 +92,13 @@ export default function Settings() {
         </SettingsGroup>
       </TabsContent>
       <TabsContent value="system" className="w-full">
-        Edit your system settings here.
+        <SettingsGroup className="flex flex-col items-start px-4 py-2 w-full h-full">
+          <ExcelBackupSetting />
+          <ExcelSaveToNewFileSetting />
+          <ExcelCheckpointRowsSetting />
+          <ExcelResumeSetting />
+          <ExcelColumnTypesSetting />
+        </SettingsGroup>
       </TabsContent>
       <TabsContent value="advanced" className="w-full">
         <SettingsGroup className="flex flex-col items-start px-4 py-2 w-full h-full">
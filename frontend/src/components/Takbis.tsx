@@ -1,34 +1,121 @@
 import { useEffect, useState } from "react";
 import { Button } from "./ui/button";
 import {
+  DeleteTakbisMappingPreset,
   GetExcelFileDialog,
   GetExcelFilesDialog,
+  GetExcelHeaders,
+  GetTakbisReport,
   ModifyExcelWithTakbis,
   OpenFile,
+  SaveTakbisMappingPreset,
+  SetTakbisMapping,
+  ValidateTakbisMapping,
 } from "@/wailsjs/go/main/App";
 import { LoaderCircle, X } from "lucide-react";
 import { useConfig } from "@/contexts/config-provider";
 import { Input } from "./ui/input";
 import { LogDebug } from "@/wailsjs/runtime/runtime";
+import { main } from "@/wailsjs/go/models";
+import { Combobox } from "./ui/combobox";
+import { TakbisMappingEditor } from "./TakbisMappingEditor";
 
 export function Takbis() {
-  const { config, setConfigField } = useConfig();
+  const { config, setConfig, setConfigField } = useConfig();
 
   const [takbisPaths, setTakbisPaths] = useState<string[]>([]);
   const [excelPath, setExcelPath] = useState<string>("");
-  const [excelHeaderMatchPattern, setExcelHeaderMatchPattern] =
-    useState<string>("");
-  const [excelCellModifyPattern, setExcelCellModifyPattern] =
-    useState<string>("");
+  const [mapping, setMapping] = useState<main.TakbisMapping | null>(null);
+  const [targetHeaders, setTargetHeaders] = useState<string[]>([]);
+  const [takbisHeaders, setTakbisHeaders] = useState<string[]>([]);
+  const [presetName, setPresetName] = useState<string>("");
+  const [issues, setIssues] = useState<main.TakbisMappingIssue[]>([]);
+  const [takbisReportSheet, setTakbisReportSheet] = useState<string>("");
+  const [report, setReport] = useState<main.TakbisRowResult[]>([]);
 
   const [message, setMessage] = useState<string>("");
   const [running, setRunning] = useState<boolean>(false);
 
   useEffect(() => {
-    setExcelHeaderMatchPattern(config?.excelHeaderMatchPattern!);
-    setExcelCellModifyPattern(config?.excelCellModifyPattern!);
+    setTakbisReportSheet(config?.takbisReportSheet!);
+    if (!mapping && config?.takbisMapping) {
+      setMapping(config.takbisMapping);
+    }
   }, [config]);
 
+  useEffect(() => {
+    if (!excelPath) {
+      setTargetHeaders([]);
+      return;
+    }
+    GetExcelHeaders(excelPath)
+      .then((headers) => setTargetHeaders(headers ?? []))
+      .catch(() => setTargetHeaders([]));
+  }, [excelPath]);
+
+  useEffect(() => {
+    Promise.all(takbisPaths.map((path) => GetExcelHeaders(path).catch(() => [])))
+      .then((headersList) => {
+        setTakbisHeaders([...new Set(headersList.flatMap((headers) => headers ?? []))]);
+      });
+  }, [takbisPaths]);
+
+  const updateMapping = (next: main.TakbisMapping) => {
+    setMapping(next);
+    setIssues([]);
+    SetTakbisMapping(next);
+    setConfig((prev) =>
+      prev ? ({ ...prev, takbisMapping: next } as main.Config) : prev
+    );
+  };
+
+  const presets = config?.takbisMappingPresets ?? {};
+
+  const handleSavePreset = () => {
+    if (!mapping) {
+      return;
+    }
+    const name = presetName.trim();
+    SaveTakbisMappingPreset(name, mapping)
+      .then(() => {
+        setConfig((prev) =>
+          prev
+            ? ({
+                ...prev,
+                takbisMappingPresets: { ...prev.takbisMappingPresets, [name]: mapping },
+              } as main.Config)
+            : prev
+        );
+        setMessage(`${name} kaydedildi`);
+      })
+      .catch((error) => setMessage(String(error)));
+  };
+
+  const handleDeletePreset = () => {
+    const name = presetName.trim();
+    DeleteTakbisMappingPreset(name).then(() => {
+      setConfig((prev) => {
+        if (!prev) {
+          return prev;
+        }
+        const presets = { ...prev.takbisMappingPresets };
+        delete presets[name];
+        return { ...prev, takbisMappingPresets: presets } as main.Config;
+      });
+      setPresetName("");
+    });
+  };
+
+  const handleValidate = () => {
+    if (!mapping) {
+      return;
+    }
+    ValidateTakbisMapping(excelPath, takbisPaths, mapping).then((issues) => {
+      setIssues(issues ?? []);
+      setMessage(issues?.length ? "" : "Eşleştirme geçerli");
+    });
+  };
+
   const handleExcelFilesDialog = () => {
     GetExcelFilesDialog().then((paths) => {
       if (paths) {
@@ -47,12 +134,7 @@ export function Takbis() {
 
   const handleRun = () => {
     setRunning(true);
-    ModifyExcelWithTakbis(
-      excelPath,
-      takbisPaths,
-      excelHeaderMatchPattern,
-      excelCellModifyPattern
-    )
+    ModifyExcelWithTakbis(excelPath, takbisPaths, mapping!)
       .then((error) => {
         if (error !== "") {
           setMessage(error);
@@ -60,6 +142,9 @@ export function Takbis() {
       })
       .finally(() => {
         setRunning(false);
+        GetTakbisReport().then((report) => {
+          setReport(report ?? []);
+        });
       });
   };
 
@@ -138,48 +223,121 @@ export function Takbis() {
       </div>
 
       <div className="flex flex-col items-center gap-2 w-full">
-        <label>Sütun Adı Karşılaştırma Kuralı</label>
-        <Input
-          className="w-[90%]"
-          value={excelHeaderMatchPattern}
-          onChange={(e) => {
-            setConfigField("excelHeaderMatchPattern", e.target.value);
-            setExcelHeaderMatchPattern(e.target.value);
-          }}
-        />
+        <label>Eşleştirme Şablonu</label>
+        <div className="flex items-center gap-2 w-[90%]">
+          <Combobox
+            key={Object.keys(presets).join("\u0000")}
+            placeholder="Şablon seçin..."
+            elements={Object.keys(presets).map((name) => ({
+              value: name,
+              label: name,
+            }))}
+            searchPlaceholder="Ara..."
+            nothingFoundMessage="Kayıtlı şablon yok"
+            onChange={(name: string) => {
+              if (name && presets[name]) {
+                setPresetName(name);
+                updateMapping(main.TakbisMapping.createFrom(presets[name]));
+              }
+            }}
+          />
+          <Input
+            placeholder="Şablon adı"
+            value={presetName}
+            onChange={(e) => setPresetName(e.target.value)}
+          />
+          <Button
+            variant={"outline"}
+            disabled={!presetName.trim() || !mapping}
+            onClick={handleSavePreset}
+          >
+            Kaydet
+          </Button>
+          <Button
+            variant={"outline"}
+            disabled={!presets[presetName.trim()]}
+            onClick={handleDeletePreset}
+          >
+            Sil
+          </Button>
+        </div>
       </div>
 
+      {mapping && (
+        <TakbisMappingEditor
+          mapping={mapping}
+          targetHeaders={targetHeaders}
+          takbisHeaders={takbisHeaders}
+          onChange={updateMapping}
+        />
+      )}
+
       <div className="flex flex-col items-center gap-2 w-full">
-        <label>Hücre Değişimi Kuralı</label>
+        <label>Eşleşme Raporu Sayfası</label>
         <Input
           className="w-[90%]"
-          value={excelCellModifyPattern}
+          placeholder="Boş bırakılırsa rapor yazılmaz"
+          value={takbisReportSheet}
           onChange={(e) => {
-            setConfigField("excelCellModifyPattern", e.target.value);
-            setExcelCellModifyPattern(e.target.value);
+            setConfigField("takbisReportSheet", e.target.value);
+            setTakbisReportSheet(e.target.value);
           }}
         />
       </div>
 
       <div className="flex flex-col items-center gap-2 w-full">
-        <Button
-          disabled={
-            !excelCellModifyPattern ||
-            !excelHeaderMatchPattern ||
-            takbisPaths.length === 0 ||
-            !excelPath ||
-            running
-          }
-          onClick={handleRun}
-          className="w-64"
-        >
-          {running ? (
-            <LoaderCircle className="w-6 h-6 animate-spin" />
-          ) : (
-            "Sütunları Ekle"
-          )}
-        </Button>
+        <div className="flex gap-2">
+          <Button variant={"outline"} disabled={!mapping} onClick={handleValidate}>
+            Doğrula
+          </Button>
+          <Button
+            disabled={
+              !mapping?.match?.length ||
+              !mapping?.cells?.length ||
+              takbisPaths.length === 0 ||
+              !excelPath ||
+              running
+            }
+            onClick={handleRun}
+            className="w-64"
+          >
+            {running ? (
+              <LoaderCircle className="w-6 h-6 animate-spin" />
+            ) : (
+              "Sütunları Ekle"
+            )}
+          </Button>
+        </div>
         <div className="h-8 text-lg">{message}</div>
+        {issues.length > 0 && (
+          <div className="flex flex-col gap-1 text-left text-sm">
+            {issues.map((issue, index) => (
+              <div
+                key={index}
+                className={issue.level === "error" ? "text-destructive" : "text-muted-foreground"}
+              >
+                {issue.level === "error" ? "Hata" : "Uyarı"}: {issue.message}
+              </div>
+            ))}
+          </div>
+        )}
+        {report.some((result) => !result.matched || result.candidates?.length > 1) && (
+          <div className="flex flex-col gap-1 max-h-48 overflow-y-auto text-left text-sm">
+            <div className="font-medium">
+              Eşleşmeyen veya birden fazla adayı olan satırlar
+            </div>
+            {report
+              .filter((result) => !result.matched || result.candidates?.length > 1)
+              .map((result) => (
+                <div key={result.row}>
+                  Satır {result.row} ({result.key}):{" "}
+                  {result.matched
+                    ? `${result.candidates.join(", ")} - ${result.chosen} kullanıldı`
+                    : "Eşleşmedi"}
+                </div>
+              ))}
+          </div>
+        )}
       </div>
     </div>
   );
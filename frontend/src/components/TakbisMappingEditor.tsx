@@ -0,0 +1,169 @@
+import { Plus, X } from "lucide-react";
+import { Button } from "./ui/button";
+import { Input } from "./ui/input";
+import { Combobox } from "./ui/combobox";
+import { main } from "@/wailsjs/go/models";
+
+const aggregates = [
+  { value: "none", label: "Tek satır" },
+  { value: "join", label: "Birleştir (join)" },
+  { value: "sum", label: "Topla (sum)" },
+  { value: "count", label: "Say (count)" },
+  { value: "first", label: "İlk (first)" },
+  { value: "last", label: "Son (last)" },
+];
+
+interface TakbisMappingEditorProps {
+  mapping: main.TakbisMapping;
+  targetHeaders: string[];
+  takbisHeaders: string[];
+  onChange: (mapping: main.TakbisMapping) => void;
+}
+
+export function TakbisMappingEditor(props: TakbisMappingEditorProps) {
+  const { mapping, targetHeaders, takbisHeaders } = props;
+
+  const onChange = (changes: Partial<main.TakbisMapping>) => {
+    props.onChange(main.TakbisMapping.createFrom({ ...mapping, ...changes }));
+  };
+
+  const setMatch = (index: number, rule: Partial<main.TakbisMatchRule>) => {
+    onChange({
+      match: mapping.match.map((r, i) => (i === index ? { ...r, ...rule } : r)),
+    });
+  };
+
+  const setCell = (index: number, rule: Partial<main.TakbisCellRule>) => {
+    onChange({
+      cells: mapping.cells.map((r, i) => (i === index ? { ...r, ...rule } : r)),
+    });
+  };
+
+  return (
+    <div className="flex flex-col items-center gap-6 w-full">
+      <datalist id="takbis-target-headers">
+        {targetHeaders.map((header) => (
+          <option key={header} value={header} />
+        ))}
+      </datalist>
+      <datalist id="takbis-takbis-headers">
+        {takbisHeaders.map((header) => (
+          <option key={header} value={header} />
+        ))}
+      </datalist>
+
+      <div className="flex flex-col items-center gap-2 w-[90%]">
+        <label>Eşleştirme Sütunları</label>
+        {mapping.match.map((rule, index) => (
+          <div className="flex items-center gap-2 w-full" key={index}>
+            <Input
+              placeholder="Hedef sütun"
+              list="takbis-target-headers"
+              value={rule.target}
+              onChange={(e) => setMatch(index, { target: e.target.value })}
+            />
+            <span>=</span>
+            <Input
+              placeholder="TAKBIS sütunu"
+              list="takbis-takbis-headers"
+              value={rule.takbis}
+              onChange={(e) => setMatch(index, { takbis: e.target.value })}
+            />
+            <Button
+              variant={"destructive"}
+              className="rounded-sm w-4 h-4 shrink-0"
+              size={"icon"}
+              onClick={() =>
+                onChange({
+                  match: mapping.match.filter((_, i) => i !== index),
+                })
+              }
+            >
+              <X className="p-0.5" />
+            </Button>
+          </div>
+        ))}
+        <Button
+          variant={"outline"}
+          size={"sm"}
+          onClick={() =>
+            onChange({
+              match: [...mapping.match, { target: "", takbis: "" }],
+            })
+          }
+        >
+          <Plus className="mr-1 w-4 h-4" /> Eşleştirme Ekle
+        </Button>
+      </div>
+
+      <div className="flex flex-col items-center gap-2 w-[90%]">
+        <label>Hücre Değişimleri</label>
+        {mapping.cells.map((rule, index) => (
+          <div className="flex items-center gap-2 w-full" key={index}>
+            <Input
+              placeholder="TAKBIS sütunu"
+              list="takbis-takbis-headers"
+              value={rule.takbis}
+              onChange={(e) => setCell(index, { takbis: e.target.value })}
+            />
+            <span>→</span>
+            <Input
+              placeholder="Hedef sütun"
+              list="takbis-target-headers"
+              value={rule.target}
+              onChange={(e) => setCell(index, { target: e.target.value })}
+            />
+            <Combobox
+              key={`${index}-${rule.aggregate}`}
+              initialValue={rule.aggregate || "none"}
+              mandatory
+              elements={aggregates}
+              searchPlaceholder="Ara..."
+              nothingFoundMessage="Bulunamadı"
+              onChange={(value: string) => {
+                const aggregate = value === "none" ? "" : value;
+                if (value && aggregate !== rule.aggregate) {
+                  setCell(index, { aggregate, separator: "" });
+                }
+              }}
+            />
+            {rule.aggregate === "join" && (
+              <Input
+                className="w-24 shrink-0"
+                placeholder='", "'
+                value={rule.separator}
+                onChange={(e) => setCell(index, { separator: e.target.value })}
+              />
+            )}
+            <Button
+              variant={"destructive"}
+              className="rounded-sm w-4 h-4 shrink-0"
+              size={"icon"}
+              onClick={() =>
+                onChange({
+                  cells: mapping.cells.filter((_, i) => i !== index),
+                })
+              }
+            >
+              <X className="p-0.5" />
+            </Button>
+          </div>
+        ))}
+        <Button
+          variant={"outline"}
+          size={"sm"}
+          onClick={() =>
+            onChange({
+              cells: [
+                ...mapping.cells,
+                { takbis: "", target: "", aggregate: "", separator: "" },
+              ],
+            })
+          }
+        >
+          <Plus className="mr-1 w-4 h-4" /> Hücre Değişimi Ekle
+        </Button>
+      </div>
+    </div>
+  );
+}
@@ -51,7 +51,8 @@ export const ConfigProvider: React.FC<{ children: ReactNode }> = ({
           // Update the config state with the new value
           setConfig((prevConfig) => {
             if (prevConfig) {
-              return { ...prevConfig, [key]: value };
+              // Spreading drops convertValues, only the fields are used
+              return { ...prevConfig, [key]: value } as main.Config;
             }
             return prevConfig;
           });
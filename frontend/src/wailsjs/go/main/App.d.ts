@@ -2,16 +2,32 @@
 // This file is automatically generated. DO NOT EDIT
 import {main} from '../models';
 
-export function AddParselSorguFields(arg1:string,arg2:string,arg3:string,arg4:string,arg5:string,arg6:string,arg7:string,arg8:string,arg9:string,arg10:string,arg11:boolean):Promise<void>;
+export function AddParselFieldsFromCoordinates(arg1:string,arg2:string,arg3:string,arg4:string,arg5:string,arg6:string,arg7:string,arg8:string,arg9:string,arg10:boolean):Promise<void>;
+
+export function AddParselSorguFields(arg1:string,arg2:string,arg3:string,arg4:string,arg5:string,arg6:string,arg7:boolean):Promise<void>;
 
 export function AddTapuToExcel(arg1:string,arg2:string,arg3:string,arg4:string,arg5:string,arg6:string,arg7:string):Promise<string>;
 
 export function CheckForUpdate():Promise<main.UpdateInfo>;
 
+export function ClearParselCache():Promise<void>;
+
+export function ComputeParselMetrics(arg1:main.QueryParams):Promise<main.ParselMetrics>;
+
 export function CreateFolders(arg1:string,arg2:string,arg3:string,arg4:string,arg5:string,arg6:boolean,arg7:string,arg8:string,arg9:string,arg10:string):Promise<string>;
 
 export function CreateFoldersV2(arg1:string,arg2:string,arg3:string):Promise<string>;
 
+export function DeleteTakbisMappingPreset(arg1:string):Promise<void>;
+
+export function ExportParselCache(arg1:string):Promise<void>;
+
+export function ExportParselCacheDialog():Promise<void>;
+
+export function ExportParselGeometry(arg1:main.QueryParams,arg2:string,arg3:string):Promise<Array<string>>;
+
+export function FindParselNeighbors(arg1:main.QueryParams):Promise<Array<main.ParselNeighbor>>;
+
 export function GetConfig():Promise<main.Config>;
 
 export function GetConfigField(arg1:string):Promise<any>;
@@ -22,10 +38,24 @@ export function GetExcelFileDialog():Promise<string>;
 
 export function GetExcelFilesDialog():Promise<Array<string>>;
 
+export function GetExcelHeaders(arg1:string):Promise<Array<string>>;
+
 export function GetFileDialog():Promise<string>;
 
+export function GetLastTapuReport():Promise<Array<main.TapuRowResult>>;
+
 export function GetLoadConfigPath():Promise<string>;
 
+export function GetParselCache():Promise<Array<main.ParselCacheEntry>>;
+
+export function GetParselFields():Promise<Array<string>>;
+
+export function GetParselNameReport():Promise<Array<main.ParselNameIssue>>;
+
+export function GetSupportedCRS():Promise<Array<main.CRS>>;
+
+export function GetTakbisReport():Promise<Array<main.TakbisRowResult>>;
+
 export function GetTargetFolderDialog():Promise<string>;
 
 export function GetVersion():Promise<string>;
@@ -34,7 +64,9 @@ export function GetWordFileDialog():Promise<string>;
 
 export function InitParselSorgu(arg1:boolean):Promise<void>;
 
-export function ModifyExcelWithTakbis(arg1:string,arg2:Array<string>,arg3:string,arg4:string):Promise<string>;
+export function InvalidateParselCacheEntry(arg1:string):Promise<void>;
+
+export function ModifyExcelWithTakbis(arg1:string,arg2:Array<string>,arg3:main.TakbisMapping):Promise<string>;
 
 export function NeedsAdminPrivileges():Promise<boolean>;
 
@@ -46,16 +78,30 @@ export function ParseTapu(arg1:string):Promise<main.Tapu>;
 
 export function ParselSorgu(arg1:main.QueryParams):Promise<main.Properties>;
 
+export function ParselSorguByCoordinate(arg1:main.CoordinateQuery):Promise<main.FeatureCollection>;
+
+export function ParselSorguByPafta(arg1:main.PaftaQueryParams):Promise<main.FeatureCollection>;
+
+export function ParselSorguGeometry(arg1:main.QueryParams):Promise<main.FeatureCollection>;
+
 export function ReadConfig(arg1:string):Promise<void>;
 
 export function RestartApplication(arg1:boolean,arg2:Array<string>):Promise<void>;
 
 export function SaveConfigDialog():Promise<void>;
 
+export function SaveTakbisMappingPreset(arg1:string,arg2:main.TakbisMapping):Promise<void>;
+
 export function SendNotification(arg1:string,arg2:string,arg3:string,arg4:string):Promise<void>;
 
 export function SetConfigField(arg1:string,arg2:any):Promise<void>;
 
+export function SetParselFields(arg1:Array<main.ParselFieldRule>):Promise<void>;
+
+export function SetTakbisMapping(arg1:main.TakbisMapping):Promise<void>;
+
 export function Update(arg1:string):Promise<void>;
 
 export function UpdateAsAdmin(arg1:string):Promise<void>;
+
+export function ValidateTakbisMapping(arg1:string,arg2:Array<string>,arg3:main.TakbisMapping):Promise<Array<main.TakbisMappingIssue>>;
@@ -2,8 +2,12 @@
 // Cynhyrchwyd y ffeil hon yn awtomatig. PEIDIWCH Â MODIWL
 // This file is automatically generated. DO NOT EDIT
 
-export function AddParselSorguFields(arg1, arg2, arg3, arg4, arg5, arg6, arg7, arg8, arg9, arg10, arg11) {
-  return window['go']['main']['App']['AddParselSorguFields'](arg1, arg2, arg3, arg4, arg5, arg6, arg7, arg8, arg9, arg10, arg11);
+export function AddParselFieldsFromCoordinates(arg1, arg2, arg3, arg4, arg5, arg6, arg7, arg8, arg9, arg10) {
+  return window['go']['main']['App']['AddParselFieldsFromCoordinates'](arg1, arg2, arg3, arg4, arg5, arg6, arg7, arg8, arg9, arg10);
+}
+
+export function AddParselSorguFields(arg1, arg2, arg3, arg4, arg5, arg6, arg7) {
+  return window['go']['main']['App']['AddParselSorguFields'](arg1, arg2, arg3, arg4, arg5, arg6, arg7);
 }
 
 export function AddTapuToExcel(arg1, arg2, arg3, arg4, arg5, arg6, arg7) {
@@ -14,6 +18,14 @@ export function CheckForUpdate() {
   return window['go']['main']['App']['CheckForUpdate']();
 }
 
+export function ClearParselCache() {
+  return window['go']['main']['App']['ClearParselCache']();
+}
+
+export function ComputeParselMetrics(arg1) {
+  return window['go']['main']['App']['ComputeParselMetrics'](arg1);
+}
+
 export function CreateFolders(arg1, arg2, arg3, arg4, arg5, arg6, arg7, arg8, arg9, arg10) {
   return window['go']['main']['App']['CreateFolders'](arg1, arg2, arg3, arg4, arg5, arg6, arg7, arg8, arg9, arg10);
 }
@@ -22,6 +34,26 @@ export function CreateFoldersV2(arg1, arg2, arg3) {
   return window['go']['main']['App']['CreateFoldersV2'](arg1, arg2, arg3);
 }
 
+export function DeleteTakbisMappingPreset(arg1) {
+  return window['go']['main']['App']['DeleteTakbisMappingPreset'](arg1);
+}
+
+export function ExportParselCache(arg1) {
+  return window['go']['main']['App']['ExportParselCache'](arg1);
+}
+
+export function ExportParselCacheDialog() {
+  return window['go']['main']['App']['ExportParselCacheDialog']();
+}
+
+export function ExportParselGeometry(arg1, arg2, arg3) {
+  return window['go']['main']['App']['ExportParselGeometry'](arg1, arg2, arg3);
+}
+
+export function FindParselNeighbors(arg1) {
+  return window['go']['main']['App']['FindParselNeighbors'](arg1);
+}
+
 export function GetConfig() {
   return window['go']['main']['App']['GetConfig']();
 }
@@ -42,14 +74,42 @@ export function GetExcelFilesDialog() {
   return window['go']['main']['App']['GetExcelFilesDialog']();
 }
 
+export function GetExcelHeaders(arg1) {
+  return window['go']['main']['App']['GetExcelHeaders'](arg1);
+}
+
 export function GetFileDialog() {
   return window['go']['main']['App']['GetFileDialog']();
 }
 
+export function GetLastTapuReport() {
+  return window['go']['main']['App']['GetLastTapuReport']();
+}
+
 export function GetLoadConfigPath() {
   return window['go']['main']['App']['GetLoadConfigPath']();
 }
 
+export function GetParselCache() {
+  return window['go']['main']['App']['GetParselCache']();
+}
+
+export function GetParselFields() {
+  return window['go']['main']['App']['GetParselFields']();
+}
+
+export function GetParselNameReport() {
+  return window['go']['main']['App']['GetParselNameReport']();
+}
+
+export function GetSupportedCRS() {
+  return window['go']['main']['App']['GetSupportedCRS']();
+}
+
+export function GetTakbisReport() {
+  return window['go']['main']['App']['GetTakbisReport']();
+}
+
 export function GetTargetFolderDialog() {
   return window['go']['main']['App']['GetTargetFolderDialog']();
 }
@@ -66,8 +126,12 @@ export function InitParselSorgu(arg1) {
   return window['go']['main']['App']['InitParselSorgu'](arg1);
 }
 
-export function ModifyExcelWithTakbis(arg1, arg2, arg3, arg4) {
-  return window['go']['main']['App']['ModifyExcelWithTakbis'](arg1, arg2, arg3, arg4);
+export function InvalidateParselCacheEntry(arg1) {
+  return window['go']['main']['App']['InvalidateParselCacheEntry'](arg1);
+}
+
+export function ModifyExcelWithTakbis(arg1, arg2, arg3) {
+  return window['go']['main']['App']['ModifyExcelWithTakbis'](arg1, arg2, arg3);
 }
 
 export function NeedsAdminPrivileges() {
@@ -90,6 +154,18 @@ export function ParselSorgu(arg1) {
   return window['go']['main']['App']['ParselSorgu'](arg1);
 }
 
+export function ParselSorguByCoordinate(arg1) {
+  return window['go']['main']['App']['ParselSorguByCoordinate'](arg1);
+}
+
+export function ParselSorguByPafta(arg1) {
+  return window['go']['main']['App']['ParselSorguByPafta'](arg1);
+}
+
+export function ParselSorguGeometry(arg1) {
+  return window['go']['main']['App']['ParselSorguGeometry'](arg1);
+}
+
 export function ReadConfig(arg1) {
   return window['go']['main']['App']['ReadConfig'](arg1);
 }
@@ -102,6 +178,10 @@ export function SaveConfigDialog() {
   return window['go']['main']['App']['SaveConfigDialog']();
 }
 
+export function SaveTakbisMappingPreset(arg1, arg2) {
+  return window['go']['main']['App']['SaveTakbisMappingPreset'](arg1, arg2);
+}
+
 export function SendNotification(arg1, arg2, arg3, arg4) {
   return window['go']['main']['App']['SendNotification'](arg1, arg2, arg3, arg4);
 }
@@ -110,6 +190,14 @@ export function SetConfigField(arg1, arg2) {
   return window['go']['main']['App']['SetConfigField'](arg1, arg2);
 }
 
+export function SetParselFields(arg1) {
+  return window['go']['main']['App']['SetParselFields'](arg1);
+}
+
+export function SetTakbisMapping(arg1) {
+  return window['go']['main']['App']['SetTakbisMapping'](arg1);
+}
+
 export function Update(arg1) {
   return window['go']['main']['App']['Update'](arg1);
 }
@@ -117,3 +205,7 @@ export function Update(arg1) {
 export function UpdateAsAdmin(arg1) {
   return window['go']['main']['App']['UpdateAsAdmin'](arg1);
 }
+
+export function ValidateTakbisMapping(arg1, arg2, arg3) {
+  return window['go']['main']['App']['ValidateTakbisMapping'](arg1, arg2, arg3);
+}
@@ -1,5 +1,97 @@
 export namespace main {
 	
+	export class CRS {
+	    code: number;
+	    name: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new CRS(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.code = source["code"];
+	        this.name = source["name"];
+	    }
+	}
+	export class ParselFieldRule {
+	    field: string;
+	    column: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new ParselFieldRule(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.field = source["field"];
+	        this.column = source["column"];
+	    }
+	}
+	export class TakbisCellRule {
+	    takbis: string;
+	    target: string;
+	    aggregate: string;
+	    separator: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new TakbisCellRule(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.takbis = source["takbis"];
+	        this.target = source["target"];
+	        this.aggregate = source["aggregate"];
+	        this.separator = source["separator"];
+	    }
+	}
+	export class TakbisMatchRule {
+	    target: string;
+	    takbis: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new TakbisMatchRule(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.target = source["target"];
+	        this.takbis = source["takbis"];
+	    }
+	}
+	export class TakbisMapping {
+	    match: TakbisMatchRule[];
+	    cells: TakbisCellRule[];
+	
+	    static createFrom(source: any = {}) {
+	        return new TakbisMapping(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.match = this.convertValues(source["match"], TakbisMatchRule);
+	        this.cells = this.convertValues(source["cells"], TakbisCellRule);
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
 	export class Config {
 	    theme?: string;
 	    useSystemTitleBar?: boolean;
@@ -32,20 +124,39 @@ export namespace main {
 	    mahalleCellName?: string;
 	    adaCellName?: string;
 	    parselCellName?: string;
-	    alanCellName?: string;
-	    paftaCellName?: string;
 	    parselSorguHeadless?: boolean;
-	    ciltCellName?: string;
-	    sayfaCellName?: string;
+	    parselSorguBackend?: string;
+	    parselCacheTTL?: number;
+	    parselExportFormats?: string;
+	    parselExportOnCreate?: boolean;
+	    parselCRS?: string;
+	    parselYuzolcumCellName?: string;
+	    parselAreaTolerance?: number;
+	    parselMaxRetries?: number;
+	    parselStatusCellName?: string;
+	    parselNameMinScore?: number;
+	    parselBrowserTabs?: number;
+	    parselConcurrency?: number;
+	    parselRequestsPerMinute?: number;
+	    parselXCellName?: string;
+	    parselYCellName?: string;
+	    parselPointCRS?: string;
+	    parselNeighborProbe?: boolean;
 	    tapuNamePattern?: string;
-	    mevkiCellName?: string;
-	    alanCellNameTapu?: string;
-	    excelHeaderMatchPattern?: string;
-	    excelCellModifyPattern?: string;
-	    mevkiCellNameSorgu?: string;
-	    cinsCellName?: string;
+	    tapuFieldMapping?: string;
+	    tapuMinConfidence?: number;
+	    excelBackup?: boolean;
+	    excelSaveToNewFile?: boolean;
+	    excelCheckpointRows?: number;
+	    excelResume?: boolean;
+	    excelColumnTypes?: string;
+	    takbisReportSheet?: string;
 	    tabId?: string;
 	    wordReplaceRules?: string;
+	    pdfTextBackend?: string;
+	    parselFields?: ParselFieldRule[];
+	    takbisMapping?: TakbisMapping;
+	    takbisMappingPresets?: {[key: string]: TakbisMapping};
 	
 	    static createFrom(source: any = {}) {
 	        return new Config(source);
@@ -84,20 +195,149 @@ export namespace main {
 	        this.mahalleCellName = source["mahalleCellName"];
 	        this.adaCellName = source["adaCellName"];
 	        this.parselCellName = source["parselCellName"];
-	        this.alanCellName = source["alanCellName"];
-	        this.paftaCellName = source["paftaCellName"];
 	        this.parselSorguHeadless = source["parselSorguHeadless"];
-	        this.ciltCellName = source["ciltCellName"];
-	        this.sayfaCellName = source["sayfaCellName"];
+	        this.parselSorguBackend = source["parselSorguBackend"];
+	        this.parselCacheTTL = source["parselCacheTTL"];
+	        this.parselExportFormats = source["parselExportFormats"];
+	        this.parselExportOnCreate = source["parselExportOnCreate"];
+	        this.parselCRS = source["parselCRS"];
+	        this.parselYuzolcumCellName = source["parselYuzolcumCellName"];
+	        this.parselAreaTolerance = source["parselAreaTolerance"];
+	        this.parselMaxRetries = source["parselMaxRetries"];
+	        this.parselStatusCellName = source["parselStatusCellName"];
+	        this.parselNameMinScore = source["parselNameMinScore"];
+	        this.parselBrowserTabs = source["parselBrowserTabs"];
+	        this.parselConcurrency = source["parselConcurrency"];
+	        this.parselRequestsPerMinute = source["parselRequestsPerMinute"];
+	        this.parselXCellName = source["parselXCellName"];
+	        this.parselYCellName = source["parselYCellName"];
+	        this.parselPointCRS = source["parselPointCRS"];
+	        this.parselNeighborProbe = source["parselNeighborProbe"];
 	        this.tapuNamePattern = source["tapuNamePattern"];
-	        this.mevkiCellName = source["mevkiCellName"];
-	        this.alanCellNameTapu = source["alanCellNameTapu"];
-	        this.excelHeaderMatchPattern = source["excelHeaderMatchPattern"];
-	        this.excelCellModifyPattern = source["excelCellModifyPattern"];
-	        this.mevkiCellNameSorgu = source["mevkiCellNameSorgu"];
-	        this.cinsCellName = source["cinsCellName"];
+	        this.tapuFieldMapping = source["tapuFieldMapping"];
+	        this.tapuMinConfidence = source["tapuMinConfidence"];
+	        this.excelBackup = source["excelBackup"];
+	        this.excelSaveToNewFile = source["excelSaveToNewFile"];
+	        this.excelCheckpointRows = source["excelCheckpointRows"];
+	        this.excelResume = source["excelResume"];
+	        this.excelColumnTypes = source["excelColumnTypes"];
+	        this.takbisReportSheet = source["takbisReportSheet"];
 	        this.tabId = source["tabId"];
 	        this.wordReplaceRules = source["wordReplaceRules"];
+	        this.pdfTextBackend = source["pdfTextBackend"];
+	        this.parselFields = this.convertValues(source["parselFields"], ParselFieldRule);
+	        this.takbisMapping = this.convertValues(source["takbisMapping"], TakbisMapping);
+	        this.takbisMappingPresets = this.convertValues(source["takbisMappingPresets"], TakbisMapping, true);
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class CoordinateQuery {
+	    x: number;
+	    y: number;
+	    crs: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new CoordinateQuery(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.x = source["x"];
+	        this.y = source["y"];
+	        this.crs = source["crs"];
+	    }
+	}
+	export class Geometry {
+	    type: string;
+	    coordinates: number[][][];
+	
+	    static createFrom(source: any = {}) {
+	        return new Geometry(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.type = source["type"];
+	        this.coordinates = source["coordinates"];
+	    }
+	}
+	export class ParselNameIssue {
+	    row: number;
+	    kind: string;
+	    message: string;
+	    candidates: NameCandidate[];
+	
+	    static createFrom(source: any = {}) {
+	        return new ParselNameIssue(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.row = source["row"];
+	        this.kind = source["kind"];
+	        this.message = source["message"];
+	        this.candidates = this.convertValues(source["candidates"], NameCandidate);
+	    }
+	
+	convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class ParselMetrics {
+	    crs: string;
+	    area: number;
+	    perimeter: number;
+	    centroidX: number;
+	    centroidY: number;
+	    centroidLon: number;
+	    centroidLat: number;
+	    vertices: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new ParselMetrics(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.crs = source["crs"];
+	        this.area = source["area"];
+	        this.perimeter = source["perimeter"];
+	        this.centroidX = source["centroidX"];
+	        this.centroidY = source["centroidY"];
+	        this.centroidLon = source["centroidLon"];
+	        this.centroidLat = source["centroidLat"];
+	        this.vertices = source["vertices"];
 	    }
 	}
 	export class Properties {
@@ -128,6 +368,74 @@ export namespace main {
 	        this.Mahalle = source["Mahalle"];
 	    }
 	}
+	export class Feature {
+	    type: string;
+	    geometry: Geometry;
+	    properties: Properties;
+	
+	    static createFrom(source: any = {}) {
+	        return new Feature(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.type = source["type"];
+	        this.geometry = this.convertValues(source["geometry"], Geometry);
+	        this.properties = this.convertValues(source["properties"], Properties);
+	    }
+	
+	convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class FeatureCollection {
+	    features: Feature[];
+	    type: string;
+	    crs: any;
+	
+	    static createFrom(source: any = {}) {
+	        return new FeatureCollection(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.features = this.convertValues(source["features"], Feature);
+	        this.type = source["type"];
+	        this.crs = source["crs"];
+	    }
+	
+	convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
 	export class QueryParams {
 	    province: string;
 	    district: string;
@@ -148,8 +456,168 @@ export namespace main {
 	        this.parcel = source["parcel"];
 	    }
 	}
-	export class Tapu {
+	export class PaftaQueryParams {
+	    province: string;
+	    district: string;
+	    neighborhood: string;
+	    pafta: string;
+	    parcel: string;
+	    block: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new PaftaQueryParams(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.province = source["province"];
+	        this.district = source["district"];
+	        this.neighborhood = source["neighborhood"];
+	        this.pafta = source["pafta"];
+	        this.parcel = source["parcel"];
+	        this.block = source["block"];
+	    }
+	}
+	export class ParselNeighbor {
+	    il: string;
+	    ilce: string;
+	    mahalle: string;
+	    ada: string;
+	    parsel: string;
+	    sharedLength: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new ParselNeighbor(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.il = source["il"];
+	        this.ilce = source["ilce"];
+	        this.mahalle = source["mahalle"];
+	        this.ada = source["ada"];
+	        this.parsel = source["parsel"];
+	        this.sharedLength = source["sharedLength"];
+	    }
+	}
+	export class NameCandidate {
+	    name: string;
+	    score: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new NameCandidate(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.name = source["name"];
+	        this.score = source["score"];
+	    }
+	}
+	export class ParselCacheEntry {
+	    key: string;
+	    params: QueryParams;
+	    fetchedAt: any;
+	    collection: FeatureCollection;
 	
+	    static createFrom(source: any = {}) {
+	        return new ParselCacheEntry(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.key = source["key"];
+	        this.params = this.convertValues(source["params"], QueryParams);
+	        this.fetchedAt = this.convertValues(source["fetchedAt"], null);
+	        this.collection = this.convertValues(source["collection"], FeatureCollection);
+	    }
+	
+	convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class TakbisMappingIssue {
+	    level: string;
+	    message: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new TakbisMappingIssue(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.level = source["level"];
+	        this.message = source["message"];
+	    }
+	}
+	export class TakbisRowResult {
+	    row: number;
+	    key: string;
+	    matched: boolean;
+	    relaxed: boolean;
+	    chosen: string;
+	    candidates: string[];
+	    message: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new TakbisRowResult(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.row = source["row"];
+	        this.key = source["key"];
+	        this.matched = source["matched"];
+	        this.relaxed = source["relaxed"];
+	        this.chosen = source["chosen"];
+	        this.candidates = source["candidates"];
+	        this.message = source["message"];
+	    }
+	}
+	export class TapuMalik {
+	    ad: string;
+	    hisse: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new TapuMalik(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.ad = source["ad"];
+	        this.hisse = source["hisse"];
+	    }
+	}
+	export class Tapu {
+	    il: string;
+	    ilce: string;
+	    mahalle: string;
+	    mevki: string;
+	    pafta: string;
+	    ada: string;
+	    parsel: string;
+	    nitelik: string;
+	    alan: number;
+	    cilt: number;
+	    sayfa: number;
+	    edinmeSebebi: string;
+	    tarih: string;
+	    yevmiye: string;
+	    malikler: TapuMalik[];
 	
 	    static createFrom(source: any = {}) {
 	        return new Tapu(source);
@@ -157,8 +625,128 @@ export namespace main {
 	
 	    constructor(source: any = {}) {
 	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.il = source["il"];
+	        this.ilce = source["ilce"];
+	        this.mahalle = source["mahalle"];
+	        this.mevki = source["mevki"];
+	        this.pafta = source["pafta"];
+	        this.ada = source["ada"];
+	        this.parsel = source["parsel"];
+	        this.nitelik = source["nitelik"];
+	        this.alan = source["alan"];
+	        this.cilt = source["cilt"];
+	        this.sayfa = source["sayfa"];
+	        this.edinmeSebebi = source["edinmeSebebi"];
+	        this.tarih = source["tarih"];
+	        this.yevmiye = source["yevmiye"];
+	        this.malikler = this.convertValues(source["malikler"], TapuMalik);
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class TapuConflict {
+	    field: string;
+	    values: string[];
 	
+	    static createFrom(source: any = {}) {
+	        return new TapuConflict(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.field = source["field"];
+	        this.values = source["values"];
+	    }
+	}
+	export class TapuMatch {
+	    path: string;
+	    tapu: Tapu;
+	    confidence: number;
+	    error: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new TapuMatch(source);
 	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.path = source["path"];
+	        this.tapu = this.convertValues(source["tapu"], Tapu);
+	        this.confidence = source["confidence"];
+	        this.error = source["error"];
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class TapuRowResult {
+	    row: number;
+	    matches: TapuMatch[];
+	    best: number;
+	    conflicts: TapuConflict[];
+	    message: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new TapuRowResult(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.row = source["row"];
+	        this.matches = this.convertValues(source["matches"], TapuMatch);
+	        this.best = source["best"];
+	        this.conflicts = this.convertValues(source["conflicts"], TapuConflict);
+	        this.message = source["message"];
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
 	}
 	export class UpdateInfo {
 	    updateAvailable: boolean;
@@ -0,0 +1,251 @@
+package main
+
+import (
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"golang.org/x/text/cases"
+	"golang.org/x/text/language"
+	"golang.org/x/text/unicode/norm"
+)
+
+var turkishLower = cases.Lower(language.Turkish)
+
+// foldGlobName folds a file name or pattern segment for comparison: Unicode NFC
+// (macOS stores names decomposed), Turkish lower case and i/ı treated as one letter
+func foldGlobName(name string) string {
+	name = norm.NFC.String(name)
+	name = turkishLower.String(name)
+	return strings.ReplaceAll(name, "ı", "i")
+}
+
+func hasGlobMeta(segment string) bool {
+	return strings.ContainsAny(segment, "*?[{")
+}
+
+// globEscaper makes the glob characters of a value match literally, each as a one character class
+var globEscaper = strings.NewReplacer(
+	"*", "[*]",
+	"?", "[?]",
+	"[", "[[]",
+	"{", "[{]",
+	"}", "[}]",
+	",", "[,]",
+)
+
+// globPatternName fills the placeholders of a glob pattern like generatePatternName does, with the
+// cell values escaped so only the pattern itself can have wildcards and {a,b} alternatives
+func globPatternName(pattern string, headers []string, row []string) string {
+	escaped := make([]string, len(row))
+	for i, cell := range row {
+		escaped[i] = globEscaper.Replace(sanitizeCellFolder(cell))
+	}
+	return generatePatternName(pattern, headers, escaped)
+}
+
+// globClassEnd returns the index of the "]" closing the character class opened at start, or start
+// if the class isn't closed
+func globClassEnd(pattern string, start int) int {
+	for i := start + 1; i < len(pattern); i++ {
+		switch pattern[i] {
+		case '\\':
+			i++
+		case ']':
+			return i
+		}
+	}
+	return start
+}
+
+// expandBraces expands {a,b} alternatives, including nested ones, into separate patterns.
+// Braces without a comma, like a placeholder no column filled, and unbalanced braces are kept
+// literally. Braces and commas inside a character class are literal too.
+func expandBraces(pattern string) []string {
+	start := -1
+	for i := 0; i < len(pattern) && start == -1; i++ {
+		switch pattern[i] {
+		case '[':
+			i = globClassEnd(pattern, i)
+		case '{':
+			start = i
+		}
+	}
+	if start == -1 {
+		return []string{pattern}
+	}
+
+	depth := 0
+	var commas []int
+	end := -1
+
+	for i := start; i < len(pattern) && end == -1; i++ {
+		switch pattern[i] {
+		case '[':
+			i = globClassEnd(pattern, i)
+		case '{':
+			depth++
+		case '}':
+			depth--
+			if depth == 0 {
+				end = i
+			}
+		case ',':
+			if depth == 1 {
+				commas = append(commas, i)
+			}
+		}
+	}
+
+	if end == -1 {
+		return []string{pattern}
+	}
+
+	prefix := pattern[:start]
+	var results []string
+
+	if len(commas) == 0 {
+		for _, rest := range expandBraces(pattern[start+1:]) {
+			results = append(results, prefix+"{"+rest)
+		}
+		return results
+	}
+
+	// Expand the suffix first so each alternative is only joined once
+	suffixes := expandBraces(pattern[end+1:])
+
+	bounds := append([]int{start}, commas...)
+	bounds = append(bounds, end)
+
+	for i := 0; i < len(bounds)-1; i++ {
+		for _, alternative := range expandBraces(pattern[bounds[i]+1 : bounds[i+1]]) {
+			for _, suffix := range suffixes {
+				results = append(results, prefix+alternative+suffix)
+			}
+		}
+	}
+
+	return results
+}
+
+// matchGlobSegment reports whether name matches a single path segment pattern,
+// ignoring case the way Turkish does
+func matchGlobSegment(pattern string, name string) (bool, error) {
+	foldedName := foldGlobName(name)
+
+	for _, alternative := range expandBraces(pattern) {
+		match, err := path.Match(foldGlobName(alternative), foldedName)
+		if err != nil {
+			return false, err
+		}
+		if match {
+			return true, nil
+		}
+	}
+
+	return false, nil
+}
+
+// FilterDirs returns the paths under root matching pattern. Pattern segments are separated by
+// "/" (or "\" on Windows) and support *, ?, [...], {a,b} and ** for any number of directories.
+// Matching is case insensitive and treats İ/i/I/ı alike. root itself is used as is and must exist.
+func FilterDirs(root string, pattern string) ([]string, error) {
+	if _, err := os.Stat(root); err != nil {
+		return nil, err
+	}
+
+	var segments []string
+	for _, segment := range strings.Split(filepath.ToSlash(pattern), "/") {
+		if segment != "" && segment != "." {
+			segments = append(segments, segment)
+		}
+	}
+
+	seen := make(map[string]bool)
+	var matches []string
+
+	err := globWalk(root, segments, func(match string) {
+		if !seen[match] {
+			seen[match] = true
+			matches = append(matches, match)
+		}
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	sort.Strings(matches)
+
+	return matches, nil
+}
+
+func globWalk(dir string, segments []string, found func(string)) error {
+	if len(segments) == 0 {
+		found(dir)
+		return nil
+	}
+
+	segment := segments[0]
+	rest := segments[1:]
+
+	if segment == "**" {
+		// Zero directories
+		if err := globWalk(dir, rest, found); err != nil {
+			return err
+		}
+
+		entries, err := os.ReadDir(dir)
+		if err != nil {
+			return nil
+		}
+
+		for _, entry := range entries {
+			if entry.IsDir() {
+				if err := globWalk(filepath.Join(dir, entry.Name()), segments, found); err != nil {
+					return err
+				}
+			} else if len(rest) == 0 {
+				// A trailing ** matches files too
+				found(filepath.Join(dir, entry.Name()))
+			}
+		}
+
+		return nil
+	}
+
+	// Fast path for names that exist exactly as written
+	if !hasGlobMeta(segment) {
+		candidate := filepath.Join(dir, segment)
+		if info, err := os.Stat(candidate); err == nil && (len(rest) == 0 || info.IsDir()) {
+			return globWalk(candidate, rest, found)
+		}
+	}
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		// Missing intermediate directories just don't match
+		return nil
+	}
+
+	for _, entry := range entries {
+		if len(rest) > 0 && !entry.IsDir() {
+			continue
+		}
+
+		match, err := matchGlobSegment(segment, entry.Name())
+		if err != nil {
+			return err
+		}
+
+		if match {
+			if err := globWalk(filepath.Join(dir, entry.Name()), rest, found); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
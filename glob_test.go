@@ -0,0 +1,71 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestExpandBraces(t *testing.T) {
+	tests := []struct {
+		pattern string
+		want    []string
+	}{
+		{"evrak_*.pdf", []string{"evrak_*.pdf"}},
+		{"{Tapu,Takbis}/*.pdf", []string{"Tapu/*.pdf", "Takbis/*.pdf"}},
+		{"{a,b{c,d}}x", []string{"ax", "bcx", "bdx"}},
+		// A placeholder no column filled stays as written
+		{"{Dosya No}_{Tapu,Takbis}", []string{"{Dosya No}_Tapu", "{Dosya No}_Takbis"}},
+		{"{a{b,c}}", []string{"{ab}", "{ac}"}},
+		// Escaped cell values aren't alternatives
+		{"Ada[{]1[,]2[}]_{x,y}", []string{"Ada[{]1[,]2[}]_x", "Ada[{]1[,]2[}]_y"}},
+		{"{a,b", []string{"{a,b"}},
+	}
+
+	for _, test := range tests {
+		if got := expandBraces(test.pattern); !reflect.DeepEqual(got, test.want) {
+			t.Errorf("expandBraces(%q) = %q, want %q", test.pattern, got, test.want)
+		}
+	}
+}
+
+func TestFilterDirsWithCellValues(t *testing.T) {
+	root := t.TempDir()
+	for _, dir := range []string{"1_Merkez,Yeni_5", "1_Merkez_5", "1_Yeni_5", "2_Çarşı*_7", "2_Çarşıbaşı_7"} {
+		if err := os.MkdirAll(filepath.Join(root, dir, "Tapu"), 0o755); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	headers := []string{"Dosya No", "Mahalle", "Ada"}
+	pattern := "{Dosya No}_{Mahalle}_{Ada}/Tapu"
+
+	tests := []struct {
+		row  []string
+		want []string
+	}{
+		// The comma of the value doesn't split it into Merkez and Yeni
+		{[]string{"1", "Merkez,Yeni", "5"}, []string{"1_Merkez,Yeni_5"}},
+		// Nor does the star match Çarşıbaşı
+		{[]string{"2", "Çarşı*", "7"}, []string{"2_Çarşı*_7"}},
+		// Nothing fills {Ada}, so nothing matches
+		{[]string{"1", "Merkez"}, nil},
+	}
+
+	for _, test := range tests {
+		matches, err := FilterDirs(root, globPatternName(pattern, headers, test.row))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		var got []string
+		for _, match := range matches {
+			rel, _ := filepath.Rel(root, filepath.Dir(match))
+			got = append(got, rel)
+		}
+		if !reflect.DeepEqual(got, test.want) {
+			t.Errorf("row %q matched %q, want %q", test.row, got, test.want)
+		}
+	}
+}
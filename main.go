@@ -11,6 +11,7 @@ import (
 	"github.com/wailsapp/wails/v2/pkg/logger"
 	"github.com/wailsapp/wails/v2/pkg/options"
 	"github.com/wailsapp/wails/v2/pkg/options/assetserver"
+	"github.com/wailsapp/wails/v2/pkg/options/linux"
 	"github.com/wailsapp/wails/v2/pkg/options/windows"
 )
 
@@ -122,6 +123,11 @@ func main() {
 			ZoomFactor:          1.0,
 			DisablePinchZoom:    true,
 		},
+		// Linux platform specific options
+		Linux: &linux.Options{
+			Icon:        appIcon,
+			ProgramName: "folder-creator",
+		},
 	})
 
 	if err != nil {
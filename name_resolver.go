@@ -0,0 +1,229 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// NameCandidate is an option scored against a searched name
+type NameCandidate struct {
+	Name  string  `json:"name"`
+	Score float64 `json:"score"` // 0-1
+}
+
+// Scores at or above exactNameScore only differ in case, diacritics or suffixes
+const (
+	exactNameScore     = 0.95
+	ambiguousNameDelta = 0.05
+	maxNameCandidates  = 5
+)
+
+var adminNameFolder = strings.NewReplacer(
+	"ç", "c", "ğ", "g", "ı", "i", "ö", "o", "ş", "s", "ü", "u",
+	"â", "a", "î", "i", "û", "u",
+)
+
+// Suffixes that are often added or left out of administrative names, in folded form, by the
+// kind of unit they denote
+var adminNameSuffixes = map[string]string{
+	"mahallesi": "mahalle", "mahalle": "mahalle", "mah": "mahalle", "mh": "mahalle", "m": "mahalle",
+	"koyu": "koy", "koy": "koy", "k": "koy",
+	"beldesi": "belde", "belde": "belde",
+	"ilcesi": "ilce",
+	"ili":    "il",
+}
+
+// foldAdminName lower cases name the Turkish way and removes diacritics and punctuation
+func foldAdminName(name string) string {
+	name = adminNameFolder.Replace(foldGlobName(name))
+
+	var b strings.Builder
+	for _, r := range name {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			b.WriteRune(r)
+		} else {
+			b.WriteRune(' ')
+		}
+	}
+
+	return strings.Join(strings.Fields(b.String()), " ")
+}
+
+// normalizeAdminName folds name and strips trailing suffixes like "Mahallesi", "Köyü" or "(K)",
+// returning the kind of unit the first stripped suffix denotes
+func normalizeAdminName(name string) (string, string) {
+	words := strings.Fields(foldAdminName(name))
+	kind := ""
+
+	for len(words) > 1 {
+		suffixKind, ok := adminNameSuffixes[words[len(words)-1]]
+		if !ok {
+			break
+		}
+
+		words = words[:len(words)-1]
+		if kind == "" {
+			kind = suffixKind
+		}
+	}
+
+	return strings.Join(words, " "), kind
+}
+
+// scoreAdminName rates how well option matches name, 1 for identical names
+func scoreAdminName(name, option string) float64 {
+	if strings.TrimSpace(name) == strings.TrimSpace(option) {
+		return 1
+	}
+
+	foldedName, foldedOption := foldAdminName(name), foldAdminName(option)
+	if foldedName == foldedOption {
+		return 0.99
+	}
+
+	normalizedName, nameKind := normalizeAdminName(name)
+	normalizedOption, optionKind := normalizeAdminName(option)
+	if normalizedName == normalizedOption {
+		// "Yeni Mah." prefers "Yeni Mahallesi" over "Yeni Köyü"
+		if nameKind != "" && nameKind == optionKind {
+			return 0.97
+		}
+		return exactNameScore
+	}
+
+	longest := max(len([]rune(normalizedName)), len([]rune(normalizedOption)))
+	if longest == 0 {
+		return 0
+	}
+
+	// Below exactNameScore so typos never beat a real match
+	similarity := 1 - float64(levenshtein(normalizedName, normalizedOption))/float64(longest)
+	return similarity * 0.9
+}
+
+// levenshtein is the edit distance of a and b in runes
+func levenshtein(a, b string) int {
+	ra, rb := []rune(a), []rune(b)
+
+	previous := make([]int, len(rb)+1)
+	current := make([]int, len(rb)+1)
+
+	for j := range previous {
+		previous[j] = j
+	}
+
+	for i := 1; i <= len(ra); i++ {
+		current[0] = i
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			current[j] = min(previous[j]+1, current[j-1]+1, previous[j-1]+cost)
+		}
+		previous, current = current, previous
+	}
+
+	return previous[len(rb)]
+}
+
+// resolveAdminName picks the option matching name. A single exact match (ignoring case, diacritics
+// and suffixes) wins, otherwise the best fuzzy match if it reaches minScore and clearly beats the
+// runner-up. kind names the unit in errors, which list the closest candidates.
+func resolveAdminName(kind string, name string, options []string, minScore float64) (int, error) {
+	type scored struct {
+		index int
+		NameCandidate
+	}
+
+	candidates := make([]scored, 0, len(options))
+	for i, option := range options {
+		candidates = append(candidates, scored{i, NameCandidate{Name: option, Score: scoreAdminName(name, option)}})
+	}
+
+	sort.SliceStable(candidates, func(i, j int) bool {
+		return candidates[i].Score > candidates[j].Score
+	})
+
+	closest := func(minimum float64) []NameCandidate {
+		var result []NameCandidate
+		for _, candidate := range candidates {
+			if len(result) == maxNameCandidates || candidate.Score < minimum {
+				break
+			}
+			result = append(result, candidate.NameCandidate)
+		}
+		return result
+	}
+
+	if len(candidates) == 0 || candidates[0].Score < minScore {
+		err := newParselError(ParselErrorNotFound, nil, "%s %s not found among %d options", kind, name, len(options))
+		err.Candidates = closest(0)
+		if len(err.Candidates) > 0 {
+			err.Message += ", closest " + candidateNames(err.Candidates)
+		}
+		return -1, err
+	}
+
+	best := candidates[0]
+
+	if len(candidates) > 1 {
+		second := candidates[1]
+
+		// Two options that only differ in suffix, like "Yeni Mahallesi" and "Yeni Köyü", or two
+		// fuzzy matches too close to tell apart
+		if (best.Score >= exactNameScore && second.Score >= exactNameScore && best.Score == second.Score) ||
+			(best.Score < exactNameScore && best.Score-second.Score < ambiguousNameDelta) {
+			minimum := best.Score - ambiguousNameDelta
+			if best.Score >= exactNameScore {
+				minimum = exactNameScore
+			}
+
+			err := newParselError(ParselErrorAmbiguous, nil, "%s %s matches %s", kind, name, candidateNames(closest(minimum)))
+			err.Candidates = closest(minimum)
+			return -1, err
+		}
+	}
+
+	return best.index, nil
+}
+
+func candidateNames(candidates []NameCandidate) string {
+	names := make([]string, len(candidates))
+	for i, candidate := range candidates {
+		names[i] = fmt.Sprintf("%s (%%%.0f)", candidate.Name, candidate.Score*100)
+	}
+	return strings.Join(names, ", ")
+}
+
+// ParselNameIssue is a row whose il/ilçe/mahalle couldn't be resolved to a single option
+type ParselNameIssue struct {
+	Row        int             `json:"row"` // 1-based row number in the Excel
+	Kind       ParselErrorKind `json:"kind"`
+	Message    string          `json:"message"`
+	Candidates []NameCandidate `json:"candidates"`
+}
+
+var lastParselNameReport []ParselNameIssue
+
+func (app *App) GetParselNameReport() []ParselNameIssue {
+	return lastParselNameReport
+}
+
+// parselNameIssue returns the issue err describes, false if err isn't about a name
+func parselNameIssue(row int, err error) (ParselNameIssue, bool) {
+	var parselErr *ParselError
+	if !errors.As(err, &parselErr) || parselErr.Candidates == nil {
+		return ParselNameIssue{}, false
+	}
+
+	return ParselNameIssue{Row: row, Kind: parselErr.Kind, Message: parselErr.Message, Candidates: parselErr.Candidates}, true
+}
+
+// parselNameMinScore is the configured minimum score for fuzzy name matches
+func parselNameMinScore() float64 {
+	return float64(*config.ParselNameMinScore) / 100
+}
@@ -0,0 +1,87 @@
+package main
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// The default of ParselNameMinScore
+const testNameMinScore = 0.8
+
+func TestScoreAdminName(t *testing.T) {
+	tests := []struct {
+		name, option string
+		want         float64
+	}{
+		{"Meram", "Meram", 1},
+		{"İNCESU", "İncesu", 0.99},
+		{"KADIKÖY", "Kadıköy", 0.99},
+		{"ISIKLAR MAH.", "Işıklar Mahallesi", 0.97},
+		{"Yeni Mah.", "Yeni Köyü", exactNameScore},
+		{"Yeni", "Yeni Mahallesi", exactNameScore},
+	}
+
+	for _, tt := range tests {
+		if got := scoreAdminName(tt.name, tt.option); got != tt.want {
+			t.Errorf("scoreAdminName(%q, %q) = %v, want %v", tt.name, tt.option, got, tt.want)
+		}
+	}
+
+	// Leaving a word out is not a suffix, it only scores as a fuzzy match
+	if got := scoreAdminName("Meram", "Meram Yeni"); got >= testNameMinScore {
+		t.Errorf("Meram scores %v against Meram Yeni", got)
+	}
+}
+
+func TestResolveAdminName(t *testing.T) {
+	tests := []struct {
+		name    string
+		options []string
+		want    int
+		kind    ParselErrorKind
+		closest []string
+	}{
+		{name: "Yeni Mah.", options: []string{"Yeni Köyü", "Yeni Mahallesi"}, want: 1},
+		{name: "yeni köy", options: []string{"Yeni Mahallesi", "Yeni Köyü"}, want: 1},
+		{name: "INCESU", options: []string{"Karatay", "İncesu"}, want: 1},
+		{name: "Yeni", options: []string{"Yeni Mahallesi", "Yeni Köyü"}, want: -1,
+			kind: ParselErrorAmbiguous, closest: []string{"Yeni Mahallesi", "Yeni Köyü"}},
+		{name: "Meram", options: []string{"Meram Yeni", "Karatay"}, want: -1,
+			kind: ParselErrorNotFound, closest: []string{"Meram Yeni", "Karatay"}},
+	}
+
+	for _, tt := range tests {
+		got, err := resolveAdminName("mahalle", tt.name, tt.options, testNameMinScore)
+		if got != tt.want {
+			t.Errorf("%s: index = %d, want %d (%v)", tt.name, got, tt.want, err)
+			continue
+		}
+		if tt.kind == "" {
+			if err != nil {
+				t.Errorf("%s: %v", tt.name, err)
+			}
+			continue
+		}
+
+		var parselErr *ParselError
+		if !errors.As(err, &parselErr) || parselErr.Kind != tt.kind {
+			t.Errorf("%s: err = %v, want %s", tt.name, err, tt.kind)
+			continue
+		}
+
+		var names []string
+		for _, candidate := range parselErr.Candidates {
+			names = append(names, candidate.Name)
+		}
+		if !reflect.DeepEqual(names, tt.closest) {
+			t.Errorf("%s: candidates = %v, want %v", tt.name, names, tt.closest)
+		}
+		for _, name := range tt.closest {
+			if !strings.Contains(parselErr.Message, name) {
+				t.Errorf("%s: message %q doesn't list %s", tt.name, parselErr.Message, name)
+			}
+		}
+	}
+}
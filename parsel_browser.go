@@ -0,0 +1,333 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"github.com/chromedp/chromedp"
+	"github.com/wailsapp/wails/v2/pkg/runtime"
+)
+
+// browserParselBackend drives parselsorgu.tkgm.gov.tr in Chrome and downloads the GeoJSON export.
+// The browser is shared by every backend and survives between runs, a crashed one is restarted
+// by the next query.
+type browserParselBackend struct {
+	headless bool
+	tabs     int
+	timeout  time.Duration
+}
+
+func newBrowserParselBackend(headless bool) (*browserParselBackend, error) {
+	tabs := *config.ParselBrowserTabs
+
+	// Start the browser now so a missing Chrome is reported before the first row
+	if _, err := getBrowserSession(headless, tabs); err != nil {
+		return nil, err
+	}
+
+	// A visible browser leaves time to solve captchas by hand
+	timeout := 2400 * time.Second
+	if headless {
+		timeout = 120 * time.Second
+	}
+
+	return &browserParselBackend{headless: headless, tabs: tabs, timeout: timeout}, nil
+}
+
+func (b *browserParselBackend) Query(ctx context.Context, params QueryParams) (FeatureCollection, error) {
+	if params.Province == "" || params.District == "" || params.Neighborhood == "" || params.Block == "" || params.Parcel == "" {
+		return FeatureCollection{}, newParselError(ParselErrorInvalid, nil, "province, district, neighborhood, block and parcel are required")
+	}
+
+	session, err := getBrowserSession(b.headless, b.tabs)
+	if err != nil {
+		return FeatureCollection{}, newParselError(ParselErrorUnavailable, err, "failed to start browser")
+	}
+
+	tab, err := session.acquire(ctx)
+	if err != nil {
+		return FeatureCollection{}, classifyParselError(err)
+	}
+
+	featureCollection, err := b.queryTab(ctx, tab, params)
+
+	// The form keeps the last query, so the tab starts from a fresh page next time
+	session.release(tab, true)
+
+	return featureCollection, err
+}
+
+// queryTab runs the query on tab, stopping early if ctx is done, which for now only happens when
+// the app shuts down
+func (b *browserParselBackend) queryTab(callerCtx context.Context, tab *browserTab, params QueryParams) (FeatureCollection, error) {
+	// Clear the download directory
+	err := os.RemoveAll(tab.downloadDir)
+	if err != nil {
+		return FeatureCollection{}, err
+	}
+	err = create_folder(tab.downloadDir)
+	if err != nil {
+		return FeatureCollection{}, err
+	}
+
+	// chromedp needs a context derived from the tab, the caller's context only stops it
+	ctx, cancel := context.WithTimeout(tab.ctx, b.timeout)
+	defer cancel()
+	stop := context.AfterFunc(callerCtx, cancel)
+	defer stop()
+
+	err = chromedp.Run(ctx,
+		// wait while loading
+		chromedp.WaitNotPresent(`.nprogress-busy`, chromedp.BySearch),
+
+		// Select province
+		b.selectOption("province-select", "province", params.Province),
+
+		// wait while loading
+		chromedp.WaitNotPresent(`.nprogress-busy`, chromedp.BySearch),
+
+		// Select district
+		b.selectOption("district-select", "district", params.District),
+
+		// wait while loading
+		chromedp.WaitNotPresent(`.nprogress-busy`, chromedp.BySearch),
+
+		// Select neighborhood
+		b.selectOption("neighborhood-select", "neighborhood", params.Neighborhood),
+
+		// wait while loading
+		chromedp.WaitNotPresent(`.nprogress-busy`, chromedp.BySearch),
+
+		// Wait for other elements to be ready and interact with them as needed
+		chromedp.WaitReady(`#block-input`, chromedp.ByID),
+		chromedp.SendKeys(`#block-input`, params.Block),
+
+		chromedp.WaitReady(`#parcel-input`, chromedp.ByID),
+		chromedp.SendKeys(`#parcel-input`, params.Parcel),
+
+		chromedp.Sleep(100*time.Millisecond),
+
+		chromedp.WaitReady(`#administrative-query-btn`, chromedp.ByID),
+		chromedp.Click(`#administrative-query-btn`, chromedp.ByID),
+
+		// wait while loading
+		chromedp.WaitNotPresent(`.nprogress-busy`, chromedp.BySearch),
+
+		chromedp.Sleep(5*time.Second),
+
+		// stop runs if .close-btn exists
+		chromedp.ActionFunc(func(ctx context.Context) error {
+			runtime.LogInfo(appContext, "Checking for close button")
+			var exists bool
+			err := chromedp.Run(ctx, chromedp.Evaluate(`document.querySelector("#close-btn") !== null`, &exists))
+			if err != nil {
+				return fmt.Errorf("failed to check for close button: %w", err)
+			}
+			if exists {
+				runtime.LogInfo(appContext, "Close button found, clicking it and stopping execution")
+				err = chromedp.Run(ctx, chromedp.Click("#close-btn", chromedp.ByID))
+				if err != nil {
+					return fmt.Errorf("failed to click close button: %w", err)
+				}
+				// The site shows its dialog instead of the parcel when nothing was found
+				return newParselError(ParselErrorNotFound, nil, "parcel %s/%s", params.Block, params.Parcel)
+			} else {
+				runtime.LogInfo(appContext, "Close button not found, continuing execution")
+			}
+			return nil
+		}),
+
+		chromedp.ActionFunc(func(ctx context.Context) error {
+			// Evaluate the XPath to find the last path element
+			var pathCount int
+			err := chromedp.Run(ctx, chromedp.Evaluate(`document.querySelectorAll("#map-canvas > div.leaflet-pane.leaflet-map-pane > div.leaflet-pane.leaflet-overlay-pane > svg > g > path").length`, &pathCount))
+			if err != nil {
+				return fmt.Errorf("failed to count paths: %w", err)
+			}
+
+			// Select the last path element
+			pathSelector := fmt.Sprintf(`#map-canvas > div.leaflet-pane.leaflet-map-pane > div.leaflet-pane.leaflet-overlay-pane > svg > g > path:nth-child(%d)`, pathCount)
+			err = chromedp.Run(ctx, chromedp.Click(pathSelector))
+			if err != nil {
+				return fmt.Errorf("failed to click on last path element: %w", err)
+			}
+
+			return nil
+		}),
+
+		// wait while loading
+		chromedp.WaitNotPresent(`.nprogress-busy`, chromedp.BySearch),
+
+		chromedp.WaitReady(".dropdown-toggle", chromedp.BySearch),
+		chromedp.Click(".dropdown-toggle", chromedp.BySearch),
+
+		// wait while loading
+		chromedp.WaitNotPresent(`.nprogress-busy`, chromedp.BySearch),
+
+		chromedp.WaitReady(`/html/body/div[3]/div[3]/div/div/div[1]/div/ul/li[11]/a`, chromedp.BySearch),
+		chromedp.Click(`/html/body/div[3]/div[3]/div/div/div[1]/div/ul/li[11]/a`, chromedp.BySearch),
+
+		// wait while loading
+		chromedp.WaitNotPresent(`.nprogress-busy`, chromedp.BySearch),
+
+		chromedp.WaitReady(`/html/body/div[3]/div[3]/div/div/div[2]/div[1]/div/div[3]/table/tbody/tr/td[3]/input`, chromedp.BySearch),
+		chromedp.Click(`/html/body/div[3]/div[3]/div/div/div[2]/div[1]/div/div[3]/table/tbody/tr/td[3]/input`, chromedp.BySearch),
+
+		// Download button
+		chromedp.WaitReady(`#export-data`, chromedp.ByID),
+		chromedp.Click(`#export-data`, chromedp.ByID),
+
+		// wait while loading
+		chromedp.Sleep(3*time.Second),
+
+		// Close button
+		chromedp.WaitReady(`#close-btn`, chromedp.ByID),
+		chromedp.Click(`#close-btn`, chromedp.ByID),
+
+		// wait while loading
+		chromedp.Sleep(2*time.Second),
+	)
+
+	if err != nil {
+		runtime.LogError(appContext, err.Error())
+		return FeatureCollection{}, classifyParselError(err)
+	}
+
+	// Select the only file in downloads folder
+	files, err := os.ReadDir(tab.downloadDir)
+	if err != nil {
+		runtime.LogError(appContext, err.Error())
+		return FeatureCollection{}, err
+	}
+
+	downloadDeadline := time.Now().Add(30 * time.Second)
+
+	for {
+		if len(files) == 0 {
+			if err := callerCtx.Err(); err != nil {
+				return FeatureCollection{}, newParselError(ParselErrorTimeout, err, "cancelled while waiting for the download")
+			}
+			if time.Now().After(downloadDeadline) {
+				return FeatureCollection{}, newParselError(ParselErrorTimeout, nil, "download didn't finish")
+			}
+
+			runtime.LogWarning(appContext, "Downloaded file not found")
+			time.Sleep(200 * time.Millisecond)
+			// Select the only file in downloads folder
+			files, err = os.ReadDir(tab.downloadDir)
+			if err != nil {
+				runtime.LogError(appContext, err.Error())
+				return FeatureCollection{}, err
+			}
+		} else {
+			break
+		}
+	}
+
+	filePath := filepath.Join(tab.downloadDir, files[0].Name())
+
+	if len(files) != 1 {
+		runtime.LogError(appContext, "More than one downloaded file found")
+
+		// Use the last added file
+		// Sort files by added date
+		sort.Slice(files, func(i, j int) bool {
+			infoI, err := os.Stat(path.Join(tab.downloadDir, files[i].Name()))
+			if err != nil {
+				return false
+			}
+
+			infoJ, err := os.Stat(path.Join(tab.downloadDir, files[j].Name()))
+			if err != nil {
+				return false
+			}
+
+			return infoI.ModTime().After(infoJ.ModTime())
+		})
+
+		runtime.LogInfo(appContext, "Last added file: "+files[0].Name())
+		filePath = filepath.Join(tab.downloadDir, files[0].Name())
+	}
+
+	runtime.LogInfo(appContext, "Processing file: "+filePath)
+
+	// Read file content
+	fileContent, err := os.ReadFile(filePath)
+	if err != nil {
+		return FeatureCollection{}, fmt.Errorf("failed to read downloaded file: %w", err)
+	}
+
+	time.Sleep(2 * time.Second)
+
+	// Delete the downloaded file
+	err = os.Remove(filePath)
+	if err != nil {
+		return FeatureCollection{}, fmt.Errorf("failed to delete downloaded file: %w", err)
+	}
+
+	// Unmarshal JSON content into FeatureCollection struct
+	var featureCollection FeatureCollection
+	if err := json.Unmarshal(fileContent, &featureCollection); err != nil {
+		return FeatureCollection{}, newParselError(ParselErrorParse, err, "failed to unmarshal downloaded file")
+	}
+
+	time.Sleep(2 * time.Second)
+
+	return featureCollection, nil
+}
+
+// selectOption picks the option of the <select> with the given id whose text best matches name
+func (b *browserParselBackend) selectOption(id string, kind string, name string) chromedp.Action {
+	return chromedp.ActionFunc(func(ctx context.Context) error {
+		runtime.LogInfo(appContext, "Waiting for "+kind+" selection")
+
+		if err := chromedp.Run(ctx, chromedp.WaitVisible("#"+id, chromedp.ByID)); err != nil {
+			return err
+		}
+
+		var options []struct {
+			Value string `json:"value"`
+			Text  string `json:"text"`
+		}
+		err := chromedp.Run(ctx, chromedp.Evaluate(`Array.from(document.querySelectorAll("#`+id+` option")).map(o => ({value: o.value, text: o.textContent.trim()}))`, &options))
+		if err != nil {
+			return fmt.Errorf("failed to list %s options: %w", kind, err)
+		}
+
+		// Skip the placeholder option
+		names := make([]string, 0, len(options))
+		values := make([]string, 0, len(options))
+		for _, option := range options {
+			if option.Value == "" || option.Value == "-1" {
+				continue
+			}
+			names = append(names, option.Text)
+			values = append(values, option.Value)
+		}
+
+		index, err := resolveAdminName(kind, name, names, parselNameMinScore())
+		if err != nil {
+			return err
+		}
+
+		runtime.LogInfo(appContext, "Option value: "+values[index]+" ("+names[index]+")")
+
+		err = chromedp.Run(ctx, chromedp.SetValue("#"+id, values[index], chromedp.ByID))
+		if err != nil {
+			return fmt.Errorf("failed to set %s value for %s: %w", kind, name, err)
+		}
+
+		return nil
+	})
+}
+
+// Close leaves the shared browser session open for the next run
+func (b *browserParselBackend) Close() error {
+	return nil
+}
@@ -0,0 +1,315 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"os"
+	"path"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/wailsapp/wails/v2/pkg/runtime"
+)
+
+// ParselCacheEntry is a stored parcel query result
+type ParselCacheEntry struct {
+	Key        string            `json:"key"`
+	Params     QueryParams       `json:"params"`
+	FetchedAt  time.Time         `json:"fetchedAt"`
+	Collection FeatureCollection `json:"collection"`
+}
+
+// parselCache keeps query results on disk so parcels aren't queried again within the TTL. The file
+// has one JSON entry per line, Put appends a line so a run doesn't rewrite the whole file for every
+// parcel and load keeps the last line of each key, compacting the file if it had replaced lines.
+type parselCache struct {
+	mu      sync.Mutex
+	path    string
+	loaded  bool
+	entries map[string]ParselCacheEntry
+}
+
+var parselCacheStore = &parselCache{}
+
+func getParselCache() *parselCache {
+	parselCacheStore.mu.Lock()
+	if parselCacheStore.path == "" {
+		parselCacheStore.path = path.Join(appFolder, "parsel_cache.json")
+	}
+	parselCacheStore.mu.Unlock()
+
+	return parselCacheStore
+}
+
+// parselCacheKey normalizes the query so spelling and case differences hit the same entry
+func parselCacheKey(params QueryParams) string {
+	normalizeName := func(s string) string {
+		return strings.Join(strings.Fields(foldGlobName(s)), " ")
+	}
+	normalizeNumber := func(s string) string {
+		s = strings.TrimLeft(strings.TrimSpace(s), "0")
+		if s == "" {
+			return "0"
+		}
+		return s
+	}
+
+	return strings.Join([]string{
+		normalizeName(params.Province),
+		normalizeName(params.District),
+		normalizeName(params.Neighborhood),
+		normalizeNumber(params.Block),
+		normalizeNumber(params.Parcel),
+	}, "|")
+}
+
+func parselCacheTTL() time.Duration {
+	return time.Duration(*config.ParselCacheTTL) * 24 * time.Hour
+}
+
+// load reads the cache file once, the caller must hold mu
+func (c *parselCache) load() error {
+	if c.loaded {
+		return nil
+	}
+
+	c.entries = make(map[string]ParselCacheEntry)
+
+	data, err := os.ReadFile(c.path)
+	if errors.Is(err, os.ErrNotExist) {
+		c.loaded = true
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	var entries []ParselCacheEntry
+	compact := false
+
+	for _, line := range bytes.Split(data, []byte("\n")) {
+		if len(bytes.TrimSpace(line)) == 0 {
+			continue
+		}
+
+		var entry ParselCacheEntry
+		if err := json.Unmarshal(line, &entry); err != nil {
+			// An append cut short by a crash leaves a partial line, the entry is just queried again
+			compact = true
+			continue
+		}
+		entries = append(entries, entry)
+	}
+
+	for _, entry := range entries {
+		if _, ok := c.entries[entry.Key]; ok {
+			compact = true
+		}
+		c.entries[entry.Key] = entry
+	}
+
+	c.loaded = true
+
+	if compact {
+		return c.save()
+	}
+	return nil
+}
+
+// save writes every entry to a temporary file and renames it over the old one, the caller must hold mu
+func (c *parselCache) save() error {
+	var data []byte
+	for _, entry := range c.sortedEntries() {
+		line, err := json.Marshal(entry)
+		if err != nil {
+			return err
+		}
+		data = append(append(data, line...), '\n')
+	}
+
+	tempPath := c.path + ".tmp"
+	if err := os.WriteFile(tempPath, data, 0o644); err != nil {
+		return err
+	}
+
+	return os.Rename(tempPath, c.path)
+}
+
+// append adds an entry to the end of the file, the caller must hold mu
+func (c *parselCache) append(entry ParselCacheEntry) error {
+	line, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+
+	file, err := os.OpenFile(c.path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o644)
+	if err != nil {
+		return err
+	}
+
+	if _, err := file.Write(append(line, '\n')); err != nil {
+		file.Close()
+		return err
+	}
+
+	return file.Close()
+}
+
+func (c *parselCache) sortedEntries() []ParselCacheEntry {
+	entries := make([]ParselCacheEntry, 0, len(c.entries))
+	for _, entry := range c.entries {
+		entries = append(entries, entry)
+	}
+
+	sort.Slice(entries, func(i, j int) bool {
+		return entries[i].Key < entries[j].Key
+	})
+
+	return entries
+}
+
+// Get returns the cached result for params if it is younger than ttl
+func (c *parselCache) Get(params QueryParams, ttl time.Duration) (FeatureCollection, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if err := c.load(); err != nil {
+		runtime.LogWarning(appContext, "Failed to read parcel cache: "+err.Error())
+		return FeatureCollection{}, false
+	}
+
+	entry, ok := c.entries[parselCacheKey(params)]
+	if !ok || time.Since(entry.FetchedAt) > ttl {
+		return FeatureCollection{}, false
+	}
+
+	return entry.Collection, true
+}
+
+// GetAt returns the cached parcel containing the WGS84 point if it is younger than ttl
+func (c *parselCache) GetAt(lon, lat float64, ttl time.Duration) (FeatureCollection, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if err := c.load(); err != nil {
+		runtime.LogWarning(appContext, "Failed to read parcel cache: "+err.Error())
+		return FeatureCollection{}, false
+	}
+
+	for _, entry := range c.sortedEntries() {
+		if time.Since(entry.FetchedAt) > ttl {
+			continue
+		}
+		for _, feature := range entry.Collection.Features {
+			if len(feature.Geometry.Coordinates) > 0 && pointInPolygon([]float64{lon, lat}, feature.Geometry.Coordinates) {
+				return entry.Collection, true
+			}
+		}
+	}
+
+	return FeatureCollection{}, false
+}
+
+func (c *parselCache) Put(params QueryParams, collection FeatureCollection) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if err := c.load(); err != nil {
+		return err
+	}
+
+	key := parselCacheKey(params)
+	entry := ParselCacheEntry{Key: key, Params: params, FetchedAt: time.Now(), Collection: collection}
+	c.entries[key] = entry
+
+	return c.append(entry)
+}
+
+func (c *parselCache) Entries() ([]ParselCacheEntry, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if err := c.load(); err != nil {
+		return nil, err
+	}
+
+	return c.sortedEntries(), nil
+}
+
+// Invalidate removes the entries with the given keys, every entry if no key is given
+func (c *parselCache) Invalidate(keys ...string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if err := c.load(); err != nil {
+		return err
+	}
+
+	if len(keys) == 0 {
+		c.entries = make(map[string]ParselCacheEntry)
+	}
+	for _, key := range keys {
+		delete(c.entries, key)
+	}
+
+	return c.save()
+}
+
+func (app *App) GetParselCache() []ParselCacheEntry {
+	entries, err := getParselCache().Entries()
+	if err != nil {
+		runtime.LogError(app.ctx, err.Error())
+		return nil
+	}
+
+	return entries
+}
+
+func (app *App) InvalidateParselCacheEntry(key string) error {
+	runtime.LogInfo(app.ctx, "Invalidating parcel cache entry: "+key)
+
+	err := getParselCache().Invalidate(key)
+	if err != nil {
+		runtime.LogError(app.ctx, err.Error())
+	}
+
+	return err
+}
+
+func (app *App) ClearParselCache() error {
+	runtime.LogInfo(app.ctx, "Clearing parcel cache")
+
+	err := getParselCache().Invalidate()
+	if err != nil {
+		runtime.LogError(app.ctx, err.Error())
+	}
+
+	return err
+}
+
+// ExportParselCache writes every cached entry to path as JSON
+func (app *App) ExportParselCache(path string) error {
+	entries, err := getParselCache().Entries()
+	if err != nil {
+		runtime.LogError(app.ctx, err.Error())
+		return err
+	}
+
+	data, err := json.MarshalIndent(entries, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	err = os.WriteFile(path, data, 0o644)
+	if err != nil {
+		runtime.LogError(app.ctx, err.Error())
+		return err
+	}
+
+	runtime.LogInfo(app.ctx, "Parcel cache exported to "+path)
+
+	return nil
+}
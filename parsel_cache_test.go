@@ -0,0 +1,92 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func parselCacheLines(t *testing.T, path string) int {
+	t.Helper()
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return bytes.Count(data, []byte("\n"))
+}
+
+func TestParselCacheAppendAndCompact(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "parsel_cache.json")
+	cache := &parselCache{path: path}
+
+	params := QueryParams{Province: "Konya", District: "Meram", Neighborhood: "Şehitler", Block: "0", Parcel: "318"}
+	first := FeatureCollection{Type: "FeatureCollection"}
+	second := FeatureCollection{Type: "FeatureCollection", Features: []Feature{{Type: "Feature"}}}
+
+	for _, collection := range []FeatureCollection{first, second} {
+		if err := cache.Put(params, collection); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := cache.Put(QueryParams{Province: "Konya", District: "Meram", Neighborhood: "Şehitler", Block: "0", Parcel: "319"}, first); err != nil {
+		t.Fatal(err)
+	}
+
+	// Every Put is one appended line
+	if lines := parselCacheLines(t, path); lines != 3 {
+		t.Fatalf("cache file has %d lines, want 3", lines)
+	}
+
+	// A crash in the middle of an append leaves a partial line
+	file, err := os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0o644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	file.WriteString(`{"key":"konya|meram`)
+	file.Close()
+
+	reloaded := &parselCache{path: path}
+	collection, ok := reloaded.Get(params, time.Hour)
+	if !ok || len(collection.Features) != 1 {
+		t.Fatalf("Get = %+v, %v, want the second Put", collection, ok)
+	}
+
+	// Loading dropped the replaced and the partial line
+	if lines := parselCacheLines(t, path); lines != 2 {
+		t.Errorf("compacted cache file has %d lines, want 2", lines)
+	}
+
+	if err := reloaded.Invalidate(parselCacheKey(params)); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := (&parselCache{path: path}).Get(params, time.Hour); ok {
+		t.Error("invalidated entry is still cached")
+	}
+}
+
+func TestParselCacheGetAt(t *testing.T) {
+	cache := &parselCache{path: filepath.Join(t.TempDir(), "parsel_cache.json")}
+
+	ring := [][]float64{{32.50, 37.90}, {32.51, 37.90}, {32.51, 37.91}, {32.50, 37.91}, {32.50, 37.90}}
+	collection := FeatureCollection{Type: "FeatureCollection", Features: []Feature{{
+		Type:       "Feature",
+		Geometry:   Geometry{Type: "Polygon", Coordinates: [][][]float64{ring}},
+		Properties: Properties{Ada: "101", ParselNo: "5"},
+	}}}
+	if err := cache.Put(QueryParams{Province: "Konya", District: "Meram", Neighborhood: "Şehitler", Block: "101", Parcel: "5"}, collection); err != nil {
+		t.Fatal(err)
+	}
+
+	if got, ok := cache.GetAt(32.505, 37.905, time.Hour); !ok || got.Features[0].Properties.ParselNo != "5" {
+		t.Errorf("GetAt inside = %+v, %v, want parcel 101/5", got, ok)
+	}
+	if _, ok := cache.GetAt(32.52, 37.905, time.Hour); ok {
+		t.Error("GetAt outside found a parcel")
+	}
+	if _, ok := cache.GetAt(32.505, 37.905, 0); ok {
+		t.Error("GetAt returned an expired parcel")
+	}
+}
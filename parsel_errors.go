@@ -0,0 +1,156 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/rand"
+	"net"
+	"strings"
+	"time"
+)
+
+// ParselErrorKind classifies why a parcel query failed
+type ParselErrorKind string
+
+const (
+	ParselErrorNotFound    ParselErrorKind = "not_found"
+	ParselErrorAmbiguous   ParselErrorKind = "ambiguous"
+	ParselErrorUnavailable ParselErrorKind = "unavailable"
+	ParselErrorTimeout     ParselErrorKind = "timeout"
+	ParselErrorParse       ParselErrorKind = "parse"
+	ParselErrorInvalid     ParselErrorKind = "invalid"
+	ParselErrorFailed      ParselErrorKind = "failed" // anything else, like a file error or a missing page element
+)
+
+// ParselError is returned by parcel backends so callers can tell a missing parcel from a
+// site that is down
+type ParselError struct {
+	Kind    ParselErrorKind
+	Message string
+	Err     error
+
+	// Closest options when a name wasn't found or was ambiguous
+	Candidates []NameCandidate
+}
+
+func (e *ParselError) Error() string {
+	if e.Err != nil {
+		return fmt.Sprintf("%s: %s: %v", e.Kind, e.Message, e.Err)
+	}
+	return fmt.Sprintf("%s: %s", e.Kind, e.Message)
+}
+
+func (e *ParselError) Unwrap() error {
+	return e.Err
+}
+
+// Transient reports whether trying again later may succeed
+func (e *ParselError) Transient() bool {
+	return e.Kind == ParselErrorUnavailable || e.Kind == ParselErrorTimeout
+}
+
+func newParselError(kind ParselErrorKind, err error, format string, args ...interface{}) *ParselError {
+	return &ParselError{Kind: kind, Message: fmt.Sprintf(format, args...), Err: err}
+}
+
+// classifyParselError wraps err in a ParselError, guessing the kind from the error's type. Only
+// network errors and timeouts are transient, errors it doesn't know aren't retried.
+func classifyParselError(err error) *ParselError {
+	if err == nil {
+		return nil
+	}
+
+	var parselErr *ParselError
+	if errors.As(err, &parselErr) {
+		return parselErr
+	}
+
+	var netErr net.Error
+	var syntaxErr *json.SyntaxError
+	var typeErr *json.UnmarshalTypeError
+
+	switch {
+	case errors.Is(err, context.DeadlineExceeded), errors.As(err, &netErr) && netErr.Timeout():
+		return newParselError(ParselErrorTimeout, err, "query timed out")
+	case errors.As(err, &netErr):
+		return newParselError(ParselErrorUnavailable, err, "site unavailable")
+	case errors.As(err, &syntaxErr), errors.As(err, &typeErr):
+		return newParselError(ParselErrorParse, err, "unexpected response")
+	}
+
+	return newParselError(ParselErrorFailed, err, "query failed")
+}
+
+// parselStatus is the text written to the status column for a query result
+func parselStatus(err error, cached bool) string {
+	if err == nil {
+		if cached {
+			return "Tamam (önbellek)"
+		}
+		return "Tamam"
+	}
+
+	parselErr := classifyParselError(err)
+
+	switch parselErr.Kind {
+	case ParselErrorNotFound:
+		return "Bulunamadı: " + parselErr.Message
+	case ParselErrorAmbiguous:
+		return "Belirsiz: " + parselErr.Message
+	case ParselErrorTimeout:
+		return "Zaman aşımı"
+	case ParselErrorParse:
+		return "Yanıt okunamadı"
+	case ParselErrorInvalid:
+		return "Eksik bilgi: " + parselErr.Message
+	case ParselErrorFailed:
+		if parselErr.Err != nil {
+			return "Sorgu başarısız: " + parselErr.Err.Error()
+		}
+		return "Sorgu başarısız: " + parselErr.Message
+	}
+
+	return "Site erişilemiyor"
+}
+
+// parselStatusDone reports whether the status column of the row says an earlier run found the
+// parcel, statusIndex is -1 when there is no status column
+func parselStatusDone(row []string, statusIndex int) bool {
+	return statusIndex != -1 && statusIndex < len(row) && strings.HasPrefix(row[statusIndex], "Tamam")
+}
+
+// parselRetryDelay is the exponential backoff before the given retry (1-based) with up to
+// 25% jitter so parallel queries don't retry in lockstep
+func parselRetryDelay(retry int) time.Duration {
+	delay := 2 * time.Second << (retry - 1)
+	if delay > 30*time.Second || delay <= 0 {
+		delay = 30 * time.Second
+	}
+
+	return delay + time.Duration(rand.Int63n(int64(delay)/4+1))
+}
+
+// retryParselQuery runs query until it succeeds, fails permanently or maxRetries retries are used
+func retryParselQuery(ctx context.Context, maxRetries int, query func() (FeatureCollection, error), onRetry func(retry int, err *ParselError)) (FeatureCollection, error) {
+	for retry := 0; ; retry++ {
+		collection, err := query()
+		if err == nil {
+			return collection, nil
+		}
+
+		parselErr := classifyParselError(err)
+		if !parselErr.Transient() || retry >= maxRetries {
+			return FeatureCollection{}, parselErr
+		}
+
+		onRetry(retry+1, parselErr)
+
+		select {
+		case <-ctx.Done():
+			return FeatureCollection{}, newParselError(ParselErrorTimeout, ctx.Err(), "cancelled while waiting to retry")
+		case <-time.After(parselRetryDelay(retry + 1)):
+		}
+	}
+}
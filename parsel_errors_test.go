@@ -0,0 +1,51 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"testing"
+)
+
+func TestClassifyParselError(t *testing.T) {
+	tests := []struct {
+		name      string
+		err       error
+		kind      ParselErrorKind
+		transient bool
+	}{
+		{"deadline", fmt.Errorf("waiting for #export-data: %w", context.DeadlineExceeded), ParselErrorTimeout, true},
+		{"connection refused", &net.OpError{Op: "dial", Net: "tcp", Err: errors.New("connection refused")}, ParselErrorUnavailable, true},
+		{"file", &os.PathError{Op: "open", Path: "parsel.json", Err: os.ErrNotExist}, ParselErrorFailed, false},
+		{"missing element", errors.New("could not find node"), ParselErrorFailed, false},
+		{"already classified", newParselError(ParselErrorNotFound, nil, "parcel 1/2"), ParselErrorNotFound, false},
+	}
+
+	for _, test := range tests {
+		parselErr := classifyParselError(test.err)
+		if parselErr.Kind != test.kind || parselErr.Transient() != test.transient {
+			t.Errorf("%s: kind = %s, transient = %v, want %s, %v", test.name, parselErr.Kind, parselErr.Transient(), test.kind, test.transient)
+		}
+	}
+}
+
+func TestRetryParselQueryStopsOnPermanentErrors(t *testing.T) {
+	calls := 0
+	_, err := retryParselQuery(context.Background(), 3, func() (FeatureCollection, error) {
+		calls++
+		return FeatureCollection{}, errors.New("failed to read downloaded file")
+	}, func(int, *ParselError) {
+		t.Error("a permanent error was retried")
+	})
+
+	if calls != 1 {
+		t.Errorf("query ran %d times, want 1", calls)
+	}
+
+	var parselErr *ParselError
+	if !errors.As(err, &parselErr) || parselErr.Kind != ParselErrorFailed {
+		t.Errorf("err = %v, want a failed ParselError", err)
+	}
+}
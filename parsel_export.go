@@ -0,0 +1,403 @@
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/wailsapp/wails/v2/pkg/runtime"
+)
+
+const (
+	ParselExportGeoJSON = "geojson"
+	ParselExportKML     = "kml"
+	ParselExportDXF     = "dxf"
+	ParselExportPNG     = "png"
+	ParselExportSVG     = "svg"
+)
+
+var parselExportExtensions = map[string]string{
+	ParselExportGeoJSON: ".geojson",
+	ParselExportKML:     ".kml",
+	ParselExportDXF:     ".dxf",
+	ParselExportPNG:     "_Kroki.png",
+	ParselExportSVG:     "_Kroki.svg",
+}
+
+// parseParselExportFormats parses a comma separated format list like "geojson,kml,dxf,png"
+func parseParselExportFormats(formats string) ([]string, error) {
+	var result []string
+	seen := make(map[string]bool)
+
+	for _, format := range strings.Split(formats, ",") {
+		format = strings.ToLower(strings.TrimSpace(format))
+		if format == "" || seen[format] {
+			continue
+		}
+		if _, ok := parselExportExtensions[format]; !ok {
+			return nil, fmt.Errorf("unknown export format: %s", format)
+		}
+
+		seen[format] = true
+		result = append(result, format)
+	}
+
+	return result, nil
+}
+
+// parselExportName is the file name, without extension, the geometry of a parcel is saved as
+func parselExportName(properties Properties) string {
+	name := "Parsel"
+	for _, part := range []string{properties.Mahalle, properties.Ada, properties.ParselNo} {
+		part = strings.Join(strings.Fields(sanitizeCellFolder(part)), "_")
+		if part != "" {
+			name += "_" + part
+		}
+	}
+
+	return name
+}
+
+// parselLabel is the ada/parsel label drawn on exported geometries
+func parselLabel(properties Properties) string {
+	return properties.Ada + "/" + properties.ParselNo
+}
+
+// exportParselGeometry writes the collection to dir once per format and returns the written paths
+func exportParselGeometry(collection FeatureCollection, dir string, formats []string) ([]string, error) {
+	if len(collection.Features) == 0 {
+		return nil, fmt.Errorf("no geometry to export")
+	}
+
+	baseName := parselExportName(collection.Features[0].Properties)
+
+	var paths []string
+
+	for _, format := range formats {
+		path := filepath.Join(dir, baseName+parselExportExtensions[format])
+
+		err := writeParselFile(path, func(w io.Writer) error {
+			switch format {
+			case ParselExportGeoJSON:
+				return writeParselGeoJSON(w, collection)
+			case ParselExportKML:
+				// KML is always WGS84
+				wgs84, err := transformCollection(collection, supportedCRS[EPSGWGS84])
+				if err != nil {
+					return err
+				}
+				return writeParselKML(w, wgs84)
+			case ParselExportDXF:
+				// CAD drawings are in metres, use the configured or the parcel's TM zone
+				target, err := resolveTargetCRS(*config.ParselCRS, collection)
+				if err != nil {
+					return err
+				}
+				projected, err := transformCollection(collection, target)
+				if err != nil {
+					return err
+				}
+				return writeParselDXF(w, projected)
+			case ParselExportPNG, ParselExportSVG:
+				sketch, err := newParselSketch(collection, cachedNeighborFeatures(collection))
+				if err != nil {
+					return err
+				}
+				if format == ParselExportPNG {
+					return writeParselSketchPNG(w, sketch)
+				}
+				return writeParselSketchSVG(w, sketch)
+			}
+			return fmt.Errorf("unknown export format: %s", format)
+		})
+
+		if err != nil {
+			return paths, err
+		}
+
+		paths = append(paths, path)
+	}
+
+	return paths, nil
+}
+
+func writeParselFile(path string, write func(w io.Writer) error) error {
+	file, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	buffered := bufio.NewWriter(file)
+
+	if err := write(buffered); err != nil {
+		return err
+	}
+
+	if err := buffered.Flush(); err != nil {
+		return err
+	}
+
+	return file.Close()
+}
+
+func writeParselGeoJSON(w io.Writer, collection FeatureCollection) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+
+	return encoder.Encode(collection)
+}
+
+// writeParselKML writes the parcels as Google Earth placemarks, KML coordinates are always WGS84
+func writeParselKML(w io.Writer, collection FeatureCollection) error {
+	escape := func(s string) string {
+		var b strings.Builder
+		xml.EscapeText(&b, []byte(s))
+		return b.String()
+	}
+
+	var b strings.Builder
+
+	b.WriteString(xml.Header)
+	b.WriteString(`<kml xmlns="http://www.opengis.net/kml/2.2">` + "\n<Document>\n")
+	b.WriteString(`<Style id="parsel"><LineStyle><color>ff0000ff</color><width>2</width></LineStyle><PolyStyle><color>400000ff</color></PolyStyle></Style>` + "\n")
+
+	for _, feature := range collection.Features {
+		p := feature.Properties
+
+		b.WriteString("<Placemark>\n")
+		b.WriteString("<name>" + escape(parselLabel(p)) + "</name>\n")
+		b.WriteString("<styleUrl>#parsel</styleUrl>\n")
+		b.WriteString("<ExtendedData>\n")
+		for _, data := range [][2]string{
+			{"Il", p.Il}, {"Ilce", p.Ilce}, {"Mahalle", p.Mahalle}, {"Ada", p.Ada}, {"Parsel", p.ParselNo},
+			{"Alan", p.Alan}, {"Mevkii", p.Mevkii}, {"Nitelik", p.Nitelik}, {"Pafta", p.Pafta},
+		} {
+			b.WriteString(`<Data name="` + data[0] + `"><value>` + escape(data[1]) + "</value></Data>\n")
+		}
+		b.WriteString("</ExtendedData>\n")
+		b.WriteString("<Polygon>\n")
+
+		for i, ring := range feature.Geometry.Coordinates {
+			if i == 0 {
+				b.WriteString("<outerBoundaryIs>")
+			} else {
+				b.WriteString("<innerBoundaryIs>")
+			}
+
+			b.WriteString("<LinearRing><coordinates>")
+			for j, point := range ring {
+				if len(point) < 2 {
+					continue
+				}
+				if j > 0 {
+					b.WriteString(" ")
+				}
+				fmt.Fprintf(&b, "%.8f,%.8f,0", point[0], point[1])
+			}
+			b.WriteString("</coordinates></LinearRing>")
+
+			if i == 0 {
+				b.WriteString("</outerBoundaryIs>\n")
+			} else {
+				b.WriteString("</innerBoundaryIs>\n")
+			}
+		}
+
+		b.WriteString("</Polygon>\n</Placemark>\n")
+	}
+
+	b.WriteString("</Document>\n</kml>\n")
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// writeParselDXF writes the parcel boundaries as closed R12 polylines on the PARSEL layer with
+// an ada/parsel label, which both NetCAD and AutoCAD open
+func writeParselDXF(w io.Writer, collection FeatureCollection) error {
+	var b strings.Builder
+
+	group := func(code int, value string) {
+		fmt.Fprintf(&b, "%d\n%s\n", code, value)
+	}
+	number := func(code int, value float64) {
+		group(code, fmt.Sprintf("%.6f", value))
+	}
+
+	group(0, "SECTION")
+	group(2, "HEADER")
+	group(9, "$ACADVER")
+	group(1, "AC1009")
+	group(0, "ENDSEC")
+
+	group(0, "SECTION")
+	group(2, "TABLES")
+	group(0, "TABLE")
+	group(2, "LAYER")
+	group(70, "1")
+	group(0, "LAYER")
+	group(2, "PARSEL")
+	group(70, "0")
+	group(62, "1")
+	group(6, "CONTINUOUS")
+	group(0, "ENDTAB")
+	group(0, "ENDSEC")
+
+	group(0, "SECTION")
+	group(2, "ENTITIES")
+
+	for _, feature := range collection.Features {
+		for _, ring := range feature.Geometry.Coordinates {
+			// GeoJSON rings repeat the first point at the end, closed polylines don't need it
+			if len(ring) > 1 && equalPoint(ring[0], ring[len(ring)-1]) {
+				ring = ring[:len(ring)-1]
+			}
+
+			group(0, "POLYLINE")
+			group(8, "PARSEL")
+			group(66, "1")
+			group(70, "1")
+			for _, point := range ring {
+				if len(point) < 2 {
+					continue
+				}
+				group(0, "VERTEX")
+				group(8, "PARSEL")
+				number(10, point[0])
+				number(20, point[1])
+				number(30, 0)
+			}
+			group(0, "SEQEND")
+			group(8, "PARSEL")
+		}
+
+		if len(feature.Geometry.Coordinates) > 0 {
+			x, y := ringAverage(feature.Geometry.Coordinates[0])
+
+			group(0, "TEXT")
+			group(8, "PARSEL")
+			number(10, x)
+			number(20, y)
+			number(30, 0)
+			number(40, dxfTextHeight(feature.Geometry.Coordinates[0]))
+			group(1, parselLabel(feature.Properties))
+		}
+	}
+
+	group(0, "ENDSEC")
+	group(0, "EOF")
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+func equalPoint(a, b []float64) bool {
+	return len(a) >= 2 && len(b) >= 2 && a[0] == b[0] && a[1] == b[1]
+}
+
+// ringAverage is the average of the ring's vertices, good enough to place a label
+func ringAverage(ring [][]float64) (float64, float64) {
+	if len(ring) > 1 && equalPoint(ring[0], ring[len(ring)-1]) {
+		ring = ring[:len(ring)-1]
+	}
+
+	var x, y float64
+	n := 0
+
+	for _, point := range ring {
+		if len(point) < 2 {
+			continue
+		}
+		x += point[0]
+		y += point[1]
+		n++
+	}
+
+	if n == 0 {
+		return 0, 0
+	}
+
+	return x / float64(n), y / float64(n)
+}
+
+// dxfTextHeight scales the label to a twentieth of the ring's bounding box
+func dxfTextHeight(ring [][]float64) float64 {
+	if len(ring) == 0 || len(ring[0]) < 2 {
+		return 1
+	}
+
+	minX, maxX, minY, maxY := ring[0][0], ring[0][0], ring[0][1], ring[0][1]
+	for _, point := range ring {
+		if len(point) < 2 {
+			continue
+		}
+		minX, maxX = min(minX, point[0]), max(maxX, point[0])
+		minY, maxY = min(minY, point[1]), max(maxY, point[1])
+	}
+
+	size := max(maxX-minX, maxY-minY)
+	if size == 0 {
+		return 1
+	}
+
+	return size / 20
+}
+
+// ParselSorguGeometry returns the whole query result, geometry included
+func (app *App) ParselSorguGeometry(params QueryParams) (FeatureCollection, error) {
+	return app.queryParsel(params, *config.ParselSorguHeadless)
+}
+
+// ExportParselGeometry queries a parcel and writes its geometry to targetFolder in the given formats
+func (app *App) ExportParselGeometry(params QueryParams, targetFolder string, formats string) ([]string, error) {
+	parsedFormats, err := parseParselExportFormats(formats)
+	if err != nil {
+		runtime.LogError(app.ctx, err.Error())
+		return nil, err
+	}
+
+	collection, err := app.queryParsel(params, *config.ParselSorguHeadless)
+	if err != nil {
+		return nil, err
+	}
+
+	paths, err := exportParselGeometry(collection, targetFolder, parsedFormats)
+	if err != nil {
+		runtime.LogError(app.ctx, err.Error())
+		return paths, err
+	}
+
+	runtime.LogInfo(app.ctx, "Exported parcel geometry: "+strings.Join(paths, ", "))
+
+	return paths, nil
+}
+
+// parselParamsFromRow builds the query of a row from the configured column names, a name that
+// isn't a column is used as the value for every row like AddParselSorguFields does
+func parselParamsFromRow(headers []string, row []string) QueryParams {
+	value := func(name string) string {
+		for i, header := range headers {
+			if strings.TrimSpace(header) == name {
+				if i < len(row) {
+					return strings.TrimSpace(row[i])
+				}
+				return ""
+			}
+		}
+		return name
+	}
+
+	return QueryParams{
+		Province:     value(*config.IlCellName),
+		District:     value(*config.IlceCellName),
+		Neighborhood: value(*config.MahalleCellName),
+		Block:        value(*config.AdaCellName),
+		Parcel:       value(*config.ParselCellName),
+	}
+}
@@ -0,0 +1,335 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/xuri/excelize/v2"
+)
+
+// Fields computed from the geometry rather than returned by the site
+const (
+	ParselFieldComputedArea = "HesaplananAlan"
+	ParselFieldPerimeter    = "Cevre"
+	ParselFieldCentroid     = "Merkez"
+	ParselFieldVertexCount  = "KoseSayisi"
+	ParselFieldAreaCheck    = "AlanKontrol"
+	ParselFieldCRS          = "Projeksiyon"
+	ParselFieldNeighbors    = "KomsuParseller"
+)
+
+var parselComputedFields = []string{
+	ParselFieldComputedArea,
+	ParselFieldPerimeter,
+	ParselFieldCentroid,
+	ParselFieldVertexCount,
+	ParselFieldAreaCheck,
+	ParselFieldCRS,
+	ParselFieldNeighbors,
+}
+
+// ParselFieldRule writes the parcel field Field to the column Column. Field is a computed field,
+// a field of Properties or any other property the source returned, matched ignoring case
+type ParselFieldRule struct {
+	Field  string `json:"field"`
+	Column string `json:"column"`
+}
+
+// initialParselFields are the columns of a new config, the ones filled before the mapping existed
+func initialParselFields() []ParselFieldRule {
+	return []ParselFieldRule{
+		{Field: "Alan", Column: "Alan (m2)"},
+		{Field: "Pafta", Column: "Pafta"},
+		{Field: "Nitelik", Column: "Cins"},
+		{Field: "Mevkii", Column: "Mevki"},
+	}
+}
+
+// migrateParselFields turns the per field column names of configs written before ParselFields
+// into rules, the fields without a saved name get the column they were written to by default
+func migrateParselFields(data []byte) {
+	if config.ParselFields != nil {
+		return
+	}
+
+	var legacy struct {
+		Alan    *string `json:"alanCellName"`
+		Pafta   *string `json:"paftaCellName"`
+		Nitelik *string `json:"cinsCellName"`
+		Mevkii  *string `json:"mevkiCellNameSorgu"`
+	}
+	if err := json.Unmarshal(data, &legacy); err != nil {
+		return
+	}
+	if legacy.Alan == nil && legacy.Pafta == nil && legacy.Nitelik == nil && legacy.Mevkii == nil {
+		return
+	}
+
+	var rules []ParselFieldRule
+	for _, field := range []struct {
+		name     string
+		column   *string
+		fallback string
+	}{
+		{"Alan", legacy.Alan, "Alan (m2)"},
+		{"Pafta", legacy.Pafta, "Pafta"},
+		{"Nitelik", legacy.Nitelik, "Cins"},
+		{"Mevkii", legacy.Mevkii, "Mevki"},
+	} {
+		column := field.fallback
+		if field.column != nil {
+			column = *field.column
+		}
+		if strings.TrimSpace(column) != "" {
+			rules = append(rules, ParselFieldRule{Field: field.name, Column: strings.TrimSpace(column)})
+		}
+	}
+
+	config.ParselFields = &rules
+}
+
+// parselPropertyFields returns the names of the fields of Properties, so new properties are
+// listed as soon as they are added there
+func parselPropertyFields() []string {
+	t := reflect.TypeOf(Properties{})
+
+	var fields []string
+	for i := 0; i < t.NumField(); i++ {
+		if name := strings.Split(t.Field(i).Tag.Get("json"), ",")[0]; name != "-" {
+			fields = append(fields, name)
+		}
+	}
+
+	return fields
+}
+
+// parselPropertyValues returns the properties by their names, the ones without a field of
+// Properties included
+func parselPropertyValues(properties Properties) map[string]interface{} {
+	values := make(map[string]interface{}, len(properties.Extra))
+	for name, value := range properties.Extra {
+		values[name] = value
+	}
+
+	v := reflect.ValueOf(properties)
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		if name := strings.Split(t.Field(i).Tag.Get("json"), ",")[0]; name != "-" {
+			values[name] = v.Field(i).String()
+		}
+	}
+
+	return values
+}
+
+func parselFieldNames() []string {
+	return append(parselPropertyFields(), parselComputedFields...)
+}
+
+// GetParselFields returns the fields every parcel has, other properties the site returns can be
+// mapped by their name too
+func (app *App) GetParselFields() []string {
+	return parselFieldNames()
+}
+
+// SetParselFields stores the parcel field columns, they're checked when the query runs
+func (app *App) SetParselFields(rules []ParselFieldRule) {
+	config.ParselFields = &rules
+}
+
+// parselFieldColumn is a field of the parcel written to a column, index is -1 until the column
+// is found or added
+type parselFieldColumn struct {
+	field  string
+	header string
+	index  int
+}
+
+// parselFieldColumnsFromRules checks the rules, known field names are spelled as in
+// parselFieldNames so the computed ones can be told apart
+func parselFieldColumnsFromRules(rules []ParselFieldRule) ([]parselFieldColumn, error) {
+	fields := parselFieldNames()
+
+	var columns []parselFieldColumn
+
+	for _, rule := range rules {
+		field, header := strings.TrimSpace(rule.Field), strings.TrimSpace(rule.Column)
+		if field == "" && header == "" {
+			continue
+		}
+		if field == "" || header == "" {
+			return nil, fmt.Errorf("invalid parcel field rule: %s->%s", rule.Field, rule.Column)
+		}
+
+		for _, name := range fields {
+			if strings.EqualFold(name, field) {
+				field = name
+				break
+			}
+		}
+
+		columns = append(columns, parselFieldColumn{field: field, header: header, index: -1})
+	}
+
+	return columns, nil
+}
+
+// parselFieldColumns are the columns AddParselSorguFields fills from each row's parcel
+type parselFieldColumns struct {
+	columns  []parselFieldColumn
+	yuzolcum int // read for the area check, -1 when not configured
+}
+
+// newParselFieldColumns finds the mapped columns, adding the missing ones after the last column
+func newParselFieldColumns(excel *excelize.File, sheetName string, headers *[]string, rules []ParselFieldRule) (parselFieldColumns, error) {
+	columns, err := parselFieldColumnsFromRules(rules)
+	if err != nil {
+		return parselFieldColumns{}, err
+	}
+
+	for i := range columns {
+		columns[i].index, err = ensureColumn(excel, sheetName, headers, columns[i].header)
+		if err != nil {
+			return parselFieldColumns{}, err
+		}
+	}
+
+	c := parselFieldColumns{columns: columns, yuzolcum: -1}
+
+	// The tapu area is only read
+	if *config.ParselYuzolcumCellName != "" {
+		c.yuzolcum = headerIndex(*headers, *config.ParselYuzolcumCellName)
+	}
+
+	return c, nil
+}
+
+// has reports whether field is mapped to a column
+func (c parselFieldColumns) has(field string) bool {
+	for _, column := range c.columns {
+		if column.field == field {
+			return true
+		}
+	}
+	return false
+}
+
+// filled reports whether every mapped column of the row already has a value
+func (c parselFieldColumns) filled(row []string) bool {
+	if len(c.columns) == 0 {
+		return false
+	}
+
+	for _, column := range c.columns {
+		if column.index >= len(row) || strings.TrimSpace(row[column.index]) == "" {
+			return false
+		}
+	}
+	return true
+}
+
+// measured reports whether any mapped field needs the parcel measured
+func (c parselFieldColumns) measured() bool {
+	for _, field := range []string{ParselFieldComputedArea, ParselFieldPerimeter, ParselFieldCentroid, ParselFieldVertexCount, ParselFieldAreaCheck, ParselFieldCRS} {
+		if c.has(field) {
+			return true
+		}
+	}
+	return false
+}
+
+// write fills the mapped columns of the 1-based excel row, neighbors is the formatted
+// neighbor list if that field is mapped
+func (c parselFieldColumns) write(w *excelCellWriter, excelRow int, collection FeatureCollection, row []string, neighbors string) error {
+	var metrics ParselMetrics
+	if c.measured() {
+		var err error
+		metrics, err = computeParselMetrics(collection)
+		if err != nil {
+			return err
+		}
+	}
+
+	properties := collection.Features[0].Properties
+	values := parselPropertyValues(properties)
+
+	for _, column := range c.columns {
+		var value interface{}
+
+		switch column.field {
+		case ParselFieldComputedArea:
+			value = metrics.Area
+		case ParselFieldPerimeter:
+			value = metrics.Perimeter
+		case ParselFieldCentroid:
+			value = fmt.Sprintf("%.7f, %.7f", metrics.CentroidLat, metrics.CentroidLon)
+		case ParselFieldVertexCount:
+			value = metrics.Vertices
+		case ParselFieldCRS:
+			value = metrics.CRS
+		case ParselFieldAreaCheck:
+			registered := []registeredArea{{"Alan", properties.Alan}}
+			if c.yuzolcum != -1 && c.yuzolcum < len(row) {
+				registered = append(registered, registeredArea{"Yüzölçüm", row[c.yuzolcum]})
+			}
+			value = areaCheckMessage(metrics.Area, *config.ParselAreaTolerance, registered)
+		case ParselFieldNeighbors:
+			value = neighbors
+		default:
+			value = parselPropertyValue(column.field, parselPropertyLookup(values, column.field))
+		}
+
+		if err := w.set(column.index, excelRow, value); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// parselPropertyLookup returns the property called field, matched ignoring case since the
+// sources differ in how they spell the names, nil if the parcel doesn't have it
+func parselPropertyLookup(values map[string]interface{}, field string) interface{} {
+	if value, ok := values[field]; ok {
+		return value
+	}
+
+	for name, value := range values {
+		if strings.EqualFold(name, field) {
+			return value
+		}
+	}
+
+	return nil
+}
+
+// parselPropertyValue returns areas and ada/parsel numbers as numbers so they can be summed and
+// sorted, other properties as the site returned them and lists or objects as their JSON
+func parselPropertyValue(field string, value interface{}) interface{} {
+	switch v := value.(type) {
+	case nil:
+		return ""
+	case string:
+		switch field {
+		case "Alan":
+			if area, err := excelNumber(v); err == nil {
+				return area
+			}
+		case "Ada", "ParselNo":
+			if number, ok := excelNumberText(v); ok {
+				return number
+			}
+		}
+		return v
+	case float64, bool:
+		return v
+	}
+
+	data, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Sprint(value)
+	}
+	return string(data)
+}
@@ -0,0 +1,147 @@
+package main
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+func TestMigrateParselFields(t *testing.T) {
+	saved := config
+	defer func() { config = saved }()
+
+	tests := []struct {
+		name string
+		data string
+		want *[]ParselFieldRule
+	}{
+		{
+			name: "renamed and missing columns",
+			data: `{"alanCellName":"Yüzölçümü","cinsCellName":""}`,
+			want: &[]ParselFieldRule{
+				{Field: "Alan", Column: "Yüzölçümü"},
+				{Field: "Pafta", Column: "Pafta"},
+				{Field: "Mevkii", Column: "Mevki"},
+			},
+		},
+		{
+			name: "new config",
+			data: `{"theme":"dark"}`,
+			want: nil,
+		},
+		{
+			name: "already migrated",
+			data: `{"alanCellName":"Yüzölçümü","parselFields":[{"field":"Nitelik","column":"Cins"}]}`,
+			want: &[]ParselFieldRule{{Field: "Nitelik", Column: "Cins"}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config = Config{}
+			if err := json.Unmarshal([]byte(tt.data), &config); err != nil {
+				t.Fatal(err)
+			}
+
+			migrateParselFields([]byte(tt.data))
+
+			if !reflect.DeepEqual(config.ParselFields, tt.want) {
+				t.Errorf("ParselFields = %+v, want %+v", config.ParselFields, tt.want)
+			}
+		})
+	}
+}
+
+func TestPropertiesExtraRoundTrip(t *testing.T) {
+	data := []byte(`{"ParselNo":"4","Alan":"1.250,00","ada":"123","Durum":"Aktif","TapuAlani":1250}`)
+
+	var properties Properties
+	if err := json.Unmarshal(data, &properties); err != nil {
+		t.Fatal(err)
+	}
+
+	want := Properties{ParselNo: "4", Alan: "1.250,00", Ada: "123",
+		Extra: map[string]interface{}{"Durum": "Aktif", "TapuAlani": 1250.0}}
+	if !reflect.DeepEqual(properties, want) {
+		t.Fatalf("Unmarshal = %+v, want %+v", properties, want)
+	}
+
+	// Cached parcels are written and read back the same way
+	encoded, err := json.Marshal(properties)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var decoded Properties
+	if err := json.Unmarshal(encoded, &decoded); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(decoded, want) {
+		t.Errorf("round trip = %+v, want %+v", decoded, want)
+	}
+}
+
+func TestParselFieldColumnsFromRules(t *testing.T) {
+	columns, err := parselFieldColumnsFromRules([]ParselFieldRule{
+		{Field: "alan", Column: "Alan (m2)"},
+		{Field: "komsuparseller", Column: "Komşular"},
+		{Field: "durum", Column: "Durum"},
+		{},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := []parselFieldColumn{
+		{field: "Alan", header: "Alan (m2)", index: -1},
+		{field: ParselFieldNeighbors, header: "Komşular", index: -1},
+		{field: "durum", header: "Durum", index: -1},
+	}
+	if !reflect.DeepEqual(columns, want) {
+		t.Errorf("columns = %+v, want %+v", columns, want)
+	}
+
+	if _, err := parselFieldColumnsFromRules([]ParselFieldRule{{Field: "Alan"}}); err == nil {
+		t.Error("rule without a column was accepted")
+	}
+}
+
+func TestParselPropertyValue(t *testing.T) {
+	values := parselPropertyValues(Properties{Alan: "1.250,50", Ada: "0123", ParselNo: "4",
+		Extra: map[string]interface{}{"Durum": "Aktif", "TapuAlani": 1250.0, "Maliklar": []interface{}{"A", "B"}}})
+
+	tests := []struct {
+		field string
+		want  interface{}
+	}{
+		{"Alan", 1250.5},
+		{"Ada", "0123"},
+		{"ParselNo", 4},
+		{"durum", "Aktif"},
+		{"TapuAlani", 1250.0},
+		{"Maliklar", `["A","B"]`},
+		{"Yok", ""},
+	}
+
+	for _, tt := range tests {
+		if got := parselPropertyValue(tt.field, parselPropertyLookup(values, tt.field)); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%s = %#v, want %#v", tt.field, got, tt.want)
+		}
+	}
+}
+
+func TestParselAreaValues(t *testing.T) {
+	// The site writes areas with a decimal comma, numbers of the API are formatted the same way
+	api := propertiesFromAPI(map[string]interface{}{"alan": 1234.567, "adaNo": 123.0})
+	if api.Alan != "1234,567" || api.Ada != "123" {
+		t.Errorf("propertiesFromAPI = %+v, want Alan 1234,567 and Ada 123", api)
+	}
+
+	for _, value := range []string{api.Alan, "1.234,567", "1234.567"} {
+		if got := parselPropertyValue("Alan", value); got != 1234.567 {
+			t.Errorf("Alan %q = %#v, want 1234.567", value, got)
+		}
+		if got := areaCheckMessage(1234.567, 1, []registeredArea{{"Alan", value}}); got != "Uygun" {
+			t.Errorf("area check of %q = %s, want Uygun", value, got)
+		}
+	}
+}
@@ -0,0 +1,211 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/xuri/excelize/v2"
+)
+
+// ParselMetrics are measurements computed from a parcel's polygon in a projected system
+type ParselMetrics struct {
+	CRS         string  `json:"crs"`
+	Area        float64 `json:"area"`      // m², holes subtracted
+	Perimeter   float64 `json:"perimeter"` // m, holes included
+	CentroidX   float64 `json:"centroidX"` // easting in CRS
+	CentroidY   float64 `json:"centroidY"` // northing in CRS
+	CentroidLon float64 `json:"centroidLon"`
+	CentroidLat float64 `json:"centroidLat"`
+	Vertices    int     `json:"vertices"`
+}
+
+// computeParselMetrics measures the collection in the configured projected system, a geographic
+// setting falls back to the parcel's TM zone since areas in degrees mean nothing
+func computeParselMetrics(collection FeatureCollection) (ParselMetrics, error) {
+	target, err := resolveTargetCRS(*config.ParselCRS, collection)
+	if err != nil {
+		return ParselMetrics{}, err
+	}
+	if !target.projected {
+		target, err = resolveTargetCRS("auto", collection)
+		if err != nil {
+			return ParselMetrics{}, err
+		}
+	}
+
+	projected, err := transformCollection(collection, target)
+	if err != nil {
+		return ParselMetrics{}, err
+	}
+
+	metrics := ParselMetrics{CRS: fmt.Sprintf("EPSG:%d", target.Code)}
+
+	// Centroid of all outer rings weighted by their area
+	var weightedX, weightedY, outerArea float64
+
+	for _, feature := range projected.Features {
+		for i, ring := range feature.Geometry.Coordinates {
+			ring = openRing(ring)
+			if len(ring) < 3 {
+				continue
+			}
+
+			area, cx, cy := ringAreaCentroid(ring)
+
+			if i == 0 {
+				metrics.Area += math.Abs(area)
+				weightedX += cx * math.Abs(area)
+				weightedY += cy * math.Abs(area)
+				outerArea += math.Abs(area)
+			} else {
+				metrics.Area -= math.Abs(area)
+			}
+
+			metrics.Perimeter += ringPerimeter(ring)
+			metrics.Vertices += len(ring)
+		}
+	}
+
+	if outerArea == 0 {
+		return ParselMetrics{}, fmt.Errorf("parcel has no polygon")
+	}
+
+	metrics.CentroidX = weightedX / outerArea
+	metrics.CentroidY = weightedY / outerArea
+
+	metrics.CentroidLon, metrics.CentroidLat, err = toWGS84(target, metrics.CentroidX, metrics.CentroidY)
+	if err != nil {
+		return ParselMetrics{}, err
+	}
+
+	return metrics, nil
+}
+
+// openRing drops the closing vertex GeoJSON repeats at the end of a ring
+func openRing(ring [][]float64) [][]float64 {
+	if len(ring) > 1 && equalPoint(ring[0], ring[len(ring)-1]) {
+		return ring[:len(ring)-1]
+	}
+	return ring
+}
+
+// ringAreaCentroid returns the signed shoelace area and the centroid of an open ring
+func ringAreaCentroid(ring [][]float64) (float64, float64, float64) {
+	// Relative to the first vertex to keep precision with large TM coordinates
+	x0, y0 := ring[0][0], ring[0][1]
+
+	var area, cx, cy float64
+	for i := range ring {
+		j := (i + 1) % len(ring)
+		xi, yi := ring[i][0]-x0, ring[i][1]-y0
+		xj, yj := ring[j][0]-x0, ring[j][1]-y0
+
+		cross := xi*yj - xj*yi
+		area += cross
+		cx += (xi + xj) * cross
+		cy += (yi + yj) * cross
+	}
+
+	area /= 2
+	if area == 0 {
+		return 0, x0, y0
+	}
+
+	return area, x0 + cx/(6*area), y0 + cy/(6*area)
+}
+
+func ringPerimeter(ring [][]float64) float64 {
+	perimeter := 0.0
+	for i := range ring {
+		j := (i + 1) % len(ring)
+		perimeter += math.Hypot(ring[j][0]-ring[i][0], ring[j][1]-ring[i][1])
+	}
+	return perimeter
+}
+
+// areaDiscrepancy returns the difference of computed to registered in percent of registered
+func areaDiscrepancy(computed, registered float64) float64 {
+	if registered == 0 {
+		return math.Inf(1)
+	}
+	return math.Abs(computed-registered) / registered * 100
+}
+
+// registeredArea is an area from the registry, like the parcel's Alan or the tapu's Yüzölçüm
+type registeredArea struct {
+	name  string
+	value string
+}
+
+// areaCheckMessage describes how the computed area compares to the registered areas,
+// values that are empty or can't be parsed are skipped
+func areaCheckMessage(computed float64, tolerance float64, registered []registeredArea) string {
+	var problems []string
+	checked := 0
+
+	for _, r := range registered {
+		area, err := excelNumber(r.value)
+		if strings.TrimSpace(r.value) == "" || err != nil {
+			continue
+		}
+
+		checked++
+
+		if discrepancy := areaDiscrepancy(computed, area); discrepancy > tolerance {
+			problems = append(problems, fmt.Sprintf("%s farkı %%%s", r.name, strconv.FormatFloat(discrepancy, 'f', 2, 64)))
+		}
+	}
+
+	if checked == 0 {
+		return "Kontrol edilemedi"
+	}
+	if len(problems) > 0 {
+		return "Uyumsuz: " + strings.Join(problems, ", ")
+	}
+
+	return "Uygun"
+}
+
+// headerIndex returns the index of the column named header, -1 if there is none
+func headerIndex(headers []string, header string) int {
+	for i, h := range headers {
+		if strings.TrimSpace(h) == header {
+			return i
+		}
+	}
+	return -1
+}
+
+// ensureColumn returns the index of the column named header, appending it to the header row if missing
+func ensureColumn(excel *excelize.File, sheetName string, headers *[]string, header string) (int, error) {
+	if index := headerIndex(*headers, header); index != -1 {
+		return index, nil
+	}
+
+	index := len(*headers)
+
+	name, err := excelize.CoordinatesToCellName(index+1, 1)
+	if err != nil {
+		return -1, err
+	}
+
+	if err := excel.SetCellStr(sheetName, name, header); err != nil {
+		return -1, err
+	}
+
+	*headers = append(*headers, header)
+
+	return index, nil
+}
+
+// ComputeParselMetrics queries a parcel and measures its polygon
+func (app *App) ComputeParselMetrics(params QueryParams) (ParselMetrics, error) {
+	collection, err := app.queryParsel(params, *config.ParselSorguHeadless)
+	if err != nil {
+		return ParselMetrics{}, err
+	}
+
+	return computeParselMetrics(collection)
+}
@@ -0,0 +1,268 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const tkgmApiUrl = "https://cbsapi.tkgm.gov.tr/megsiswebapi.v3/api"
+
+// administrativeUnit is an il, ilçe or mahalle as listed by the idariYapi endpoints
+type administrativeUnit struct {
+	ID   int    `json:"id"`
+	Text string `json:"text"`
+}
+
+// apiFeature is a parcel as returned by the parsel endpoint, the properties are kept by name so
+// the ones without a field of Properties can still be mapped to columns
+type apiFeature struct {
+	Type       string                 `json:"type"`
+	Geometry   Geometry               `json:"geometry"`
+	Properties map[string]interface{} `json:"properties"`
+}
+
+// httpParselBackend queries the JSON endpoints behind parselsorgu.tkgm.gov.tr directly
+type httpParselBackend struct {
+	client  *http.Client
+	baseURL string
+
+	// Administrative unit lists rarely change, keep them for the lifetime of the backend
+	mu    sync.Mutex
+	units map[string][]administrativeUnit
+}
+
+// newHTTPParselBackend creates a backend for the API at baseURL, tkgmApiUrl if empty
+func newHTTPParselBackend(baseURL string, client *http.Client) *httpParselBackend {
+	if baseURL == "" {
+		baseURL = tkgmApiUrl
+	}
+	if client == nil {
+		client = &http.Client{Timeout: 30 * time.Second}
+	}
+
+	return &httpParselBackend{
+		client:  client,
+		baseURL: strings.TrimRight(baseURL, "/"),
+		units:   make(map[string][]administrativeUnit),
+	}
+}
+
+func (b *httpParselBackend) Query(ctx context.Context, params QueryParams) (FeatureCollection, error) {
+	if params.Province == "" || params.District == "" || params.Neighborhood == "" || params.Block == "" || params.Parcel == "" {
+		return FeatureCollection{}, newParselError(ParselErrorInvalid, nil, "province, district, neighborhood, block and parcel are required")
+	}
+
+	province, err := b.resolveUnit(ctx, "idariYapi/ilListe", "province", params.Province)
+	if err != nil {
+		return FeatureCollection{}, err
+	}
+
+	district, err := b.resolveUnit(ctx, fmt.Sprintf("idariYapi/ilceListe/%d", province.ID), "district", params.District)
+	if err != nil {
+		return FeatureCollection{}, err
+	}
+
+	neighborhood, err := b.resolveUnit(ctx, fmt.Sprintf("idariYapi/mahalleListe/%d", district.ID), "neighborhood", params.Neighborhood)
+	if err != nil {
+		return FeatureCollection{}, err
+	}
+
+	endpoint := fmt.Sprintf("parsel/%d/%s/%s", neighborhood.ID, url.PathEscape(strings.TrimSpace(params.Block)), url.PathEscape(strings.TrimSpace(params.Parcel)))
+
+	var feature apiFeature
+	if err := b.getJSON(ctx, endpoint, &feature); err != nil {
+		return FeatureCollection{}, err
+	}
+
+	if len(feature.Geometry.Coordinates) == 0 {
+		return FeatureCollection{}, newParselError(ParselErrorNotFound, nil, "parcel %s/%s", params.Block, params.Parcel)
+	}
+
+	return featureCollectionFromAPI(feature), nil
+}
+
+// QueryPoint finds the parcel containing the WGS84 point
+func (b *httpParselBackend) QueryPoint(ctx context.Context, lon, lat float64) (FeatureCollection, error) {
+	endpoint := fmt.Sprintf("parsel/%s/%s/", strconv.FormatFloat(lat, 'f', 7, 64), strconv.FormatFloat(lon, 'f', 7, 64))
+
+	var feature apiFeature
+	if err := b.getJSON(ctx, endpoint, &feature); err != nil {
+		return FeatureCollection{}, err
+	}
+
+	if len(feature.Geometry.Coordinates) == 0 {
+		return FeatureCollection{}, newParselError(ParselErrorNotFound, nil, "no parcel at %.7f, %.7f", lat, lon)
+	}
+
+	return featureCollectionFromAPI(feature), nil
+}
+
+func (b *httpParselBackend) Close() error {
+	b.client.CloseIdleConnections()
+	return nil
+}
+
+// resolveUnit finds the unit called name in the list served at endpoint, kind names the unit in errors
+func (b *httpParselBackend) resolveUnit(ctx context.Context, endpoint string, kind string, name string) (administrativeUnit, error) {
+	units, err := b.listUnits(ctx, endpoint)
+	if err != nil {
+		return administrativeUnit{}, err
+	}
+
+	names := make([]string, len(units))
+	for i, unit := range units {
+		names[i] = unit.Text
+	}
+
+	index, err := resolveAdminName(kind, name, names, parselNameMinScore())
+	if err != nil {
+		return administrativeUnit{}, err
+	}
+
+	return units[index], nil
+}
+
+func (b *httpParselBackend) listUnits(ctx context.Context, endpoint string) ([]administrativeUnit, error) {
+	b.mu.Lock()
+	units, ok := b.units[endpoint]
+	b.mu.Unlock()
+
+	if ok {
+		return units, nil
+	}
+
+	var collection struct {
+		Features []struct {
+			Properties administrativeUnit `json:"properties"`
+		} `json:"features"`
+	}
+
+	if err := b.getJSON(ctx, endpoint, &collection); err != nil {
+		return nil, err
+	}
+
+	units = make([]administrativeUnit, 0, len(collection.Features))
+	for _, feature := range collection.Features {
+		units = append(units, feature.Properties)
+	}
+
+	b.mu.Lock()
+	b.units[endpoint] = units
+	b.mu.Unlock()
+
+	return units, nil
+}
+
+func (b *httpParselBackend) getJSON(ctx context.Context, endpoint string, v interface{}) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, b.baseURL+"/"+endpoint, nil)
+	if err != nil {
+		return err
+	}
+
+	// The API only answers requests coming from the parcel query site
+	req.Header.Set("Accept", "application/json")
+	req.Header.Set("Origin", "https://parselsorgu.tkgm.gov.tr")
+	req.Header.Set("Referer", "https://parselsorgu.tkgm.gov.tr/")
+	req.Header.Set("User-Agent", "folder-creator/"+version)
+
+	resp, err := b.client.Do(req)
+	if err != nil {
+		return classifyParselError(err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return classifyParselError(err)
+	}
+
+	switch {
+	case resp.StatusCode == http.StatusNoContent, resp.StatusCode == http.StatusNotFound, resp.StatusCode == http.StatusBadRequest:
+		return newParselError(ParselErrorNotFound, nil, "GET %s: %s", endpoint, resp.Status)
+	case resp.StatusCode >= 500, resp.StatusCode == http.StatusTooManyRequests:
+		return newParselError(ParselErrorUnavailable, nil, "GET %s: %s", endpoint, resp.Status)
+	case resp.StatusCode != http.StatusOK:
+		return newParselError(ParselErrorFailed, nil, "GET %s: %s", endpoint, resp.Status)
+	case len(strings.TrimSpace(string(body))) == 0 || strings.TrimSpace(string(body)) == "null":
+		return newParselError(ParselErrorNotFound, nil, "GET %s: empty response", endpoint)
+	}
+
+	if err := json.Unmarshal(body, v); err != nil {
+		return newParselError(ParselErrorParse, err, "failed to unmarshal %s", endpoint)
+	}
+
+	return nil
+}
+
+// featureCollectionFromAPI converts a parcel feature of the API to the format of the site's GeoJSON export
+func featureCollectionFromAPI(feature apiFeature) FeatureCollection {
+	var collection FeatureCollection
+
+	collection.Type = "FeatureCollection"
+	collection.CRS.Type = "name"
+	collection.CRS.Properties.Name = "EPSG:4326"
+	collection.Features = []Feature{{
+		Type:       "Feature",
+		Geometry:   feature.Geometry,
+		Properties: propertiesFromAPI(feature.Properties),
+	}}
+
+	return collection
+}
+
+// propertiesFromAPI fills the fields of Properties from the API's names for them and keeps the
+// other properties in Extra
+func propertiesFromAPI(api map[string]interface{}) Properties {
+	var properties Properties
+
+	extra := make(map[string]interface{}, len(api))
+	for name, value := range api {
+		extra[name] = value
+	}
+
+	for _, field := range []struct {
+		name  string
+		value *string
+	}{
+		{"ilAd", &properties.Il},
+		{"ilceAd", &properties.Ilce},
+		{"mahalleAd", &properties.Mahalle},
+		{"adaNo", &properties.Ada},
+		{"parselNo", &properties.ParselNo},
+		{"alan", &properties.Alan},
+		{"mevkii", &properties.Mevkii},
+		{"nitelik", &properties.Nitelik},
+		{"pafta", &properties.Pafta},
+	} {
+		value, ok := extra[field.name]
+		if !ok {
+			continue
+		}
+		delete(extra, field.name)
+
+		switch v := value.(type) {
+		case string:
+			*field.value = v
+		case float64:
+			// With a decimal comma like the site's own text, "1234.567" would read as thousands
+			*field.value = strings.Replace(strconv.FormatFloat(v, 'f', -1, 64), ".", ",", 1)
+		case nil:
+		default:
+			*field.value = fmt.Sprint(v)
+		}
+	}
+
+	if len(extra) > 0 {
+		properties.Extra = extra
+	}
+
+	return properties
+}
@@ -0,0 +1,148 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// newParselStubServer serves the idariYapi and parsel endpoints for Konya/Selçuklu/Bosna Hersek
+func newParselStubServer(t *testing.T) (*httptest.Server, *int32) {
+	t.Helper()
+
+	var unitRequests int32
+	mux := http.NewServeMux()
+
+	units := func(body string) http.HandlerFunc {
+		return func(w http.ResponseWriter, r *http.Request) {
+			atomic.AddInt32(&unitRequests, 1)
+			if r.Header.Get("Origin") != "https://parselsorgu.tkgm.gov.tr" {
+				http.Error(w, "forbidden", http.StatusForbidden)
+				return
+			}
+			w.Header().Set("Content-Type", "application/json")
+			w.Write([]byte(body))
+		}
+	}
+
+	mux.HandleFunc("/idariYapi/ilListe", units(`{"type":"FeatureCollection","features":[
+		{"type":"Feature","properties":{"id":6,"text":"ANKARA"}},
+		{"type":"Feature","properties":{"id":42,"text":"KONYA"}}]}`))
+	mux.HandleFunc("/idariYapi/ilceListe/42", units(`{"type":"FeatureCollection","features":[
+		{"type":"Feature","properties":{"id":500,"text":"SELÇUKLU"}},
+		{"type":"Feature","properties":{"id":501,"text":"MERAM"}}]}`))
+	mux.HandleFunc("/idariYapi/mahalleListe/500", units(`{"type":"FeatureCollection","features":[
+		{"type":"Feature","properties":{"id":9001,"text":"BOSNA HERSEK"}}]}`))
+
+	mux.HandleFunc("/parsel/9001/123/4", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"type":"Feature",
+			"geometry":{"type":"Polygon","coordinates":[[[32.48,37.95],[32.49,37.95],[32.49,37.96],[32.48,37.95]]]},
+			"properties":{"ilAd":"Konya","ilceAd":"Selçuklu","mahalleAd":"Bosna Hersek","adaNo":"123","parselNo":"4",
+				"alan":"1.250,00","mevkii":"Çayırlık","nitelik":"Arsa","pafta":"27L-IV","zeminKmdurum":"Askıda","tapuAlani":1250}}`))
+	})
+	mux.HandleFunc("/parsel/9001/123/5", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"type":"Feature","geometry":{"type":"Polygon","coordinates":[[[32.48,`))
+	})
+	mux.HandleFunc("/parsel/9001/123/6", func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-r.Context().Done():
+		case <-time.After(5 * time.Second):
+		}
+	})
+	mux.HandleFunc("/parsel/9001/123/7", func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "maintenance", http.StatusServiceUnavailable)
+	})
+	mux.HandleFunc("/parsel/9001/123/8", func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "forbidden", http.StatusForbidden)
+	})
+
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+
+	return server, &unitRequests
+}
+
+func TestHTTPParselBackendQuery(t *testing.T) {
+	server, unitRequests := newParselStubServer(t)
+	backend := newHTTPParselBackend(server.URL, nil)
+	defer backend.Close()
+
+	params := QueryParams{Province: "Konya", District: "Selçuklu", Neighborhood: "Bosna Hersek", Block: "123", Parcel: "4"}
+
+	collection, err := backend.Query(context.Background(), params)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(collection.Features) != 1 {
+		t.Fatalf("got %d features, want 1", len(collection.Features))
+	}
+
+	feature := collection.Features[0]
+	want := Properties{ParselNo: "4", Alan: "1.250,00", Mevkii: "Çayırlık", Nitelik: "Arsa", Ada: "123",
+		Il: "Konya", Ilce: "Selçuklu", Pafta: "27L-IV", Mahalle: "Bosna Hersek",
+		Extra: map[string]interface{}{"zeminKmdurum": "Askıda", "tapuAlani": 1250.0}}
+	if !reflect.DeepEqual(feature.Properties, want) {
+		t.Errorf("properties = %+v, want %+v", feature.Properties, want)
+	}
+	if len(feature.Geometry.Coordinates) != 1 || len(feature.Geometry.Coordinates[0]) != 4 {
+		t.Errorf("geometry = %v, want one ring of 4 points", feature.Geometry.Coordinates)
+	}
+	if collection.CRS.Properties.Name != "EPSG:4326" {
+		t.Errorf("crs = %s, want EPSG:4326", collection.CRS.Properties.Name)
+	}
+
+	// The unit lists are fetched once per backend
+	if _, err := backend.Query(context.Background(), params); err != nil {
+		t.Fatal(err)
+	}
+	if n := atomic.LoadInt32(unitRequests); n != 3 {
+		t.Errorf("unit lists requested %d times, want 3", n)
+	}
+}
+
+func TestHTTPParselBackendErrors(t *testing.T) {
+	server, _ := newParselStubServer(t)
+	backend := newHTTPParselBackend(server.URL, &http.Client{Timeout: 200 * time.Millisecond})
+	defer backend.Close()
+
+	query := QueryParams{Province: "Konya", District: "Selçuklu", Neighborhood: "Bosna Hersek", Block: "123"}
+
+	tests := []struct {
+		name   string
+		params QueryParams
+		kind   ParselErrorKind
+	}{
+		{"missing parcel", withParcel(query, "99"), ParselErrorNotFound},
+		{"unknown neighborhood", QueryParams{Province: "Konya", District: "Selçuklu", Neighborhood: "Zzzz", Block: "1", Parcel: "1"}, ParselErrorNotFound},
+		{"malformed json", withParcel(query, "5"), ParselErrorParse},
+		{"timeout", withParcel(query, "6"), ParselErrorTimeout},
+		{"unavailable", withParcel(query, "7"), ParselErrorUnavailable},
+		{"forbidden", withParcel(query, "8"), ParselErrorFailed},
+		{"no parcel number", query, ParselErrorInvalid},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			_, err := backend.Query(context.Background(), test.params)
+
+			var parselErr *ParselError
+			if !errors.As(err, &parselErr) {
+				t.Fatalf("err = %v, want a ParselError", err)
+			}
+			if parselErr.Kind != test.kind {
+				t.Errorf("kind = %s, want %s (%v)", parselErr.Kind, test.kind, err)
+			}
+		})
+	}
+}
+
+func withParcel(params QueryParams, parcel string) QueryParams {
+	params.Parcel = parcel
+	return params
+}
@@ -0,0 +1,58 @@
+package main
+
+import (
+	"context"
+	"math/rand"
+	"sync"
+	"time"
+)
+
+// parselRateLimiter spaces parcel queries evenly so all workers together stay under a
+// requests-per-minute budget, each slot delayed by a random jitter to avoid a fixed rhythm
+type parselRateLimiter struct {
+	mu       sync.Mutex
+	interval time.Duration
+	next     time.Time
+}
+
+var parselLimiter = &parselRateLimiter{}
+
+// setRate changes the budget, 0 or less disables limiting
+func (l *parselRateLimiter) setRate(perMinute int) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if perMinute <= 0 {
+		l.interval = 0
+		return
+	}
+
+	l.interval = time.Minute / time.Duration(perMinute)
+}
+
+// Wait blocks until the caller's slot comes up or ctx is done
+func (l *parselRateLimiter) Wait(ctx context.Context) error {
+	l.mu.Lock()
+	if l.interval == 0 {
+		l.mu.Unlock()
+		return nil
+	}
+
+	now := time.Now()
+	if l.next.Before(now) {
+		l.next = now
+	}
+	slot := l.next
+	l.next = l.next.Add(l.interval)
+
+	// Up to half an interval late, never early, so the budget holds
+	jitter := time.Duration(rand.Int63n(int64(l.interval)/2 + 1))
+	l.mu.Unlock()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-time.After(time.Until(slot) + jitter):
+		return nil
+	}
+}
@@ -0,0 +1,378 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/wailsapp/wails/v2/pkg/runtime"
+	"github.com/xuri/excelize/v2"
+)
+
+// CoordinateQuery is a point to find the parcel of, X is the longitude or easting and Y the
+// latitude or northing in CRS
+type CoordinateQuery struct {
+	X   float64 `json:"x"`
+	Y   float64 `json:"y"`
+	CRS string  `json:"crs"` // empty for WGS84
+}
+
+// PaftaQueryParams finds a parcel by its old pafta reference instead of its ada
+type PaftaQueryParams struct {
+	Province     string `json:"province"`
+	District     string `json:"district"`
+	Neighborhood string `json:"neighborhood"`
+	Pafta        string `json:"pafta"`
+	Parcel       string `json:"parcel"`
+	Block        string `json:"block"` // ada of a parcel that has both, empty for one with only a pafta
+}
+
+// parselPointBackend is implemented by backends that can find the parcel containing a point
+type parselPointBackend interface {
+	QueryPoint(ctx context.Context, lon, lat float64) (FeatureCollection, error)
+}
+
+// The site's map has no point search to drive, so point queries of the browser backend go to the
+// API. Like parselBackend it is started when first needed and closed with it, under parselBackendMu.
+var fallbackPointBackend *httpParselBackend
+
+func pointParselBackend(headless bool) (parselPointBackend, error) {
+	if *config.ParselSorguBackend != ParselBackendBrowser {
+		backend, err := runningParselBackend(headless)
+		if err != nil {
+			return nil, err
+		}
+
+		if pointBackend, ok := backend.(parselPointBackend); ok {
+			return pointBackend, nil
+		}
+	}
+
+	parselBackendMu.Lock()
+	defer parselBackendMu.Unlock()
+
+	if fallbackPointBackend == nil {
+		fallbackPointBackend = newHTTPParselBackend("", nil)
+	}
+
+	return fallbackPointBackend, nil
+}
+
+// wgs84 returns the point's longitude and latitude, rejecting points outside Turkey which
+// usually have their coordinates swapped
+func (q CoordinateQuery) wgs84() (float64, float64, error) {
+	crs, err := parseCRS(q.CRS)
+	if err != nil {
+		return 0, 0, newParselError(ParselErrorInvalid, err, "invalid coordinate system")
+	}
+
+	lon, lat, err := toWGS84(crs, q.X, q.Y)
+	if err != nil {
+		return 0, 0, newParselError(ParselErrorInvalid, err, "invalid point")
+	}
+
+	if lon < 25 || lon > 45 || lat < 35 || lat > 43 {
+		return 0, 0, newParselError(ParselErrorInvalid, nil, "point %.7f, %.7f is outside Turkey, check the order of the coordinates", lat, lon)
+	}
+
+	return lon, lat, nil
+}
+
+// queryParselAt finds the parcel containing point, answering from the parcel cache when a cached
+// parcel contains it. The result is cached under the parcel's ada/parsel query so later queries
+// of the parcel hit the cache.
+func (app *App) queryParselAt(point CoordinateQuery, headless bool) (FeatureCollection, error) {
+	featureCollection, _, err := app.queryParselAtCached(point, headless)
+	return featureCollection, err
+}
+
+// queryParselAtCached is queryParselAt also reporting whether the result came from the cache
+func (app *App) queryParselAtCached(point CoordinateQuery, headless bool) (FeatureCollection, bool, error) {
+	lon, lat, err := point.wgs84()
+	if err != nil {
+		return FeatureCollection{}, false, err
+	}
+
+	runtime.LogInfo(app.ctx, fmt.Sprintf("Parsel Sorgu: %.7f,%.7f", lat, lon))
+
+	ttl := parselCacheTTL()
+
+	if ttl > 0 {
+		if featureCollection, ok := getParselCache().GetAt(lon, lat, ttl); ok {
+			runtime.LogInfo(app.ctx, "Using cached parcel: "+parselFeatureKey(featureCollection.Features[0].Properties))
+			return featureCollection, true, nil
+		}
+	}
+
+	featureCollection, err := app.runParselQuery(func() (FeatureCollection, error) {
+		backend, err := pointParselBackend(headless)
+		if err != nil {
+			return FeatureCollection{}, newParselError(ParselErrorUnavailable, err, "failed to start %s backend", *config.ParselSorguBackend)
+		}
+
+		featureCollection, err := backend.QueryPoint(app.ctx, lon, lat)
+		if err == nil && len(featureCollection.Features) == 0 {
+			err = newParselError(ParselErrorNotFound, nil, "no parcel at %.7f, %.7f", lat, lon)
+		}
+
+		return featureCollection, err
+	})
+
+	if err != nil {
+		runtime.LogError(app.ctx, err.Error())
+		return FeatureCollection{}, false, err
+	}
+
+	properties := featureCollection.Features[0].Properties
+
+	runtime.LogInfo(app.ctx, "Feature properties: "+fmt.Sprint(properties))
+
+	if ttl > 0 {
+		params := QueryParams{Province: properties.Il, District: properties.Ilce, Neighborhood: properties.Mahalle, Block: properties.Ada, Parcel: properties.ParselNo}
+		if err := getParselCache().Put(params, featureCollection); err != nil {
+			runtime.LogWarning(app.ctx, "Failed to cache parcel: "+err.Error())
+		}
+	}
+
+	return featureCollection, false, nil
+}
+
+// ParselSorguByCoordinate returns the parcel containing a point
+func (app *App) ParselSorguByCoordinate(point CoordinateQuery) (FeatureCollection, error) {
+	return app.queryParselAt(point, *config.ParselSorguHeadless)
+}
+
+// ParselSorguByPafta returns a parcel registered by pafta and parsel number, the pafta of the result
+// must match. The site can't search by pafta, so a parcel with only a pafta is looked up under
+// ada 0, where the registry files them, and one that also has an ada needs it in Block.
+func (app *App) ParselSorguByPafta(params PaftaQueryParams) (FeatureCollection, error) {
+	if strings.TrimSpace(params.Pafta) == "" || strings.TrimSpace(params.Parcel) == "" {
+		return FeatureCollection{}, newParselError(ParselErrorInvalid, nil, "pafta and parcel are required")
+	}
+
+	block := strings.TrimSpace(params.Block)
+	if block == "" {
+		block = "0"
+	}
+
+	collection, err := app.queryParsel(QueryParams{
+		Province:     params.Province,
+		District:     params.District,
+		Neighborhood: params.Neighborhood,
+		Block:        block,
+		Parcel:       params.Parcel,
+	}, *config.ParselSorguHeadless)
+
+	var parselErr *ParselError
+	if block == "0" && errors.As(err, &parselErr) && parselErr.Kind == ParselErrorNotFound {
+		return FeatureCollection{}, newParselError(ParselErrorNotFound, err, "parcel %s isn't filed under ada 0, a parcel that also has an ada can only be found with it", params.Parcel)
+	}
+	if err != nil {
+		return FeatureCollection{}, err
+	}
+
+	if pafta := collection.Features[0].Properties.Pafta; !samePafta(pafta, params.Pafta) {
+		return FeatureCollection{}, newParselError(ParselErrorNotFound, nil, "parcel %s is in pafta %s, not %s", params.Parcel, pafta, params.Pafta)
+	}
+
+	return collection, nil
+}
+
+// samePafta compares pafta references ignoring case and separators, "G22-B-12" equals "g22 b12"
+func samePafta(a, b string) bool {
+	normalize := func(pafta string) string {
+		return strings.ReplaceAll(foldAdminName(pafta), " ", "")
+	}
+
+	return normalize(a) != "" && normalize(a) == normalize(b)
+}
+
+// parseCoordinate reads a coordinate cell, with either a decimal point or a decimal comma
+func parseCoordinate(value string) (float64, error) {
+	value = strings.ReplaceAll(strings.TrimSpace(value), " ", "")
+
+	// 4.412.345,67 has dots as thousands separators
+	if strings.Contains(value, ",") {
+		value = strings.ReplaceAll(value, ".", "")
+		value = strings.ReplaceAll(value, ",", ".")
+	}
+
+	return strconv.ParseFloat(value, 64)
+}
+
+// coordinateQueryFromRow reads the point of a row from its X and Y columns
+func coordinateQueryFromRow(row []string, xIndex, yIndex int, crs string) (CoordinateQuery, error) {
+	if xIndex >= len(row) || yIndex >= len(row) || strings.TrimSpace(row[xIndex]) == "" || strings.TrimSpace(row[yIndex]) == "" {
+		return CoordinateQuery{}, newParselError(ParselErrorInvalid, nil, "coordinates are required")
+	}
+
+	x, err := parseCoordinate(row[xIndex])
+	if err != nil {
+		return CoordinateQuery{}, newParselError(ParselErrorInvalid, err, "invalid coordinate %s", row[xIndex])
+	}
+
+	y, err := parseCoordinate(row[yIndex])
+	if err != nil {
+		return CoordinateQuery{}, newParselError(ParselErrorInvalid, err, "invalid coordinate %s", row[yIndex])
+	}
+
+	return CoordinateQuery{X: x, Y: y, CRS: crs}, nil
+}
+
+// AddParselFieldsFromCoordinates fills the il, ilçe, mahalle, ada and parsel columns of every row
+// from the parcel containing the point in its coordinate columns. Ada and parsel columns are
+// added when missing, the others are only filled if they exist.
+func (app *App) AddParselFieldsFromCoordinates(excelPath, xHeader, yHeader, crs, ilHeader, ilceHeader, mahalleHeader, adaHeader, parselHeader string, headless bool) error {
+	defer closeParselSorgu()
+
+	if err := checkExcelSavable(excelPath); err != nil {
+		runtime.LogError(app.ctx, err.Error())
+		app.SendNotification("", excelSaveError(err), "", "error")
+		return err
+	}
+
+	checkpoint, readPath := newExcelCheckpoint(ExcelTaskParselPoint, excelPath)
+
+	headers, rows, excel, err := ReadExcel(readPath)
+	if err != nil {
+		runtime.LogError(app.ctx, err.Error())
+		return err
+	}
+
+	sheetName := excel.GetSheetList()[0]
+
+	xIndex, yIndex := headerIndex(headers, xHeader), headerIndex(headers, yHeader)
+	if xIndex == -1 || yIndex == -1 {
+		err := fmt.Errorf("coordinate columns %s and %s not found", xHeader, yHeader)
+		runtime.LogError(app.ctx, err.Error())
+		return err
+	}
+
+	if _, err := parseCRS(crs); err != nil {
+		runtime.LogError(app.ctx, err.Error())
+		return err
+	}
+
+	adaIndex, err := ensureColumn(excel, sheetName, &headers, adaHeader)
+	if err != nil {
+		runtime.LogError(app.ctx, err.Error())
+		return err
+	}
+
+	parselIndex, err := ensureColumn(excel, sheetName, &headers, parselHeader)
+	if err != nil {
+		runtime.LogError(app.ctx, err.Error())
+		return err
+	}
+
+	optionalIndex := func(header string) int {
+		if header == "" {
+			return -1
+		}
+		return headerIndex(headers, header)
+	}
+
+	ilIndex, ilceIndex, mahalleIndex := optionalIndex(ilHeader), optionalIndex(ilceHeader), optionalIndex(mahalleHeader)
+
+	statusIndex := -1
+	if *config.ParselStatusCellName != "" {
+		statusIndex, err = ensureColumn(excel, sheetName, &headers, *config.ParselStatusCellName)
+		if err != nil {
+			runtime.LogError(app.ctx, err.Error())
+			return err
+		}
+	}
+
+	writer, err := newExcelCellWriter(excel, sheetName, headers)
+	if err != nil {
+		runtime.LogError(app.ctx, err.Error())
+		return err
+	}
+
+	var pending []int
+	for i, row := range rows {
+		filled := adaIndex < len(row) && parselIndex < len(row) && strings.TrimSpace(row[adaIndex]) != "" && strings.TrimSpace(row[parselIndex]) != ""
+		if checkpoint.isDone(i) || (*config.ExcelResume && (parselStatusDone(row, statusIndex) || filled)) {
+			continue
+		}
+		pending = append(pending, i)
+	}
+	skipped := len(rows) - len(pending)
+
+	progress := &parselProgress{total: len(pending)}
+
+	for _, batch := range checkpoint.batches(pending) {
+		results := app.queryParselRows(batch, progress, func(i int) (FeatureCollection, bool, error) {
+			point, err := coordinateQueryFromRow(rows[i], xIndex, yIndex, crs)
+			if err != nil {
+				return FeatureCollection{}, false, err
+			}
+
+			return app.queryParselAtCached(point, headless)
+		})
+
+		for j, i := range batch {
+			result := results[j]
+
+			if statusIndex != -1 {
+				statusCell, _ := excelize.CoordinatesToCellName(statusIndex+1, i+2)
+				if err := excel.SetCellStr(sheetName, statusCell, parselStatus(result.err, result.cached)); err != nil {
+					runtime.LogError(app.ctx, err.Error())
+				}
+			}
+
+			if result.err != nil {
+				continue
+			}
+
+			properties := result.collection.Features[0].Properties
+
+			for _, column := range []struct {
+				index int
+				value string
+			}{
+				{ilIndex, properties.Il},
+				{ilceIndex, properties.Ilce},
+				{mahalleIndex, properties.Mahalle},
+				{adaIndex, properties.Ada},
+				{parselIndex, properties.ParselNo},
+			} {
+				if column.index == -1 {
+					continue
+				}
+
+				// Ada and parsel numbers stay numbers like the rest of the column
+				var value interface{} = column.value
+				if number, ok := excelNumberText(column.value); ok {
+					value = number
+				}
+				if err := writer.set(column.index, i+2, value); err != nil {
+					runtime.LogError(app.ctx, err.Error())
+					return err
+				}
+			}
+
+			if err := checkpoint.markDone(excel, i); err != nil {
+				runtime.LogError(app.ctx, err.Error())
+				app.SendNotification("", excelSaveError(err), "", "error")
+				return err
+			}
+		}
+	}
+
+	savedPath, err := checkpoint.finish(excel)
+
+	if err != nil {
+		runtime.LogError(app.ctx, err.Error())
+		app.SendNotification("", excelSaveError(err), "", "error")
+		return err
+	}
+
+	app.SendNotification("Excel dosyası başarıyla güncellendi", resumeMessage(skipped), strings.ReplaceAll(savedPath, "\\", "\\\\"), "success")
+
+	runtime.WindowExecJS(appContext, `window.setParselMessage("Excel dosyası başarıyla güncellendi`+resumeMessage(skipped)+`");`)
+
+	return nil
+}
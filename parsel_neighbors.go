@@ -0,0 +1,413 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/wailsapp/wails/v2/pkg/runtime"
+)
+
+const (
+	// Edges closer than this in metres are treated as the same boundary line
+	neighborTolerance = 0.5
+	// Parcels touching along less than this in metres, like at a corner, aren't neighbors
+	neighborMinShared = 0.5
+	// Distance in metres outside an edge where the parcel across it is looked up
+	neighborProbeOffset = 1.5
+	// Every probe is a rate limited query, 8 covers the longest edges of most parcels
+	neighborMaxProbes = 8
+)
+
+// ParselNeighbor is a parcel sharing part of its boundary with the queried one
+type ParselNeighbor struct {
+	Il           string  `json:"il"`
+	Ilce         string  `json:"ilce"`
+	Mahalle      string  `json:"mahalle"`
+	Ada          string  `json:"ada"`
+	Parsel       string  `json:"parsel"`
+	SharedLength float64 `json:"sharedLength"` // m
+}
+
+// parselNeighborMatch is a neighbor with its geometry, for drawing
+type parselNeighborMatch struct {
+	ParselNeighbor
+	feature Feature
+}
+
+// neighborCandidate is a parcel that may be adjacent, in the subject's projected system
+type neighborCandidate struct {
+	feature   Feature
+	projected [][][]float64
+}
+
+func parselFeatureKey(properties Properties) string {
+	return foldAdminName(properties.Il + " " + properties.Ilce + " " + properties.Mahalle + " " + properties.Ada + " " + properties.ParselNo)
+}
+
+// findParselNeighbors returns the parcels sharing an edge with the collection's parcel, sorted by
+// ada and parsel. Candidates come from the parcel cache and, unless probe is nil, from looking up
+// the parcel just outside each edge, which also finds neighbors in other adas. Known parcels,
+// like the other rows of a sheet, are candidates too.
+func findParselNeighbors(collection FeatureCollection, known []FeatureCollection, probe func(point CoordinateQuery) (FeatureCollection, error)) ([]parselNeighborMatch, error) {
+	if len(collection.Features) == 0 {
+		return nil, fmt.Errorf("no geometry to find neighbors of")
+	}
+	if len(collection.Features[0].Geometry.Coordinates) == 0 {
+		return nil, fmt.Errorf("parcel has no polygon")
+	}
+
+	target, err := resolveTargetCRS("auto", collection)
+	if err != nil {
+		return nil, err
+	}
+
+	subject, err := transformCollection(collection, target)
+	if err != nil {
+		return nil, err
+	}
+
+	subjectKey := parselFeatureKey(collection.Features[0].Properties)
+	subjectRing := openRing(subject.Features[0].Geometry.Coordinates[0])
+	if len(subjectRing) < 3 {
+		return nil, fmt.Errorf("parcel has no polygon")
+	}
+
+	candidates := make(map[string]neighborCandidate)
+
+	// About 10 m around the parcel in degrees, to skip far parcels before projecting them
+	const margin = 0.0001
+	minLon, minLat, maxLon, maxLat := collectionBounds(collection)
+
+	addCandidates := func(candidate FeatureCollection) {
+		cMinLon, cMinLat, cMaxLon, cMaxLat := collectionBounds(candidate)
+		if cMinLon > maxLon+margin || cMaxLon < minLon-margin || cMinLat > maxLat+margin || cMaxLat < minLat-margin {
+			return
+		}
+
+		projected, err := transformCollection(candidate, target)
+		if err != nil {
+			return
+		}
+
+		for i, feature := range candidate.Features {
+			key := parselFeatureKey(feature.Properties)
+			if key == subjectKey || len(projected.Features[i].Geometry.Coordinates) == 0 {
+				continue
+			}
+			candidates[key] = neighborCandidate{feature: feature, projected: projected.Features[i].Geometry.Coordinates}
+		}
+	}
+
+	for _, candidate := range known {
+		addCandidates(candidate)
+	}
+
+	if ttl := parselCacheTTL(); ttl > 0 {
+		entries, err := getParselCache().Entries()
+		if err != nil {
+			runtime.LogWarning(appContext, "Failed to read parcel cache: "+err.Error())
+		}
+
+		for _, entry := range entries {
+			if time.Since(entry.FetchedAt) <= ttl {
+				addCandidates(entry.Collection)
+			}
+		}
+	}
+
+	if probe != nil {
+		probes := 0
+
+		for _, point := range neighborProbePoints(subjectRing) {
+			if probes == neighborMaxProbes {
+				break
+			}
+
+			// A parcel already known covers this side
+			covered := false
+			for _, candidate := range candidates {
+				if pointInPolygon(point, candidate.projected) {
+					covered = true
+					break
+				}
+			}
+			if covered {
+				continue
+			}
+
+			probes++
+
+			lon, lat, err := toWGS84(target, point[0], point[1])
+			if err != nil {
+				continue
+			}
+
+			found, err := probe(CoordinateQuery{X: lon, Y: lat})
+			if err != nil {
+				// Roads and rivers have no parcel
+				continue
+			}
+
+			addCandidates(found)
+		}
+	}
+
+	var neighbors []parselNeighborMatch
+
+	for _, candidate := range candidates {
+		shared := 0.0
+		for _, ring := range candidate.projected {
+			shared += sharedBoundaryLength(subjectRing, openRing(ring))
+		}
+
+		if shared < neighborMinShared {
+			continue
+		}
+
+		properties := candidate.feature.Properties
+		neighbors = append(neighbors, parselNeighborMatch{
+			ParselNeighbor: ParselNeighbor{
+				Il:           properties.Il,
+				Ilce:         properties.Ilce,
+				Mahalle:      properties.Mahalle,
+				Ada:          properties.Ada,
+				Parsel:       properties.ParselNo,
+				SharedLength: math.Round(shared*100) / 100,
+			},
+			feature: candidate.feature,
+		})
+	}
+
+	sort.Slice(neighbors, func(i, j int) bool {
+		a, b := neighbors[i].ParselNeighbor, neighbors[j].ParselNeighbor
+		if a.Mahalle != b.Mahalle {
+			return a.Mahalle < b.Mahalle
+		}
+		if a.Ada != b.Ada {
+			return naturalLess(a.Ada, b.Ada)
+		}
+		return naturalLess(a.Parsel, b.Parsel)
+	})
+
+	return neighbors, nil
+}
+
+// naturalLess orders numbers by value and anything else alphabetically
+func naturalLess(a, b string) bool {
+	var x, y int
+	_, errA := fmt.Sscan(a, &x)
+	_, errB := fmt.Sscan(b, &y)
+	if errA == nil && errB == nil && x != y {
+		return x < y
+	}
+	return a < b
+}
+
+// collectionBounds returns the bounding box of every ring of the collection
+func collectionBounds(collection FeatureCollection) (float64, float64, float64, float64) {
+	minX, minY, maxX, maxY := math.Inf(1), math.Inf(1), math.Inf(-1), math.Inf(-1)
+	for _, feature := range collection.Features {
+		for _, ring := range feature.Geometry.Coordinates {
+			for _, point := range ring {
+				if len(point) < 2 {
+					continue
+				}
+				minX, maxX = min(minX, point[0]), max(maxX, point[0])
+				minY, maxY = min(minY, point[1]), max(maxY, point[1])
+			}
+		}
+	}
+	return minX, minY, maxX, maxY
+}
+
+// neighborProbePoints are points just outside the middle of each edge of an open ring, longest
+// edges first since they border the most
+func neighborProbePoints(ring [][]float64) [][]float64 {
+	area, _, _ := ringAreaCentroid(ring)
+
+	type probe struct {
+		point  []float64
+		length float64
+	}
+	var probes []probe
+
+	for i := range ring {
+		a, b := ring[i], ring[(i+1)%len(ring)]
+		dx, dy := b[0]-a[0], b[1]-a[1]
+
+		length := math.Hypot(dx, dy)
+		if length < 2*neighborProbeOffset {
+			continue
+		}
+
+		// Outward normal, counter-clockwise rings have the outside on the right
+		nx, ny := dy/length, -dx/length
+		if area < 0 {
+			nx, ny = -nx, -ny
+		}
+
+		probes = append(probes, probe{
+			point:  []float64{(a[0]+b[0])/2 + nx*neighborProbeOffset, (a[1]+b[1])/2 + ny*neighborProbeOffset},
+			length: length,
+		})
+	}
+
+	sort.SliceStable(probes, func(i, j int) bool {
+		return probes[i].length > probes[j].length
+	})
+
+	points := make([][]float64, len(probes))
+	for i, probe := range probes {
+		points[i] = probe.point
+	}
+
+	return points
+}
+
+// pointInPolygon tests point against the rings of a polygon, holes excluded
+func pointInPolygon(point []float64, rings [][][]float64) bool {
+	inside := false
+
+	for _, ring := range rings {
+		ring = openRing(ring)
+		for i, j := 0, len(ring)-1; i < len(ring); j, i = i, i+1 {
+			a, b := ring[i], ring[j]
+			if (a[1] > point[1]) != (b[1] > point[1]) && point[0] < (b[0]-a[0])*(point[1]-a[1])/(b[1]-a[1])+a[0] {
+				inside = !inside
+			}
+		}
+	}
+
+	return inside
+}
+
+// sharedBoundaryLength sums how far the edges of two open rings run along each other
+func sharedBoundaryLength(a, b [][]float64) float64 {
+	shared := 0.0
+
+	for i := range a {
+		p, q := a[i], a[(i+1)%len(a)]
+		dx, dy := q[0]-p[0], q[1]-p[1]
+
+		length := math.Hypot(dx, dy)
+		if length == 0 {
+			continue
+		}
+		ux, uy := dx/length, dy/length
+
+		for j := range b {
+			r, s := b[j], b[(j+1)%len(b)]
+
+			// Both ends of the other edge on this edge's line
+			if math.Abs((r[0]-p[0])*uy-(r[1]-p[1])*ux) > neighborTolerance || math.Abs((s[0]-p[0])*uy-(s[1]-p[1])*ux) > neighborTolerance {
+				continue
+			}
+
+			// Overlap of the two edges along the line
+			t1 := (r[0]-p[0])*ux + (r[1]-p[1])*uy
+			t2 := (s[0]-p[0])*ux + (s[1]-p[1])*uy
+			overlap := min(max(t1, t2), length) - max(min(t1, t2), 0)
+			if overlap > 0 {
+				shared += overlap
+			}
+		}
+	}
+
+	return shared
+}
+
+// formatParselNeighbors lists neighbors like "123/5, 123/6", prefixed with the mahalle when it
+// differs from the parcel's
+func formatParselNeighbors(neighbors []parselNeighborMatch, mahalle string) string {
+	labels := make([]string, len(neighbors))
+	for i, neighbor := range neighbors {
+		labels[i] = neighbor.Ada + "/" + neighbor.Parsel
+		if neighbor.Mahalle != "" && foldAdminName(neighbor.Mahalle) != foldAdminName(mahalle) {
+			labels[i] = neighbor.Mahalle + " " + labels[i]
+		}
+	}
+	return strings.Join(labels, ", ")
+}
+
+// cachedNeighborFeatures returns the geometries of the neighbors already in the cache, for drawing
+func cachedNeighborFeatures(collection FeatureCollection) []Feature {
+	neighbors, err := findParselNeighbors(collection, nil, nil)
+	if err != nil {
+		return nil
+	}
+
+	features := make([]Feature, len(neighbors))
+	for i, neighbor := range neighbors {
+		features[i] = neighbor.feature
+	}
+	return features
+}
+
+// neighborProbe looks up parcels around a parcel if probing is enabled, nil otherwise
+func (app *App) neighborProbe(headless bool) func(point CoordinateQuery) (FeatureCollection, error) {
+	if !*config.ParselNeighborProbe {
+		return nil
+	}
+
+	return func(point CoordinateQuery) (FeatureCollection, error) {
+		return app.queryParselAt(point, headless)
+	}
+}
+
+// findParselRowNeighbors lists the neighbors of every queried row. The parcels of the rows and
+// those in known, like earlier batches of the run, are checked before any parcel around them is
+// queried.
+func (app *App) findParselRowNeighbors(results []parselRowResult, known []FeatureCollection, headless bool) []string {
+	for _, result := range results {
+		if result.err == nil {
+			known = append(known, result.collection)
+		}
+	}
+
+	probe := app.neighborProbe(headless)
+
+	indexes := make([]int, len(results))
+	for i := range indexes {
+		indexes[i] = i
+	}
+
+	return runParselRows(indexes, nil, func(i int) string {
+		if results[i].err != nil {
+			return ""
+		}
+
+		collection := results[i].collection
+
+		neighbors, err := findParselNeighbors(collection, known, probe)
+		if err != nil {
+			runtime.LogError(app.ctx, err.Error())
+			return ""
+		}
+
+		return formatParselNeighbors(neighbors, collection.Features[0].Properties.Mahalle)
+	})
+}
+
+// FindParselNeighbors queries a parcel and returns the parcels sharing an edge with it
+func (app *App) FindParselNeighbors(params QueryParams) ([]ParselNeighbor, error) {
+	collection, err := app.queryParsel(params, *config.ParselSorguHeadless)
+	if err != nil {
+		return nil, err
+	}
+
+	matches, err := findParselNeighbors(collection, nil, app.neighborProbe(*config.ParselSorguHeadless))
+	if err != nil {
+		runtime.LogError(app.ctx, err.Error())
+		return nil, err
+	}
+
+	neighbors := make([]ParselNeighbor, len(matches))
+	for i, match := range matches {
+		neighbors[i] = match.ParselNeighbor
+	}
+
+	return neighbors, nil
+}
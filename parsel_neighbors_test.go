@@ -0,0 +1,108 @@
+package main
+
+import (
+	"math"
+	"reflect"
+	"testing"
+)
+
+func TestFindParselNeighborsWithoutPolygon(t *testing.T) {
+	collection := FeatureCollection{Features: []Feature{
+		{Type: "Feature"},
+		{Type: "Feature", Geometry: Geometry{Type: "Polygon", Coordinates: [][][]float64{{{32.48, 37.95}, {32.49, 37.95}, {32.49, 37.96}, {32.48, 37.95}}}}},
+	}}
+	collection.CRS.Properties.Name = "EPSG:4326"
+
+	if _, err := findParselNeighbors(collection, nil, nil); err == nil {
+		t.Error("a parcel without a polygon should fail")
+	}
+}
+
+// square is the ring of a parcel with its lower left corner at x, y
+func square(x, y, size float64) [][]float64 {
+	return [][]float64{{x, y}, {x + size, y}, {x + size, y + size}, {x, y + size}}
+}
+
+func TestSharedBoundaryLength(t *testing.T) {
+	tests := []struct {
+		name string
+		b    [][]float64
+		want float64
+	}{
+		{"adjacent", square(10, 0, 10), 10},
+		{"offset along the edge", square(10, 4, 10), 6},
+		{"corner only", square(10, 10, 10), 0},
+		{"apart", square(12, 0, 10), 0},
+		{"within tolerance", square(10.3, 0, 10), 10},
+	}
+
+	for _, tt := range tests {
+		if got := sharedBoundaryLength(square(0, 0, 10), tt.b); math.Abs(got-tt.want) > 1e-9 {
+			t.Errorf("%s: shared length = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestNeighborProbePoints(t *testing.T) {
+	want := [][]float64{{10, -1.5}, {10, 3.5}}
+
+	// Counter-clockwise, the 2 m ends are too short to probe
+	if got := neighborProbePoints([][]float64{{0, 0}, {20, 0}, {20, 2}, {0, 2}}); !reflect.DeepEqual(got, want) {
+		t.Errorf("counter-clockwise probes = %v, want %v", got, want)
+	}
+
+	// Clockwise rings are probed on the outside too
+	got := neighborProbePoints([][]float64{{0, 2}, {20, 2}, {20, 0}, {0, 0}})
+	if want := [][]float64{{10, 3.5}, {10, -1.5}}; !reflect.DeepEqual(got, want) {
+		t.Errorf("clockwise probes = %v, want %v", got, want)
+	}
+
+	// Longest edges first
+	got = neighborProbePoints([][]float64{{0, 0}, {4, 0}, {4, 30}, {0, 30}})
+	if len(got) != 4 || got[0][1] != 15 || got[1][1] != 15 {
+		t.Errorf("probes = %v, want the 30 m edges first", got)
+	}
+}
+
+func TestFindParselNeighborsSkipsCorners(t *testing.T) {
+	saved := config.ParselCacheTTL
+	noCache := 0
+	config.ParselCacheTTL = &noCache
+	defer func() { config.ParselCacheTTL = saved }()
+
+	parcel := func(parsel string, lon, lat float64) FeatureCollection {
+		ring := square(lon, lat, 0.001)
+		ring = append(ring, ring[0])
+
+		collection := FeatureCollection{Type: "FeatureCollection", Features: []Feature{{
+			Type:       "Feature",
+			Geometry:   Geometry{Type: "Polygon", Coordinates: [][][]float64{ring}},
+			Properties: Properties{Il: "Konya", Ilce: "Meram", Mahalle: "Şehitler", Ada: "1", ParselNo: parsel},
+		}}}
+		collection.CRS.Properties.Name = "EPSG:4326"
+		return collection
+	}
+
+	subject := parcel("1", 32.480, 37.950)
+	known := []FeatureCollection{
+		parcel("2", 32.481, 37.950), // east side
+		parcel("3", 32.481, 37.951), // north east corner
+		parcel("4", 32.480, 37.951), // north side
+	}
+
+	neighbors, err := findParselNeighbors(subject, known, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var parcels []string
+	for _, neighbor := range neighbors {
+		parcels = append(parcels, neighbor.Parsel)
+		if neighbor.SharedLength < 80 {
+			t.Errorf("parcel %s shares %v m, want about a side of the parcel", neighbor.Parsel, neighbor.SharedLength)
+		}
+	}
+	if !reflect.DeepEqual(parcels, []string{"2", "4"}) {
+		t.Errorf("neighbors = %v, want [2 4]", parcels)
+	}
+}
@@ -0,0 +1,432 @@
+package main
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	"image/png"
+	"io"
+	"math"
+	"strings"
+	"sync"
+
+	"golang.org/x/image/font"
+	"golang.org/x/image/font/gofont/goregular"
+	"golang.org/x/image/font/opentype"
+	"golang.org/x/image/math/fixed"
+	"golang.org/x/image/vector"
+)
+
+const (
+	// Word templates show the sketch at {image:Kroki}
+	parselSketchPlaceholder = "Kroki"
+	parselSketchWidthCm     = 16
+
+	sketchWidth   = 1200
+	sketchHeight  = 900
+	sketchPadding = 40
+)
+
+var (
+	sketchBackground   = color.NRGBA{255, 255, 255, 255}
+	sketchSubjectFill  = color.NRGBA{244, 197, 66, 90}
+	sketchSubjectLine  = color.NRGBA{192, 57, 43, 255}
+	sketchNeighborLine = color.NRGBA{120, 120, 120, 255}
+	sketchText         = color.NRGBA{0, 0, 0, 255}
+)
+
+// sketchParcel is a parcel in pixel coordinates
+type sketchParcel struct {
+	rings          [][][2]float64
+	label          string
+	labelX, labelY float64
+	subject        bool
+}
+
+// parselSketch is a location sketch of a parcel and its neighbors, north up, ready to be
+// written as SVG or PNG
+type parselSketch struct {
+	width, height int
+	parcels       []sketchParcel // neighbors first so the subject is drawn on top
+
+	scaleBarMetres float64
+	scaleBarPixels float64
+}
+
+// newParselSketch fits the parcels of collection into the sketch with room around them for
+// the neighbors, which are only drawn where they fall inside
+func newParselSketch(collection FeatureCollection, neighbors []Feature) (parselSketch, error) {
+	if len(collection.Features) == 0 {
+		return parselSketch{}, fmt.Errorf("no geometry to draw")
+	}
+
+	target, err := resolveTargetCRS(*config.ParselCRS, collection)
+	if err != nil {
+		return parselSketch{}, err
+	}
+	if !target.projected {
+		target, err = resolveTargetCRS("auto", collection)
+		if err != nil {
+			return parselSketch{}, err
+		}
+	}
+
+	subject, err := transformCollection(collection, target)
+	if err != nil {
+		return parselSketch{}, err
+	}
+
+	around := collection
+	around.Features = neighbors
+	around, err = transformCollection(around, target)
+	if err != nil {
+		return parselSketch{}, err
+	}
+
+	minX, minY, maxX, maxY := math.Inf(1), math.Inf(1), math.Inf(-1), math.Inf(-1)
+	for _, feature := range subject.Features {
+		for _, ring := range feature.Geometry.Coordinates {
+			for _, point := range ring {
+				if len(point) < 2 {
+					continue
+				}
+				minX, maxX = min(minX, point[0]), max(maxX, point[0])
+				minY, maxY = min(minY, point[1]), max(maxY, point[1])
+			}
+		}
+	}
+	if math.IsInf(minX, 0) {
+		return parselSketch{}, fmt.Errorf("parcel has no polygon")
+	}
+
+	// Show the surroundings, at least 50 m across
+	midX, midY := (minX+maxX)/2, (minY+maxY)/2
+	viewWidth := max((maxX-minX)*1.6, 50)
+	viewHeight := max((maxY-minY)*1.6, 50)
+
+	sketch := parselSketch{width: sketchWidth, height: sketchHeight}
+	scale := min(float64(sketch.width-2*sketchPadding)/viewWidth, float64(sketch.height-2*sketchPadding)/viewHeight)
+
+	toPixel := func(point []float64) [2]float64 {
+		return [2]float64{
+			float64(sketch.width)/2 + (point[0]-midX)*scale,
+			float64(sketch.height)/2 - (point[1]-midY)*scale,
+		}
+	}
+
+	add := func(feature Feature, isSubject bool) {
+		parcel := sketchParcel{label: parselLabel(feature.Properties), subject: isSubject}
+
+		for i, ring := range feature.Geometry.Coordinates {
+			pixels := make([][2]float64, 0, len(ring))
+			for _, point := range openRing(ring) {
+				if len(point) >= 2 {
+					pixels = append(pixels, toPixel(point))
+				}
+			}
+			if len(pixels) < 3 {
+				continue
+			}
+
+			if i == 0 {
+				_, cx, cy := ringAreaCentroid(openRing(ring))
+				label := toPixel([]float64{cx, cy})
+				parcel.labelX, parcel.labelY = label[0], label[1]
+
+				// Neighbors reaching out of the sketch aren't labelled
+				if label[0] < sketchPadding || label[0] > float64(sketch.width-sketchPadding) || label[1] < sketchPadding || label[1] > float64(sketch.height-sketchPadding) {
+					parcel.label = ""
+				}
+			}
+
+			parcel.rings = append(parcel.rings, pixels)
+		}
+
+		if len(parcel.rings) > 0 {
+			sketch.parcels = append(sketch.parcels, parcel)
+		}
+	}
+
+	for _, feature := range around.Features {
+		add(feature, false)
+	}
+	for _, feature := range subject.Features {
+		add(feature, true)
+	}
+
+	sketch.scaleBarMetres = niceLength(viewWidth / 4)
+	sketch.scaleBarPixels = sketch.scaleBarMetres * scale
+
+	return sketch, nil
+}
+
+// niceLength rounds length down to 1, 2 or 5 times a power of ten
+func niceLength(length float64) float64 {
+	magnitude := math.Pow(10, math.Floor(math.Log10(length)))
+	for _, step := range []float64{5, 2, 1} {
+		if step*magnitude <= length {
+			return step * magnitude
+		}
+	}
+	return magnitude
+}
+
+func formatMetres(metres float64) string {
+	if metres >= 1000 {
+		return fmt.Sprintf("%g km", metres/1000)
+	}
+	return fmt.Sprintf("%g m", metres)
+}
+
+// northArrow returns the arrow's triangle and the position of its "K" label in the top right corner
+func (s parselSketch) northArrow() ([][2]float64, float64, float64) {
+	x := float64(s.width - sketchPadding - 20)
+	y := float64(sketchPadding + 30)
+
+	return [][2]float64{{x, y - 30}, {x + 14, y + 20}, {x, y + 10}, {x - 14, y + 20}}, x, y + 45
+}
+
+// scaleBar returns the bar's left, top, width and height in the bottom left corner
+func (s parselSketch) scaleBar() (float64, float64, float64, float64) {
+	return sketchPadding, float64(s.height - sketchPadding - 10), s.scaleBarPixels, 8
+}
+
+// insertParselSketch draws the parcel into the document's {image:Kroki} placeholders, the parcel
+// is only queried when the document has one
+func insertParselSketch(documentPath string, parcel func() (FeatureCollection, error)) error {
+	found, err := docxHasImagePlaceholder(documentPath, parselSketchPlaceholder)
+	if err != nil || !found {
+		return err
+	}
+
+	collection, err := parcel()
+	if err != nil {
+		return err
+	}
+
+	sketch, err := newParselSketch(collection, cachedNeighborFeatures(collection))
+	if err != nil {
+		return err
+	}
+
+	var buffer bytes.Buffer
+	if err := writeParselSketchPNG(&buffer, sketch); err != nil {
+		return err
+	}
+
+	return insertDocxImage(documentPath, parselSketchPlaceholder, buffer.Bytes(), parselSketchWidthCm)
+}
+
+func writeParselSketchSVG(w io.Writer, sketch parselSketch) error {
+	var b strings.Builder
+
+	fmt.Fprintf(&b, `<?xml version="1.0" encoding="UTF-8"?>`+"\n")
+	fmt.Fprintf(&b, `<svg xmlns="http://www.w3.org/2000/svg" width="%d" height="%d" viewBox="0 0 %d %d">`+"\n", sketch.width, sketch.height, sketch.width, sketch.height)
+	fmt.Fprintf(&b, `<rect width="100%%" height="100%%" fill="%s"/>`+"\n", svgColor(sketchBackground))
+
+	for _, parcel := range sketch.parcels {
+		var d strings.Builder
+		for _, ring := range parcel.rings {
+			for i, point := range ring {
+				if i == 0 {
+					fmt.Fprintf(&d, "M%.1f %.1f", point[0], point[1])
+				} else {
+					fmt.Fprintf(&d, "L%.1f %.1f", point[0], point[1])
+				}
+			}
+			d.WriteString("Z")
+		}
+
+		if parcel.subject {
+			fmt.Fprintf(&b, `<path d="%s" fill="%s" fill-opacity="%.2f" fill-rule="evenodd" stroke="%s" stroke-width="3"/>`+"\n", d.String(), svgColor(sketchSubjectFill), float64(sketchSubjectFill.A)/255, svgColor(sketchSubjectLine))
+		} else {
+			fmt.Fprintf(&b, `<path d="%s" fill="none" stroke="%s" stroke-width="1.5"/>`+"\n", d.String(), svgColor(sketchNeighborLine))
+		}
+	}
+
+	for _, parcel := range sketch.parcels {
+		if parcel.label == "" {
+			continue
+		}
+
+		size, weight := 16, "normal"
+		if parcel.subject {
+			size, weight = 22, "bold"
+		}
+		fmt.Fprintf(&b, `<text x="%.1f" y="%.1f" font-family="sans-serif" font-size="%d" font-weight="%s" text-anchor="middle" dominant-baseline="middle">%s</text>`+"\n", parcel.labelX, parcel.labelY, size, weight, xmlEscape(parcel.label))
+	}
+
+	arrow, labelX, labelY := sketch.northArrow()
+	fmt.Fprintf(&b, `<polygon points="%s" fill="%s"/>`+"\n", svgPoints(arrow), svgColor(sketchText))
+	fmt.Fprintf(&b, `<text x="%.1f" y="%.1f" font-family="sans-serif" font-size="20" font-weight="bold" text-anchor="middle" dominant-baseline="middle">K</text>`+"\n", labelX, labelY)
+
+	x, y, width, height := sketch.scaleBar()
+	fmt.Fprintf(&b, `<rect x="%.1f" y="%.1f" width="%.1f" height="%.1f" fill="none" stroke="%s"/>`+"\n", x, y, width, height, svgColor(sketchText))
+	fmt.Fprintf(&b, `<rect x="%.1f" y="%.1f" width="%.1f" height="%.1f" fill="%s"/>`+"\n", x, y, width/2, height, svgColor(sketchText))
+	fmt.Fprintf(&b, `<text x="%.1f" y="%.1f" font-family="sans-serif" font-size="14" text-anchor="middle">0</text>`+"\n", x, y-6)
+	fmt.Fprintf(&b, `<text x="%.1f" y="%.1f" font-family="sans-serif" font-size="14" text-anchor="middle">%s</text>`+"\n", x+width, y-6, formatMetres(sketch.scaleBarMetres))
+
+	b.WriteString("</svg>\n")
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+func svgColor(c color.NRGBA) string {
+	return fmt.Sprintf("#%02x%02x%02x", c.R, c.G, c.B)
+}
+
+func svgPoints(points [][2]float64) string {
+	parts := make([]string, len(points))
+	for i, point := range points {
+		parts[i] = fmt.Sprintf("%.1f,%.1f", point[0], point[1])
+	}
+	return strings.Join(parts, " ")
+}
+
+func xmlEscape(text string) string {
+	var b strings.Builder
+	xml.EscapeText(&b, []byte(text))
+	return b.String()
+}
+
+var (
+	sketchFontOnce sync.Once
+	sketchFont     *opentype.Font
+	sketchFontErr  error
+)
+
+func sketchFace(size float64) (font.Face, error) {
+	sketchFontOnce.Do(func() {
+		sketchFont, sketchFontErr = opentype.Parse(goregular.TTF)
+	})
+	if sketchFontErr != nil {
+		return nil, sketchFontErr
+	}
+
+	return opentype.NewFace(sketchFont, &opentype.FaceOptions{Size: size, DPI: 72, Hinting: font.HintingFull})
+}
+
+func writeParselSketchPNG(w io.Writer, sketch parselSketch) error {
+	img := image.NewRGBA(image.Rect(0, 0, sketch.width, sketch.height))
+	draw.Draw(img, img.Bounds(), image.NewUniform(sketchBackground), image.Point{}, draw.Src)
+
+	for _, parcel := range sketch.parcels {
+		if parcel.subject {
+			fillPolygons(img, parcel.rings, sketchSubjectFill)
+			strokeRings(img, parcel.rings, 3, sketchSubjectLine)
+		} else {
+			strokeRings(img, parcel.rings, 1.5, sketchNeighborLine)
+		}
+	}
+
+	for _, parcel := range sketch.parcels {
+		if parcel.label == "" {
+			continue
+		}
+
+		size := 16.0
+		if parcel.subject {
+			size = 22
+		}
+		if err := drawSketchText(img, parcel.label, parcel.labelX, parcel.labelY, size); err != nil {
+			return err
+		}
+	}
+
+	arrow, labelX, labelY := sketch.northArrow()
+	fillPolygons(img, [][][2]float64{arrow}, sketchText)
+	if err := drawSketchText(img, "K", labelX, labelY, 20); err != nil {
+		return err
+	}
+
+	x, y, width, height := sketch.scaleBar()
+	fillPolygons(img, [][][2]float64{{{x, y}, {x + width/2, y}, {x + width/2, y + height}, {x, y + height}}}, sketchText)
+	strokeRings(img, [][][2]float64{{{x, y}, {x + width, y}, {x + width, y + height}, {x, y + height}}}, 1, sketchText)
+	if err := drawSketchText(img, "0", x, y-14, 14); err != nil {
+		return err
+	}
+	if err := drawSketchText(img, formatMetres(sketch.scaleBarMetres), x+width, y-14, 14); err != nil {
+		return err
+	}
+
+	return png.Encode(w, img)
+}
+
+// fillPolygons fills the rings together so holes stay empty, rasterizing only their bounding box
+func fillPolygons(img *image.RGBA, rings [][][2]float64, c color.NRGBA) {
+	minX, minY, maxX, maxY := math.Inf(1), math.Inf(1), math.Inf(-1), math.Inf(-1)
+	for _, ring := range rings {
+		for _, point := range ring {
+			minX, maxX = min(minX, point[0]), max(maxX, point[0])
+			minY, maxY = min(minY, point[1]), max(maxY, point[1])
+		}
+	}
+
+	bounds := image.Rect(int(math.Floor(minX)), int(math.Floor(minY)), int(math.Ceil(maxX))+1, int(math.Ceil(maxY))+1).Intersect(img.Bounds())
+	if bounds.Empty() {
+		return
+	}
+
+	r := vector.NewRasterizer(bounds.Dx(), bounds.Dy())
+	offsetX, offsetY := float64(bounds.Min.X), float64(bounds.Min.Y)
+
+	for _, ring := range rings {
+		r.MoveTo(float32(ring[0][0]-offsetX), float32(ring[0][1]-offsetY))
+		for _, point := range ring[1:] {
+			r.LineTo(float32(point[0]-offsetX), float32(point[1]-offsetY))
+		}
+		r.ClosePath()
+	}
+
+	r.Draw(img, bounds, image.NewUniform(c), image.Point{})
+}
+
+// strokeRings draws the outline of closed rings as one quad per edge
+func strokeRings(img *image.RGBA, rings [][][2]float64, width float64, c color.NRGBA) {
+	for _, ring := range rings {
+		for i := range ring {
+			a, b := ring[i], ring[(i+1)%len(ring)]
+
+			length := math.Hypot(b[0]-a[0], b[1]-a[1])
+			if length == 0 {
+				continue
+			}
+
+			// Extend each edge by half the width so corners meet
+			dx, dy := (b[0]-a[0])/length*width/2, (b[1]-a[1])/length*width/2
+			nx, ny := -dy, dx
+
+			fillPolygons(img, [][][2]float64{{
+				{a[0] - dx + nx, a[1] - dy + ny},
+				{b[0] + dx + nx, b[1] + dy + ny},
+				{b[0] + dx - nx, b[1] + dy - ny},
+				{a[0] - dx - nx, a[1] - dy - ny},
+			}}, c)
+		}
+	}
+}
+
+// drawSketchText draws text centered on x, y
+func drawSketchText(img *image.RGBA, text string, x, y, size float64) error {
+	face, err := sketchFace(size)
+	if err != nil {
+		return err
+	}
+	defer face.Close()
+
+	drawer := font.Drawer{Dst: img, Src: image.NewUniform(sketchText), Face: face}
+	metrics := face.Metrics()
+
+	width := drawer.MeasureString(text)
+	drawer.Dot = fixed.Point26_6{
+		X: fixed.Int26_6(x*64) - width/2,
+		Y: fixed.Int26_6(y*64) + (metrics.Ascent-metrics.Descent)/2,
+	}
+	drawer.DrawString(text)
+
+	return nil
+}
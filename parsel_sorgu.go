@@ -4,18 +4,12 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
-	"log"
-	"os"
-	"path"
-	"path/filepath"
-	"sort"
-	"strconv"
 	"strings"
-	"time"
+	"sync"
+	"sync/atomic"
 
-	"github.com/chromedp/cdproto/browser"
-	"github.com/chromedp/chromedp"
 	"github.com/wailsapp/wails/v2/pkg/runtime"
+	"github.com/xuri/excelize/v2"
 	"golang.org/x/text/cases"
 	"golang.org/x/text/language"
 )
@@ -56,371 +50,313 @@ type Properties struct {
 	Ilce     string `json:"Ilce"`
 	Pafta    string `json:"Pafta"`
 	Mahalle  string `json:"Mahalle"`
-}
 
-type QueryParams struct {
-	Province     string `json:"province"`
-	District     string `json:"district"`
-	Neighborhood string `json:"neighborhood"`
-	Block        string `json:"block"`
-	Parcel       string `json:"parcel"`
+	// Extra holds the properties without a field above by the name the source used, so
+	// they can be mapped to columns too
+	Extra map[string]interface{} `json:"-"`
 }
 
-var parselSorguContext context.Context
-var close1 context.CancelFunc
-var close2 context.CancelFunc
-var close3 context.CancelFunc
-var downloadDir string
-
-func (a *App) InitParselSorgu(headless bool) error {
-	downloadDir = filepath.Join(appFolder, "downloads")
-
-	err := create_folder(downloadDir)
-
-	if err != nil {
+// UnmarshalJSON reads the fields and keeps the other properties in Extra
+func (p *Properties) UnmarshalJSON(data []byte) error {
+	type fields Properties
+	if err := json.Unmarshal(data, (*fields)(p)); err != nil {
 		return err
 	}
 
-	// Create download directory if it doesn't exist
-	if err := os.MkdirAll(downloadDir, 0755); err != nil {
+	var all map[string]interface{}
+	if err := json.Unmarshal(data, &all); err != nil {
 		return err
 	}
 
-	// Create a new context with logging allocator and headful mode
-	opts := append(chromedp.DefaultExecAllocatorOptions[:],
-		chromedp.Flag("headless", headless), // Run in headful mode
-		chromedp.Flag("disable-gpu", headless),
-		//chromedp.Flag("start-maximized", true),
-	)
-	var allocCtx context.Context
-	allocCtx, close1 = chromedp.NewExecAllocator(context.Background(), opts...)
-
-	// Create a new context with visible browser window
-	var ctx context.Context
-	ctx, close2 = chromedp.NewContext(allocCtx, chromedp.WithLogf(log.Printf))
-
-	timeOut := 2400 * time.Second
-	if headless {
-		timeOut = 60 * time.Second
+	// Like the fields, the names are matched ignoring case
+	for _, field := range parselPropertyFields() {
+		for name := range all {
+			if strings.EqualFold(name, field) {
+				delete(all, name)
+			}
+		}
 	}
-	// Set up a timeout
-	ctx, close3 = context.WithTimeout(ctx, timeOut)
-
-	err = chromedp.Run(ctx,
-		chromedp.Navigate(`https://parselsorgu.tkgm.gov.tr/`),
-
-		// Accept terms and conditions
-		chromedp.WaitReady(`//*[@id="terms-ok"]`, chromedp.BySearch),
-		chromedp.Click(`//*[@id="terms-ok"]`, chromedp.BySearch),
 
-		browser.SetDownloadBehavior(browser.SetDownloadBehaviorBehaviorAllowAndName).WithDownloadPath(downloadDir).WithEventsEnabled(true),
-	)
-
-	if err != nil {
-		return err
+	p.Extra = nil
+	if len(all) > 0 {
+		p.Extra = all
 	}
 
-	parselSorguContext = ctx
-
 	return nil
 }
 
-func (app *App) ParselSorgu(params QueryParams) (Properties, error) {
-	params.Province = cases.Title(language.Turkish).String(params.Province)
-	params.District = cases.Title(language.Turkish).String(params.District)
-	params.Neighborhood = cases.Title(language.Turkish).String(params.Neighborhood)
-
-	runtime.LogInfo(app.ctx, fmt.Sprintf("Parsel Sorgu: %s,%s,%s,%s,%s", params.Province, params.District, params.Neighborhood, params.Block, params.Parcel))
-
-	// Clear the download directory
-	err := os.RemoveAll(downloadDir)
-	if err != nil {
-		return Properties{}, err
-	}
-	err = create_folder(downloadDir)
-	if err != nil {
-		return Properties{}, err
+// MarshalJSON writes Extra next to the fields, so cached parcels keep every property
+func (p Properties) MarshalJSON() ([]byte, error) {
+	type fields Properties
+	data, err := json.Marshal(fields(p))
+	if err != nil || len(p.Extra) == 0 {
+		return data, err
 	}
 
-	if params.Province == "" || params.District == "" || params.Neighborhood == "" || params.Block == "" || params.Parcel == "" {
-		return Properties{}, fmt.Errorf("province, district, neighborhood, block and parcel are required")
+	all := make(map[string]interface{}, len(p.Extra))
+	for name, value := range p.Extra {
+		all[name] = value
+	}
+	if err := json.Unmarshal(data, &all); err != nil {
+		return nil, err
 	}
 
-	err = chromedp.Run(parselSorguContext,
-		// wait while loading
-		chromedp.WaitNotPresent(`.nprogress-busy`, chromedp.BySearch),
-
-		// Select province
-		chromedp.ActionFunc(func(ctx context.Context) error {
-			runtime.LogInfo(app.ctx, "Waiting for province selection")
-			chromedp.WaitVisible(`//*[@id="province-select"]`, chromedp.BySearch)
-
-			// Find the option based on its text content to get its value attribute
-			var optionValue string
-			err := chromedp.Run(ctx, chromedp.Evaluate(`document.evaluate('//*[@id="province-select"]/option[contains(text(), "`+params.Province+`")]', document, null, XPathResult.FIRST_ORDERED_NODE_TYPE, null).singleNodeValue.value;`, &optionValue))
-			if err != nil {
-				return fmt.Errorf("failed to find option value for province %s: %w", params.Province, err)
-			}
-			runtime.LogInfo(app.ctx, "Option value: "+optionValue)
-
-			// Set the value of the <select> element to the retrieved optionValue
-			err = chromedp.Run(ctx, chromedp.SetValue(`#province-select`, optionValue, chromedp.ByID))
-			if err != nil {
-				return fmt.Errorf("failed to set province value for %s: %w", params.Province, err)
-			}
-
-			return nil
-		}),
-
-		// wait while loading
-		chromedp.WaitNotPresent(`.nprogress-busy`, chromedp.BySearch),
-
-		// Select district
-		chromedp.ActionFunc(func(ctx context.Context) error {
-			runtime.LogInfo(app.ctx, "Waiting for district selection")
-			chromedp.WaitVisible(`//*[@id="district-select"]`, chromedp.BySearch)
-
-			// Find the option based on its text content to get its value attribute
-			var optionValue string
-			err := chromedp.Run(ctx, chromedp.Evaluate(`document.evaluate('//*[@id="district-select"]/option[contains(text(), "`+params.District+`")]', document, null, XPathResult.FIRST_ORDERED_NODE_TYPE, null).singleNodeValue.value;`, &optionValue))
-			if err != nil {
-				return fmt.Errorf("failed to find option value for district %s: %w", params.District, err)
-			}
-			runtime.LogInfo(app.ctx, "Option value: "+optionValue)
-
-			// Set the value of the <select> element to the retrieved optionValue
-			err = chromedp.Run(ctx, chromedp.SetValue(`#district-select`, optionValue, chromedp.ByID))
-			if err != nil {
-				return fmt.Errorf("failed to set district value for %s: %w", params.District, err)
-			}
-
-			return nil
-		}),
+	return json.Marshal(all)
+}
 
-		// wait while loading
-		chromedp.WaitNotPresent(`.nprogress-busy`, chromedp.BySearch),
+type QueryParams struct {
+	Province     string `json:"province"`
+	District     string `json:"district"`
+	Neighborhood string `json:"neighborhood"`
+	Block        string `json:"block"`
+	Parcel       string `json:"parcel"`
+}
 
-		// Select neighborhood
-		chromedp.ActionFunc(func(ctx context.Context) error {
-			runtime.LogInfo(app.ctx, "Waiting for neighborhood selection")
-			chromedp.WaitVisible(`//*[@id="neighborhood-select"]`, chromedp.BySearch)
+// ParselBackend looks up parcels on the TKGM parcel query service
+type ParselBackend interface {
+	Query(ctx context.Context, params QueryParams) (FeatureCollection, error)
+	Close() error
+}
 
-			// Find the option based on its text content to get its value attribute
-			var optionValue string
-			err := chromedp.Run(ctx, chromedp.Evaluate(`document.evaluate('//*[@id="neighborhood-select"]/option[contains(text(), "`+params.Neighborhood+`")]', document, null, XPathResult.FIRST_ORDERED_NODE_TYPE, null).singleNodeValue.value;`, &optionValue))
-			if err != nil {
-				return fmt.Errorf("failed to find option value for neighborhood %s: %w", params.Neighborhood, err)
-			}
-			runtime.LogInfo(app.ctx, "Option value: "+optionValue)
+const (
+	ParselBackendHTTP    = "http"
+	ParselBackendBrowser = "browser"
+)
 
-			// Set the value of the <select> element to the retrieved optionValue
-			err = chromedp.Run(ctx, chromedp.SetValue(`#neighborhood-select`, optionValue, chromedp.ByID))
-			if err != nil {
-				return fmt.Errorf("failed to set neighborhood value for %s: %w", params.Neighborhood, err)
-			}
+var (
+	parselBackendMu sync.Mutex
+	parselBackend   ParselBackend
+)
 
-			return nil
-		}),
+func newParselBackend(kind string, headless bool) (ParselBackend, error) {
+	switch kind {
+	case ParselBackendHTTP, "":
+		return newHTTPParselBackend("", nil), nil
+	case ParselBackendBrowser:
+		return newBrowserParselBackend(headless)
+	default:
+		return nil, fmt.Errorf("unknown parcel query backend: %s", kind)
+	}
+}
 
-		// wait while loading
-		chromedp.WaitNotPresent(`.nprogress-busy`, chromedp.BySearch),
+func (a *App) InitParselSorgu(headless bool) error {
+	parselBackendMu.Lock()
+	defer parselBackendMu.Unlock()
 
-		// Wait for other elements to be ready and interact with them as needed
-		chromedp.WaitReady(`#block-input`, chromedp.ByID),
-		chromedp.SendKeys(`#block-input`, params.Block),
+	closeParselBackend()
 
-		chromedp.WaitReady(`#parcel-input`, chromedp.ByID),
-		chromedp.SendKeys(`#parcel-input`, params.Parcel),
+	backend, err := newParselBackend(*config.ParselSorguBackend, headless)
+	if err != nil {
+		return err
+	}
 
-		chromedp.Sleep(100*time.Millisecond),
+	parselBackend = backend
 
-		chromedp.WaitReady(`#administrative-query-btn`, chromedp.ByID),
-		chromedp.Click(`#administrative-query-btn`, chromedp.ByID),
+	return nil
+}
 
-		// wait while loading
-		chromedp.WaitNotPresent(`.nprogress-busy`, chromedp.BySearch),
+func closeParselSorgu() {
+	parselBackendMu.Lock()
+	defer parselBackendMu.Unlock()
 
-		chromedp.Sleep(5*time.Second),
+	closeParselBackend()
+}
 
-		// stop runs if .close-btn exists
-		chromedp.ActionFunc(func(ctx context.Context) error {
-			runtime.LogInfo(app.ctx, "Checking for close button")
-			var exists bool
-			err := chromedp.Run(ctx, chromedp.Evaluate(`document.querySelector("#close-btn") !== null`, &exists))
-			if err != nil {
-				return fmt.Errorf("failed to check for close button: %w", err)
-			}
-			if exists {
-				runtime.LogInfo(app.ctx, "Close button found, clicking it and stopping execution")
-				err = chromedp.Run(ctx, chromedp.Click("#close-btn", chromedp.ByID))
-				if err != nil {
-					return fmt.Errorf("failed to click close button: %w", err)
-				}
-				return fmt.Errorf("execution stopped due to close button")
-			} else {
-				runtime.LogInfo(app.ctx, "Close button not found, continuing execution")
-			}
-			return nil
-		}),
+// closeParselBackend closes the running backend, the caller holds parselBackendMu
+func closeParselBackend() {
+	if parselBackend != nil {
+		parselBackend.Close()
+		parselBackend = nil
+	}
+	if fallbackPointBackend != nil {
+		fallbackPointBackend.Close()
+		fallbackPointBackend = nil
+	}
+}
 
-		chromedp.ActionFunc(func(ctx context.Context) error {
-			// Evaluate the XPath to find the last path element
-			var pathCount int
-			err := chromedp.Run(ctx, chromedp.Evaluate(`document.querySelectorAll("#map-canvas > div.leaflet-pane.leaflet-map-pane > div.leaflet-pane.leaflet-overlay-pane > svg > g > path").length`, &pathCount))
-			if err != nil {
-				return fmt.Errorf("failed to count paths: %w", err)
-			}
+// runningParselBackend returns the running backend, starting it if there is none
+func runningParselBackend(headless bool) (ParselBackend, error) {
+	parselBackendMu.Lock()
+	defer parselBackendMu.Unlock()
 
-			// Select the last path element
-			pathSelector := fmt.Sprintf(`#map-canvas > div.leaflet-pane.leaflet-map-pane > div.leaflet-pane.leaflet-overlay-pane > svg > g > path:nth-child(%d)`, pathCount)
-			err = chromedp.Run(ctx, chromedp.Click(pathSelector))
-			if err != nil {
-				return fmt.Errorf("failed to click on last path element: %w", err)
-			}
+	if parselBackend == nil {
+		backend, err := newParselBackend(*config.ParselSorguBackend, headless)
+		if err != nil {
+			return nil, err
+		}
+		parselBackend = backend
+	}
 
-			return nil
-		}),
+	return parselBackend, nil
+}
 
-		// wait while loading
-		chromedp.WaitNotPresent(`.nprogress-busy`, chromedp.BySearch),
+func (app *App) ParselSorgu(params QueryParams) (Properties, error) {
+	featureCollection, err := app.queryParsel(params, *config.ParselSorguHeadless)
+	if err != nil {
+		return Properties{}, err
+	}
 
-		chromedp.WaitReady(".dropdown-toggle", chromedp.BySearch),
-		chromedp.Click(".dropdown-toggle", chromedp.BySearch),
+	return featureCollection.Features[0].Properties, nil
+}
 
-		// wait while loading
-		chromedp.WaitNotPresent(`.nprogress-busy`, chromedp.BySearch),
+// queryParsel answers from the parcel cache when possible, otherwise queries the backend,
+// starting it if needed, and caches the result
+func (app *App) queryParsel(params QueryParams, headless bool) (FeatureCollection, error) {
+	featureCollection, _, err := app.queryParselCached(params, headless)
+	return featureCollection, err
+}
 
-		chromedp.WaitReady(`/html/body/div[3]/div[3]/div/div/div[1]/div/ul/li[11]/a`, chromedp.BySearch),
-		chromedp.Click(`/html/body/div[3]/div[3]/div/div/div[1]/div/ul/li[11]/a`, chromedp.BySearch),
+// queryParselCached is queryParsel also reporting whether the result came from the cache.
+// Transient failures are retried with backoff, errors are always *ParselError.
+func (app *App) queryParselCached(params QueryParams, headless bool) (FeatureCollection, bool, error) {
+	params.Province = cases.Title(language.Turkish).String(params.Province)
+	params.District = cases.Title(language.Turkish).String(params.District)
+	params.Neighborhood = cases.Title(language.Turkish).String(params.Neighborhood)
 
-		// wait while loading
-		chromedp.WaitNotPresent(`.nprogress-busy`, chromedp.BySearch),
+	runtime.LogInfo(app.ctx, fmt.Sprintf("Parsel Sorgu: %s,%s,%s,%s,%s", params.Province, params.District, params.Neighborhood, params.Block, params.Parcel))
 
-		chromedp.WaitReady(`/html/body/div[3]/div[3]/div/div/div[2]/div[1]/div/div[3]/table/tbody/tr/td[3]/input`, chromedp.BySearch),
-		chromedp.Click(`/html/body/div[3]/div[3]/div/div/div[2]/div[1]/div/div[3]/table/tbody/tr/td[3]/input`, chromedp.BySearch),
+	cache := getParselCache()
+	ttl := parselCacheTTL()
 
-		// Download button
-		chromedp.WaitReady(`#export-data`, chromedp.ByID),
-		chromedp.Click(`#export-data`, chromedp.ByID),
+	if ttl > 0 {
+		if featureCollection, ok := cache.Get(params, ttl); ok && len(featureCollection.Features) > 0 {
+			runtime.LogInfo(app.ctx, "Using cached parcel: "+parselCacheKey(params))
+			return featureCollection, true, nil
+		}
+	}
 
-		// wait while loading
-		chromedp.Sleep(3*time.Second),
+	featureCollection, err := app.runParselQuery(func() (FeatureCollection, error) {
+		backend, err := runningParselBackend(headless)
+		if err != nil {
+			return FeatureCollection{}, newParselError(ParselErrorUnavailable, err, "failed to start %s backend", *config.ParselSorguBackend)
+		}
 
-		// Close button
-		chromedp.WaitReady(`#close-btn`, chromedp.ByID),
-		chromedp.Click(`#close-btn`, chromedp.ByID),
+		featureCollection, err := backend.Query(app.ctx, params)
+		if err == nil && len(featureCollection.Features) == 0 {
+			err = newParselError(ParselErrorNotFound, nil, "parcel %s/%s", params.Block, params.Parcel)
+		}
 
-		// wait while loading
-		chromedp.Sleep(2*time.Second),
-	)
+		return featureCollection, err
+	})
 
 	if err != nil {
 		runtime.LogError(app.ctx, err.Error())
-		return Properties{}, err
+		return FeatureCollection{}, false, err
 	}
 
-	// Select the only file in downloads folder
-	files, err := os.ReadDir(downloadDir)
-	if err != nil {
-		runtime.LogError(app.ctx, err.Error())
-		return Properties{}, err
-	}
+	runtime.LogInfo(app.ctx, "Feature properties: "+fmt.Sprint(featureCollection.Features[0].Properties))
 
-	for {
-		if len(files) == 0 {
-			runtime.LogWarning(app.ctx, "Downloaded file not found")
-			time.Sleep(200 * time.Millisecond)
-			// Select the only file in downloads folder
-			files, err = os.ReadDir(downloadDir)
-			if err != nil {
-				runtime.LogError(app.ctx, err.Error())
-				return Properties{}, err
-			}
-		} else {
-			break
+	if ttl > 0 {
+		if err := cache.Put(params, featureCollection); err != nil {
+			runtime.LogWarning(app.ctx, "Failed to cache parcel: "+err.Error())
 		}
 	}
 
-	filePath := filepath.Join(downloadDir, files[0].Name())
+	return featureCollection, false, nil
+}
 
-	if len(files) != 1 {
-		runtime.LogError(app.ctx, "More than one downloaded file found")
+// runParselQuery runs query under the rate limit, retrying transient failures
+func (app *App) runParselQuery(query func() (FeatureCollection, error)) (FeatureCollection, error) {
+	parselLimiter.setRate(*config.ParselRequestsPerMinute)
 
-		// Use the last added file
-		// Sort files by added date
-		sort.Slice(files, func(i, j int) bool {
-			infoI, err := os.Stat(path.Join(downloadDir, files[i].Name()))
-			if err != nil {
-				return false
-			}
+	return retryParselQuery(app.ctx, *config.ParselMaxRetries, func() (FeatureCollection, error) {
+		if err := parselLimiter.Wait(app.ctx); err != nil {
+			return FeatureCollection{}, newParselError(ParselErrorTimeout, err, "cancelled while waiting for rate limit")
+		}
 
-			infoJ, err := os.Stat(path.Join(downloadDir, files[j].Name()))
-			if err != nil {
-				return false
-			}
+		return query()
+	}, func(retry int, err *ParselError) {
+		runtime.LogWarning(app.ctx, fmt.Sprintf("Parcel query failed, retry %d/%d: %s", retry, *config.ParselMaxRetries, err.Error()))
+	})
+}
 
-			return infoI.ModTime().After(infoJ.ModTime())
-		})
+// parselRowResult is the outcome of a row's query
+type parselRowResult struct {
+	collection FeatureCollection
+	cached     bool
+	err        error
+}
 
-		runtime.LogInfo(app.ctx, "Last added file: "+files[0].Name())
-		filePath = filepath.Join(downloadDir, files[0].Name())
+// parselProgress counts the finished rows of a run across its batches for the progress message
+type parselProgress struct {
+	done  atomic.Int32
+	total int
+}
+
+func (p *parselProgress) step() {
+	if p == nil {
+		return
 	}
+	runtime.WindowExecJS(appContext, `window.setParselMessage("`+fmt.Sprintf("%d/%d", p.done.Add(1), p.total)+`");`)
+}
 
-	runtime.LogInfo(app.ctx, "Processing file: "+filePath)
+// queryParselRows runs query for the rows with the configured number of workers, the results are
+// in the order of rows no matter which query finishes first
+func (app *App) queryParselRows(rows []int, progress *parselProgress, query func(i int) (FeatureCollection, bool, error)) []parselRowResult {
+	return runParselRows(rows, progress, func(i int) parselRowResult {
+		collection, cached, err := query(i)
+		return parselRowResult{collection, cached, err}
+	})
+}
 
-	// Read file content
-	fileContent, err := os.ReadFile(filePath)
-	if err != nil {
-		return Properties{}, fmt.Errorf("failed to read downloaded file: %w", err)
-	}
+// runParselRows runs the rows with the configured number of workers, a nil progress reports nothing
+func runParselRows[T any](rows []int, progress *parselProgress, run func(i int) T) []T {
+	results := make([]T, len(rows))
 
-	time.Sleep(2 * time.Second)
+	workers := min(max(*config.ParselConcurrency, 1), max(len(rows), 1))
+	jobs := make(chan int)
 
-	// Delete the downloaded file
-	err = os.Remove(filePath)
-	if err != nil {
-		return Properties{}, fmt.Errorf("failed to delete downloaded file: %w", err)
-	}
+	var wg sync.WaitGroup
 
-	// Unmarshal JSON content into FeatureCollection struct
-	var featureCollection FeatureCollection
-	if err := json.Unmarshal(fileContent, &featureCollection); err != nil {
-		return Properties{}, fmt.Errorf("failed to unmarshal JSON: %w", err)
-	}
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
 
-	feature := featureCollection.Features[0]
-	properties := feature.Properties
+			for j := range jobs {
+				results[j] = run(rows[j])
+				progress.step()
+			}
+		}()
+	}
 
-	runtime.LogInfo(app.ctx, "Feature properties: "+fmt.Sprint(properties))
+	for j := range rows {
+		jobs <- j
+	}
+	close(jobs)
 
-	time.Sleep(2 * time.Second)
+	wg.Wait()
 
-	return properties, nil
+	return results
 }
 
 var alphabet = []string{"A", "B", "C", "D", "E", "F", "G", "H", "I", "J", "K", "L", "M", "N", "O", "P", "Q", "R", "S", "T", "U", "V", "W", "X", "Y", "Z", "AA", "AB", "AC", "AD", "AE", "AF", "AG", "AH", "AI", "AJ", "AK", "AL", "AM", "AN", "AO", "AP", "AQ", "AR", "AS", "AT", "AU", "AV", "AW", "AX", "AY", "AZ"}
 
-func (app *App) AddParselSorguFields(excelPath string, ilHeader, ilceHeader, mahalleHeader, adaHeader, parselHeader, alanHeader, paftaHeader, cinsHeader, mevkiHeader string, headless bool) error {
-	if !headless {
-		err := app.InitParselSorgu(headless)
+// AddParselSorguFields queries the parcel of every row and fills the columns of the configured
+// field mapping, adding the ones missing
+func (app *App) AddParselSorguFields(excelPath string, ilHeader, ilceHeader, mahalleHeader, adaHeader, parselHeader string, headless bool) error {
+	// Backends are started on the first row missing from the cache and shared by all rows
+	defer closeParselSorgu()
 
-		if err != nil {
-			runtime.LogError(app.ctx, err.Error())
-			return err
-		}
+	if err := checkExcelSavable(excelPath); err != nil {
+		runtime.LogError(app.ctx, err.Error())
+		app.SendNotification("", excelSaveError(err), "", "error")
+		return err
 	}
 
-	headers, rows, excel, err := ReadExcel(excelPath)
-	sheetName := excel.GetSheetList()[0]
+	checkpoint, readPath := newExcelCheckpoint(ExcelTaskParsel, excelPath)
 
+	headers, rows, excel, err := ReadExcel(readPath)
 	if err != nil {
 		runtime.LogError(app.ctx, err.Error())
 		return err
 	}
 
+	sheetName := excel.GetSheetList()[0]
+
 	var il, ilce, mahalle, ada, parsel string
 
-	ilIndex, ilceIndex, mahalleIndex, adaIndex, parselIndex, alanIndex, paftaIndex, cinsIndex, mevkiIndex := -1, -1, -1, -1, -1, -1, -1, -1, -1
+	ilIndex, ilceIndex, mahalleIndex, adaIndex, parselIndex := -1, -1, -1, -1, -1
 
 	runtime.LogInfo(app.ctx, "Headers: "+fmt.Sprint(headers))
 
@@ -442,18 +378,6 @@ func (app *App) AddParselSorguFields(excelPath string, ilHeader, ilceHeader, mah
 		} else if header == parselHeader {
 			parselIndex = i
 			runtime.LogInfo(app.ctx, "Matched parselHeader: "+header)
-		} else if header == alanHeader {
-			alanIndex = i
-			runtime.LogInfo(app.ctx, "Matched alanHeader: "+header)
-		} else if header == paftaHeader {
-			paftaIndex = i
-			runtime.LogInfo(app.ctx, "Matched paftaHeader: "+header)
-		} else if header == cinsHeader {
-			cinsIndex = i
-			runtime.LogInfo(app.ctx, "Matched cinsHeader: "+header)
-		} else if header == mevkiHeader {
-			mevkiIndex = i
-			runtime.LogInfo(app.ctx, "Matched mevkiHeader: "+header)
 		}
 	}
 
@@ -482,20 +406,34 @@ func (app *App) AddParselSorguFields(excelPath string, ilHeader, ilceHeader, mah
 		parsel = parselHeader
 	}
 
-	for i := 0; i < len(rows); i++ {
-		if headless {
-			err := app.InitParselSorgu(headless)
+	fieldColumns, err := newParselFieldColumns(excel, sheetName, &headers, *config.ParselFields)
+	if err != nil {
+		runtime.LogError(app.ctx, err.Error())
+		return err
+	}
 
-			if err != nil {
-				runtime.LogError(app.ctx, err.Error())
-				return err
-			}
+	statusIndex := -1
+	if *config.ParselStatusCellName != "" {
+		statusIndex, err = ensureColumn(excel, sheetName, &headers, *config.ParselStatusCellName)
+		if err != nil {
+			runtime.LogError(app.ctx, err.Error())
+			return err
 		}
+	}
 
-		runtime.WindowExecJS(appContext, `window.setParselMessage("`+fmt.Sprintf("%d/%d", i+1, len(rows))+`");`)
+	writer, err := newExcelCellWriter(excel, sheetName, headers)
+	if err != nil {
+		runtime.LogError(app.ctx, err.Error())
+		return err
+	}
 
-		row := rows[i]
+	var nameReport []ParselNameIssue
+	defer func() {
+		lastParselNameReport = nameReport
+	}()
 
+	params := make([]QueryParams, len(rows))
+	for i, row := range rows {
 		if ilIndex != -1 {
 			il = row[ilIndex]
 		}
@@ -512,85 +450,77 @@ func (app *App) AddParselSorguFields(excelPath string, ilHeader, ilceHeader, mah
 			parsel = row[parselIndex]
 		}
 
-		properties, err := app.ParselSorgu(QueryParams{Province: il, District: ilce, Neighborhood: mahalle, Block: ada, Parcel: parsel})
+		params[i] = QueryParams{Province: il, District: ilce, Neighborhood: mahalle, Block: ada, Parcel: parsel}
+	}
 
-		if err != nil {
-			runtime.LogError(app.ctx, err.Error())
+	var pending []int
+	for i, row := range rows {
+		if checkpoint.isDone(i) || (*config.ExcelResume && (parselStatusDone(row, statusIndex) || fieldColumns.filled(row))) {
 			continue
 		}
+		pending = append(pending, i)
+	}
+	skipped := len(rows) - len(pending)
 
-		if alanHeader != "" {
-			alan := properties.Alan
-			alan = strings.ReplaceAll(alan, ".", "")
-			alan = strings.ReplaceAll(alan, ",", ".")
+	progress := &parselProgress{total: len(pending)}
+	var known []FeatureCollection
 
-			floa64Alan, err := strconv.ParseFloat(alan, 64)
-			if err != nil {
-				runtime.LogError(app.ctx, err.Error())
-				continue
-			}
-			err = excel.SetCellFloat(sheetName, alphabet[alanIndex]+fmt.Sprint(i+2), floa64Alan, 2, 32)
+	for _, batch := range checkpoint.batches(pending) {
+		results := app.queryParselRows(batch, progress, func(i int) (FeatureCollection, bool, error) {
+			return app.queryParselCached(params[i], headless)
+		})
 
-			if err != nil {
-				runtime.LogError(app.ctx, err.Error())
-				return err
-			}
+		neighbors := make([]string, len(results))
+		if fieldColumns.has(ParselFieldNeighbors) {
+			neighbors = app.findParselRowNeighbors(results, known, headless)
 		}
 
-		if paftaHeader != "" {
-			pafta := properties.Pafta
+		for j, i := range batch {
+			row := rows[i]
 
-			err = excel.SetCellStr(sheetName, alphabet[paftaIndex]+fmt.Sprint(i+2), pafta)
+			featureCollection, cached, err := results[j].collection, results[j].cached, results[j].err
 
-			if err != nil {
-				runtime.LogError(app.ctx, err.Error())
-				return err
+			if statusIndex != -1 {
+				statusCell, _ := excelize.CoordinatesToCellName(statusIndex+1, i+2)
+				if err := excel.SetCellStr(sheetName, statusCell, parselStatus(err, cached)); err != nil {
+					runtime.LogError(app.ctx, err.Error())
+				}
 			}
-		}
 
-		if cinsHeader != "" {
-			cins := properties.Nitelik
-			err = excel.SetCellStr(sheetName, alphabet[cinsIndex]+fmt.Sprint(i+2), cins)
+			if issue, ok := parselNameIssue(i+2, err); ok {
+				nameReport = append(nameReport, issue)
+			}
 
+			// Failed rows aren't marked done so a resumed run retries them
 			if err != nil {
-				runtime.LogError(app.ctx, err.Error())
-				return err
+				continue
 			}
-		}
 
-		if mevkiHeader != "" {
-			mevki := properties.Mevkii
-			err = excel.SetCellStr(sheetName, alphabet[mevkiIndex]+fmt.Sprint(i+2), mevki)
+			known = append(known, featureCollection)
 
-			if err != nil {
+			if err := fieldColumns.write(writer, i+2, featureCollection, row, neighbors[j]); err != nil {
 				runtime.LogError(app.ctx, err.Error())
-				return err
 			}
-		}
 
-		if headless {
-			close1()
-			close2()
-			close3()
+			if err := checkpoint.markDone(excel, i); err != nil {
+				runtime.LogError(app.ctx, err.Error())
+				app.SendNotification("", excelSaveError(err), "", "error")
+				return err
+			}
 		}
 	}
 
-	err = excel.SaveAs(excelPath)
+	savedPath, err := checkpoint.finish(excel)
 
 	if err != nil {
 		runtime.LogError(app.ctx, err.Error())
+		app.SendNotification("", excelSaveError(err), "", "error")
 		return err
 	}
 
-	app.SendNotification("Excel dosyası başarıyla güncellendi", "", "", "success")
+	app.SendNotification("Excel dosyası başarıyla güncellendi", resumeMessage(skipped), strings.ReplaceAll(savedPath, "\\", "\\\\"), "success")
 
-	runtime.WindowExecJS(appContext, `window.setParselMessage("Excel dosyası başarıyla güncellendi");`)
-
-	if !headless {
-		close1()
-		close2()
-		close3()
-	}
+	runtime.WindowExecJS(appContext, `window.setParselMessage("Excel dosyası başarıyla güncellendi`+resumeMessage(skipped)+`");`)
 
 	return nil
 }
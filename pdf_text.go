@@ -0,0 +1,223 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"os"
+	"os/exec"
+	"sort"
+	"strings"
+
+	"github.com/ledongthuc/pdf"
+	"github.com/wailsapp/wails/v2/pkg/runtime"
+	"golang.org/x/text/unicode/norm"
+)
+
+const (
+	PdfTextBackendBuiltin = "builtin"
+	PdfTextBackendXpdf    = "xpdf"
+)
+
+// turkishGlyphReplacer maps glyphs produced by fonts that embed Windows-1254
+// characters under a Windows-1252 encoding back to the Turkish letters
+var turkishGlyphReplacer = strings.NewReplacer(
+	"Ý", "İ",
+	"ý", "ı",
+	"Þ", "Ş",
+	"þ", "ş",
+	"Ð", "Ğ",
+	"ð", "ğ",
+	"ﬁ", "fi",
+	"ﬂ", "fl",
+	"\u00a0", " ",
+)
+
+// pdfGlyph is a single run of text placed on a page
+type pdfGlyph struct {
+	x, y, w, size float64
+	s             string
+}
+
+func ReadPlainTextFromPDF(pdfpath string) (text string, err error) {
+	backend := PdfTextBackendBuiltin
+	if config.PdfTextBackend != nil && *config.PdfTextBackend != "" {
+		backend = *config.PdfTextBackend
+	}
+
+	runtime.LogInfo(appContext, "Reading text from PDF using "+backend+" backend")
+
+	switch backend {
+	case PdfTextBackendXpdf:
+		return readPlainTextWithXpdf(pdfpath)
+	case PdfTextBackendBuiltin:
+		text, err = extractPDFText(pdfpath)
+
+		if err == nil && strings.TrimSpace(text) != "" {
+			return text, nil
+		}
+
+		// Fall back to an already installed pdftotext without downloading it
+		if _, lookErr := exec.LookPath(pdfToTextPath); lookErr == nil {
+			runtime.LogWarning(appContext, "Builtin PDF reader failed, falling back to xpdf")
+			return readPlainTextWithXpdf(pdfpath)
+		}
+
+		if err == nil {
+			err = fmt.Errorf("no text found in %s", pdfpath)
+		}
+
+		return "", err
+	default:
+		return "", fmt.Errorf("unknown PDF text backend: %s", backend)
+	}
+}
+
+// readPlainTextWithXpdf runs pdftotext into a private temporary file so concurrent parses don't collide
+func readPlainTextWithXpdf(pdfpath string) (string, error) {
+	err := installXpdf()
+	if err != nil {
+		return "", err
+	}
+
+	tempFile, err := os.CreateTemp("", "folder-creator-*.txt")
+	if err != nil {
+		return "", err
+	}
+	tempFile.Close()
+	defer os.Remove(tempFile.Name())
+
+	cmd := exec.Command(pdfToTextPath, "-simple2", "-enc", "UTF-8", pdfpath, tempFile.Name())
+	err = cmd.Run()
+
+	if err != nil {
+		return "", err
+	}
+
+	bytes, err := os.ReadFile(tempFile.Name())
+
+	if err != nil {
+		return "", err
+	}
+
+	return string(bytes), nil
+}
+
+// extractPDFText reads every page of the PDF and lays the text out line by line,
+// keeping columns apart with spaces roughly proportional to their distance
+func extractPDFText(pdfpath string) (text string, err error) {
+	// The PDF reader panics on malformed streams
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("failed to read %s: %v", pdfpath, r)
+		}
+	}()
+
+	file, reader, err := pdf.Open(pdfpath)
+	if err != nil {
+		return "", err
+	}
+	defer file.Close()
+
+	var builder strings.Builder
+
+	for i := 1; i <= reader.NumPage(); i++ {
+		page := reader.Page(i)
+		if page.V.IsNull() {
+			continue
+		}
+
+		var glyphs []pdfGlyph
+		for _, t := range page.Content().Text {
+			if strings.TrimSpace(t.S) == "" {
+				continue
+			}
+			glyphs = append(glyphs, pdfGlyph{x: t.X, y: t.Y, w: t.W, size: t.FontSize, s: t.S})
+		}
+
+		for _, line := range layoutLines(glyphs) {
+			builder.WriteString(line)
+			builder.WriteString("\n")
+		}
+
+		// Page break, same as pdftotext
+		builder.WriteString("\f")
+	}
+
+	return normalizePDFText(builder.String()), nil
+}
+
+// layoutLines groups glyphs sharing a baseline into lines ordered top to bottom
+func layoutLines(glyphs []pdfGlyph) []string {
+	sort.SliceStable(glyphs, func(i, j int) bool {
+		if math.Abs(glyphs[i].y-glyphs[j].y) > 0.5 {
+			return glyphs[i].y > glyphs[j].y
+		}
+		return glyphs[i].x < glyphs[j].x
+	})
+
+	var lines [][]pdfGlyph
+	var lineY float64
+
+	for _, glyph := range glyphs {
+		tolerance := math.Max(glyph.size, 1) * 0.4
+		if len(lines) == 0 || math.Abs(lineY-glyph.y) > tolerance {
+			lines = append(lines, []pdfGlyph{glyph})
+			lineY = glyph.y
+			continue
+		}
+		lines[len(lines)-1] = append(lines[len(lines)-1], glyph)
+	}
+
+	result := make([]string, 0, len(lines))
+	for _, line := range lines {
+		result = append(result, layoutLine(line))
+	}
+
+	return result
+}
+
+func layoutLine(line []pdfGlyph) string {
+	sort.SliceStable(line, func(i, j int) bool {
+		return line[i].x < line[j].x
+	})
+
+	var builder strings.Builder
+	end := 0.0
+
+	for i, glyph := range line {
+		if i > 0 {
+			// Approximate a character cell as half the font size
+			cell := math.Max(glyph.size, 1) * 0.5
+			gap := glyph.x - end
+
+			if gap > cell*0.3 {
+				spaces := int(math.Round(gap / cell))
+				if spaces < 1 {
+					spaces = 1
+				}
+				builder.WriteString(strings.Repeat(" ", spaces))
+			}
+		}
+
+		builder.WriteString(glyph.s)
+		end = glyph.x + glyph.w
+	}
+
+	return strings.TrimRight(builder.String(), " ")
+}
+
+// normalizePDFText fixes Turkish glyphs and composes decomposed characters such as "i̇"
+func normalizePDFText(text string) string {
+	text = turkishGlyphReplacer.Replace(text)
+	text = norm.NFC.String(text)
+
+	return strings.Map(func(r rune) rune {
+		if r == '\n' || r == '\f' || r == '\t' {
+			return r
+		}
+		if r < 0x20 || (r >= 0xE000 && r <= 0xF8FF) {
+			return -1
+		}
+		return r
+	}, text)
+}
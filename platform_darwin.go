@@ -0,0 +1,63 @@
+//go:build darwin
+
+package main
+
+import (
+	"fmt"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+// restartElevated starts executable with administrator privileges through an osascript prompt
+func restartElevated(executable string, args []string) error {
+	quoted := []string{shellQuote(executable)}
+	for _, arg := range args {
+		quoted = append(quoted, shellQuote(arg))
+	}
+
+	// Detach the process so the prompt returns once the new instance is started
+	script := fmt.Sprintf(`do shell script "%s > /dev/null 2>&1 &" with administrator privileges`,
+		strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(strings.Join(quoted, " ")))
+
+	return exec.Command("osascript", "-e", script).Run()
+}
+
+// revealInFileManager opens Finder with path selected
+func revealInFileManager(path string) error {
+	return exec.Command("open", "-R", path).Start()
+}
+
+// openWithDefaultApp opens path with the application associated with its type
+func openWithDefaultApp(path string) error {
+	return exec.Command("open", path).Start()
+}
+
+// defaultPdfToTextPath prefers a pdftotext on PATH (Homebrew poppler or xpdf)
+func defaultPdfToTextPath() string {
+	if path, err := exec.LookPath("pdftotext"); err == nil {
+		return path
+	}
+
+	return filepath.Join(appFolder, "xpdf-tools", "xpdf-tools-mac-4.05", "bin64", "pdftotext")
+}
+
+// xpdfDownloadURL returns the xpdf-tools archive installXpdf downloads for this platform
+func xpdfDownloadURL() (string, error) {
+	return "", fmt.Errorf("pdftotext not found, install poppler or xpdf with Homebrew")
+}
+
+// updateAssetName returns the release file self-update installs on this platform, releases only
+// have a Windows build
+func updateAssetName() (string, error) {
+	return "", fmt.Errorf("updates are only published for Windows, build the app bundle from source")
+}
+
+func shellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
+
+// isFileLocked is always false, files open in other programs can still be replaced here
+func isFileLocked(err error) bool {
+	return false
+}
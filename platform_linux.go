@@ -0,0 +1,85 @@
+//go:build linux
+
+package main
+
+import (
+	"fmt"
+	"net/url"
+	"os"
+	"os/exec"
+	"path/filepath"
+)
+
+// restartElevated starts executable as root through polkit, forwarding the display environment
+func restartElevated(executable string, args []string) error {
+	pkexec, err := exec.LookPath("pkexec")
+	if err != nil {
+		return fmt.Errorf("pkexec not found: %w", err)
+	}
+
+	cmdArgs := []string{"env"}
+	for _, key := range []string{"DISPLAY", "XAUTHORITY", "WAYLAND_DISPLAY", "XDG_RUNTIME_DIR", "DBUS_SESSION_BUS_ADDRESS"} {
+		if value, ok := os.LookupEnv(key); ok {
+			cmdArgs = append(cmdArgs, key+"="+value)
+		}
+	}
+	cmdArgs = append(cmdArgs, executable)
+	cmdArgs = append(cmdArgs, args...)
+
+	return exec.Command(pkexec, cmdArgs...).Start()
+}
+
+// revealInFileManager asks the desktop file manager to show path selected,
+// falling back to opening the containing folder
+func revealInFileManager(path string) error {
+	absPath, err := filepath.Abs(path)
+	if err != nil {
+		return err
+	}
+
+	fileURI := (&url.URL{Scheme: "file", Path: absPath}).String()
+
+	err = exec.Command("dbus-send", "--session", "--print-reply", "--dest=org.freedesktop.FileManager1",
+		"--type=method_call", "/org/freedesktop/FileManager1", "org.freedesktop.FileManager1.ShowItems",
+		"array:string:"+fileURI, "string:").Run()
+	if err == nil {
+		return nil
+	}
+
+	dir := absPath
+	if info, err := os.Stat(absPath); err != nil || !info.IsDir() {
+		dir = filepath.Dir(absPath)
+	}
+
+	return exec.Command("xdg-open", dir).Start()
+}
+
+// openWithDefaultApp opens path with the application associated with its type
+func openWithDefaultApp(path string) error {
+	return exec.Command("xdg-open", path).Start()
+}
+
+// defaultPdfToTextPath prefers a pdftotext on PATH (poppler-utils or xpdf)
+func defaultPdfToTextPath() string {
+	if path, err := exec.LookPath("pdftotext"); err == nil {
+		return path
+	}
+
+	return filepath.Join(appFolder, "xpdf-tools", "xpdf-tools-linux-4.05", "bin64", "pdftotext")
+}
+
+// xpdfDownloadURL returns the xpdf-tools archive installXpdf downloads for this platform
+func xpdfDownloadURL() (string, error) {
+	return "", fmt.Errorf("pdftotext not found, install poppler-utils or xpdf from your package manager")
+}
+
+// updateAssetName returns the release file self-update installs on this platform, releases only
+// have a Windows build
+func updateAssetName() (string, error) {
+	return "", fmt.Errorf("updates are only published for Windows, update from your package manager or build from source")
+}
+
+// isFileLocked is always false, files open in other programs can still be replaced here
+func isFileLocked(err error) bool {
+	return false
+}
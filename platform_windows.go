@@ -0,0 +1,82 @@
+//go:build windows
+
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os/exec"
+	"path"
+	r "runtime"
+	"strings"
+
+	"golang.org/x/sys/windows"
+)
+
+// restartElevated starts executable with administrator privileges through the UAC prompt
+func restartElevated(executable string, args []string) error {
+	verb := "runas"
+	showCmd := 1 // SW_NORMAL
+
+	executablePtr, err := windows.UTF16PtrFromString(executable)
+	if err != nil {
+		return fmt.Errorf("failed to convert executable path to UTF16: %w", err)
+	}
+
+	// Convert arguments to a single string
+	argStr := strings.Join(args, " ")
+
+	argPtr, err := windows.UTF16PtrFromString(argStr)
+	if err != nil {
+		return fmt.Errorf("failed to convert arguments to UTF16: %w", err)
+	}
+
+	// Execute with elevated privileges
+	err = windows.ShellExecute(0, windows.StringToUTF16Ptr(verb), executablePtr, argPtr, nil, int32(showCmd))
+	if err != nil {
+		return fmt.Errorf("ShellExecute failed: %w", err)
+	}
+
+	return nil
+}
+
+// revealInFileManager opens Explorer with path selected
+func revealInFileManager(path string) error {
+	// explorer exits with a non-zero code even on success, so don't wait for it
+	return exec.Command(`explorer`, `/select,`, path).Start()
+}
+
+// openWithDefaultApp opens path with the application associated with its type
+func openWithDefaultApp(path string) error {
+	return exec.Command("rundll32.exe", "url.dll,FileProtocolHandler", path).Run()
+}
+
+// defaultPdfToTextPath returns the location of the xpdf pdftotext binary installed by installXpdf
+func defaultPdfToTextPath() string {
+	bin := "bin64"
+	if r.GOARCH == "386" {
+		bin = "bin32"
+	}
+
+	return path.Join(appFolder, "xpdf-tools", "xpdf-tools-win-4.05", bin, "pdftotext.exe")
+}
+
+// xpdfDownloadURL returns the xpdf-tools archive installXpdf downloads for this platform
+func xpdfDownloadURL() (string, error) {
+	switch r.GOARCH {
+	case "amd64", "386":
+		return "https://dl.xpdfreader.com/xpdf-tools-win-4.05.zip", nil
+	default:
+		return "", fmt.Errorf("unsupported architecture: %s", r.GOARCH)
+	}
+}
+
+// updateAssetName returns the release file self-update installs on this platform
+func updateAssetName() (string, error) {
+	return "folder-creator.exe", nil
+}
+
+// isFileLocked reports whether err is Windows refusing access to a file another process has open
+func isFileLocked(err error) bool {
+	return errors.Is(err, windows.ERROR_SHARING_VIOLATION) || errors.Is(err, windows.ERROR_LOCK_VIOLATION)
+}
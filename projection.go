@@ -0,0 +1,385 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// ellipsoid is a reference ellipsoid given by its semi-major axis and flattening
+type ellipsoid struct {
+	a float64
+	f float64
+}
+
+var (
+	ellipsoidGRS80         = ellipsoid{a: 6378137, f: 1 / 298.257222101}
+	ellipsoidWGS84         = ellipsoid{a: 6378137, f: 1 / 298.257223563}
+	ellipsoidInternational = ellipsoid{a: 6378388, f: 1 / 297.0}
+)
+
+// CRS is a coordinate reference system parcel geometries can be converted between.
+// Geographic systems use longitude/latitude in degrees, projected ones easting/northing in metres.
+type CRS struct {
+	Code int    `json:"code"`
+	Name string `json:"name"`
+
+	ellipsoid ellipsoid
+	// Translation from the datum to WGS84 in metres, ITRF96 is treated as WGS84
+	toWGS84 [3]float64
+
+	projected       bool
+	centralMeridian float64
+	scale           float64
+	falseEasting    float64
+	falseNorthing   float64
+}
+
+const (
+	EPSGWGS84 = 4326
+	EPSGED50  = 4230
+)
+
+// ed50ToWGS84 is the mean European ED50 translation, accurate to a few metres in Turkey
+var ed50ToWGS84 = [3]float64{-87, -98, -121}
+
+var supportedCRS = buildSupportedCRS()
+
+func buildSupportedCRS() map[int]CRS {
+	systems := map[int]CRS{
+		EPSGWGS84: {Code: EPSGWGS84, Name: "WGS 84", ellipsoid: ellipsoidWGS84},
+		EPSGED50:  {Code: EPSGED50, Name: "ED50", ellipsoid: ellipsoidInternational, toWGS84: ed50ToWGS84},
+	}
+
+	// ITRF96 / TM27 .. TM45 (3 degree zones) and ED50 / TM27 .. TM45
+	for i, meridian := 0, 27; meridian <= 45; i, meridian = i+1, meridian+3 {
+		systems[5253+i] = CRS{
+			Code:            5253 + i,
+			Name:            fmt.Sprintf("ITRF96 / TM%d", meridian),
+			ellipsoid:       ellipsoidGRS80,
+			projected:       true,
+			centralMeridian: float64(meridian),
+			scale:           1,
+			falseEasting:    500000,
+		}
+		systems[2319+i] = CRS{
+			Code:            2319 + i,
+			Name:            fmt.Sprintf("ED50 / TM%d", meridian),
+			ellipsoid:       ellipsoidInternational,
+			toWGS84:         ed50ToWGS84,
+			projected:       true,
+			centralMeridian: float64(meridian),
+			scale:           1,
+			falseEasting:    500000,
+		}
+	}
+
+	// ED50 / UTM zone 35N .. 38N (6 degree zones) for older plans
+	for zone := 35; zone <= 38; zone++ {
+		systems[23000+zone] = CRS{
+			Code:            23000 + zone,
+			Name:            fmt.Sprintf("ED50 / UTM zone %dN", zone),
+			ellipsoid:       ellipsoidInternational,
+			toWGS84:         ed50ToWGS84,
+			projected:       true,
+			centralMeridian: float64(zone*6 - 183),
+			scale:           0.9996,
+			falseEasting:    500000,
+		}
+	}
+
+	return systems
+}
+
+// parseCRS accepts "EPSG:5254", "5254", OGC URNs and CRS84
+func parseCRS(name string) (CRS, error) {
+	name = strings.TrimSpace(name)
+
+	if name == "" || strings.HasSuffix(strings.ToUpper(name), "CRS84") {
+		return supportedCRS[EPSGWGS84], nil
+	}
+
+	// urn:ogc:def:crs:EPSG::5254 and EPSG:5254 both end with the code
+	code, err := strconv.Atoi(name[strings.LastIndex(name, ":")+1:])
+	if err != nil {
+		return CRS{}, fmt.Errorf("invalid coordinate system: %s", name)
+	}
+
+	crs, ok := supportedCRS[code]
+	if !ok {
+		return CRS{}, fmt.Errorf("unsupported coordinate system: EPSG:%d", code)
+	}
+
+	return crs, nil
+}
+
+// tmZoneForLongitude returns the ITRF96 3 degree TM zone whose central meridian is closest to lon
+func tmZoneForLongitude(lon float64) CRS {
+	meridian := int(math.Round(lon/3)) * 3
+	meridian = max(27, min(45, meridian))
+
+	return supportedCRS[5253+(meridian-27)/3]
+}
+
+// resolveTargetCRS turns a setting like "auto" or "EPSG:5254" into a CRS, auto picks the TM
+// zone of the collection's first vertex
+func resolveTargetCRS(setting string, collection FeatureCollection) (CRS, error) {
+	if setting != "" && !strings.EqualFold(setting, "auto") {
+		return parseCRS(setting)
+	}
+
+	source, err := collectionCRS(collection)
+	if err != nil {
+		return CRS{}, err
+	}
+
+	for _, feature := range collection.Features {
+		for _, ring := range feature.Geometry.Coordinates {
+			for _, point := range ring {
+				if len(point) < 2 {
+					continue
+				}
+
+				lon, _, err := toWGS84(source, point[0], point[1])
+				if err != nil {
+					return CRS{}, err
+				}
+
+				return tmZoneForLongitude(lon), nil
+			}
+		}
+	}
+
+	return CRS{}, fmt.Errorf("no coordinates to choose a zone from")
+}
+
+func collectionCRS(collection FeatureCollection) (CRS, error) {
+	return parseCRS(collection.CRS.Properties.Name)
+}
+
+// transformCollection returns a copy of collection with every coordinate converted to target
+func transformCollection(collection FeatureCollection, target CRS) (FeatureCollection, error) {
+	source, err := collectionCRS(collection)
+	if err != nil {
+		return FeatureCollection{}, err
+	}
+
+	result := collection
+	result.CRS.Type = "name"
+	result.CRS.Properties.Name = fmt.Sprintf("EPSG:%d", target.Code)
+	result.Features = make([]Feature, len(collection.Features))
+
+	for i, feature := range collection.Features {
+		rings := make([][][]float64, len(feature.Geometry.Coordinates))
+
+		for j, ring := range feature.Geometry.Coordinates {
+			rings[j] = make([][]float64, 0, len(ring))
+
+			for _, point := range ring {
+				if len(point) < 2 {
+					continue
+				}
+
+				x, y, err := transformPoint(source, target, point[0], point[1])
+				if err != nil {
+					return FeatureCollection{}, err
+				}
+
+				rings[j] = append(rings[j], []float64{x, y})
+			}
+		}
+
+		feature.Geometry.Coordinates = rings
+		result.Features[i] = feature
+	}
+
+	return result, nil
+}
+
+// transformPoint converts x/y (lon/lat for geographic systems) from one system to another
+func transformPoint(from, to CRS, x, y float64) (float64, float64, error) {
+	if from.Code == to.Code {
+		return x, y, nil
+	}
+
+	lon, lat, err := toWGS84(from, x, y)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	return fromWGS84(to, lon, lat)
+}
+
+func toWGS84(crs CRS, x, y float64) (float64, float64, error) {
+	lon, lat := x, y
+	if crs.projected {
+		lon, lat = crs.inverseTM(x, y)
+	}
+
+	if crs.toWGS84 != [3]float64{} {
+		lon, lat = shiftDatum(lon, lat, crs.ellipsoid, ellipsoidWGS84, crs.toWGS84, 1)
+	}
+
+	if math.IsNaN(lon) || math.IsNaN(lat) {
+		return 0, 0, fmt.Errorf("coordinate %f,%f can't be converted from %s", x, y, crs.Name)
+	}
+
+	return lon, lat, nil
+}
+
+func fromWGS84(crs CRS, lon, lat float64) (float64, float64, error) {
+	if crs.toWGS84 != [3]float64{} {
+		lon, lat = shiftDatum(lon, lat, ellipsoidWGS84, crs.ellipsoid, crs.toWGS84, -1)
+	}
+
+	x, y := lon, lat
+	if crs.projected {
+		x, y = crs.forwardTM(lon, lat)
+	}
+
+	if math.IsNaN(x) || math.IsNaN(y) {
+		return 0, 0, fmt.Errorf("coordinate %f,%f can't be converted to %s", lon, lat, crs.Name)
+	}
+
+	return x, y, nil
+}
+
+// shiftDatum moves a geographic coordinate between datums through geocentric coordinates,
+// sign is 1 to apply the translation and -1 to undo it
+func shiftDatum(lon, lat float64, from, to ellipsoid, translation [3]float64, sign float64) (float64, float64) {
+	X, Y, Z := geodeticToGeocentric(lon, lat, from)
+
+	X += sign * translation[0]
+	Y += sign * translation[1]
+	Z += sign * translation[2]
+
+	return geocentricToGeodetic(X, Y, Z, to)
+}
+
+func geodeticToGeocentric(lon, lat float64, e ellipsoid) (float64, float64, float64) {
+	phi := lat * math.Pi / 180
+	lambda := lon * math.Pi / 180
+	e2 := e.f * (2 - e.f)
+	N := e.a / math.Sqrt(1-e2*math.Sin(phi)*math.Sin(phi))
+
+	return N * math.Cos(phi) * math.Cos(lambda),
+		N * math.Cos(phi) * math.Sin(lambda),
+		N * (1 - e2) * math.Sin(phi)
+}
+
+func geocentricToGeodetic(X, Y, Z float64, e ellipsoid) (float64, float64) {
+	e2 := e.f * (2 - e.f)
+	p := math.Hypot(X, Y)
+	lambda := math.Atan2(Y, X)
+
+	// Converges to well below a millimetre in a few iterations at the Earth's surface
+	phi := math.Atan2(Z, p*(1-e2))
+	for i := 0; i < 10; i++ {
+		N := e.a / math.Sqrt(1-e2*math.Sin(phi)*math.Sin(phi))
+		next := math.Atan2(Z+e2*N*math.Sin(phi), p)
+		if math.Abs(next-phi) < 1e-12 {
+			phi = next
+			break
+		}
+		phi = next
+	}
+
+	return lambda * 180 / math.Pi, phi * 180 / math.Pi
+}
+
+// krugerCoefficients are the 4th order series coefficients of the Krüger transverse Mercator
+// formulas, good to well under a millimetre within a zone
+type krugerCoefficients struct {
+	A     float64
+	alpha [4]float64
+	beta  [4]float64
+	delta [4]float64
+}
+
+func (e ellipsoid) kruger() krugerCoefficients {
+	n := e.f / (2 - e.f)
+	n2, n3, n4 := n*n, n*n*n, n*n*n*n
+
+	return krugerCoefficients{
+		A: e.a / (1 + n) * (1 + n2/4 + n4/64),
+		alpha: [4]float64{
+			n/2 - 2*n2/3 + 5*n3/16 + 41*n4/180,
+			13*n2/48 - 3*n3/5 + 557*n4/1440,
+			61*n3/240 - 103*n4/140,
+			49561 * n4 / 161280,
+		},
+		beta: [4]float64{
+			n/2 - 2*n2/3 + 37*n3/96 - n4/360,
+			n2/48 + n3/15 - 437*n4/1440,
+			17*n3/480 - 37*n4/840,
+			4397 * n4 / 161280,
+		},
+		delta: [4]float64{
+			2*n - 2*n2/3 - 2*n3 + 116*n4/45,
+			7*n2/3 - 8*n3/5 - 227*n4/45,
+			56*n3/15 - 136*n4/35,
+			4279 * n4 / 630,
+		},
+	}
+}
+
+func (crs CRS) forwardTM(lon, lat float64) (float64, float64) {
+	k := crs.ellipsoid.kruger()
+	n := crs.ellipsoid.f / (2 - crs.ellipsoid.f)
+	c := 2 * math.Sqrt(n) / (1 + n)
+
+	phi := lat * math.Pi / 180
+	dLambda := (lon - crs.centralMeridian) * math.Pi / 180
+
+	t := math.Sinh(math.Atanh(math.Sin(phi)) - c*math.Atanh(c*math.Sin(phi)))
+	xi := math.Atan2(t, math.Cos(dLambda))
+	eta := math.Atanh(math.Sin(dLambda) / math.Sqrt(1+t*t))
+
+	E, N := eta, xi
+	for j := 1; j <= 4; j++ {
+		E += k.alpha[j-1] * math.Cos(2*float64(j)*xi) * math.Sinh(2*float64(j)*eta)
+		N += k.alpha[j-1] * math.Sin(2*float64(j)*xi) * math.Cosh(2*float64(j)*eta)
+	}
+
+	return crs.falseEasting + crs.scale*k.A*E, crs.falseNorthing + crs.scale*k.A*N
+}
+
+func (crs CRS) inverseTM(x, y float64) (float64, float64) {
+	k := crs.ellipsoid.kruger()
+
+	xi := (y - crs.falseNorthing) / (crs.scale * k.A)
+	eta := (x - crs.falseEasting) / (crs.scale * k.A)
+
+	xiP, etaP := xi, eta
+	for j := 1; j <= 4; j++ {
+		xiP -= k.beta[j-1] * math.Sin(2*float64(j)*xi) * math.Cosh(2*float64(j)*eta)
+		etaP -= k.beta[j-1] * math.Cos(2*float64(j)*xi) * math.Sinh(2*float64(j)*eta)
+	}
+
+	chi := math.Asin(math.Sin(xiP) / math.Cosh(etaP))
+
+	phi := chi
+	for j := 1; j <= 4; j++ {
+		phi += k.delta[j-1] * math.Sin(2*float64(j)*chi)
+	}
+
+	lambda := crs.centralMeridian*math.Pi/180 + math.Atan2(math.Sinh(etaP), math.Cos(xiP))
+
+	return lambda * 180 / math.Pi, phi * 180 / math.Pi
+}
+
+// GetSupportedCRS lists the coordinate systems parcel geometries can be converted to
+func (app *App) GetSupportedCRS() []CRS {
+	systems := make([]CRS, 0, len(supportedCRS))
+	for _, crs := range supportedCRS {
+		systems = append(systems, crs)
+	}
+
+	sort.Slice(systems, func(i, j int) bool {
+		return systems[i].Code < systems[j].Code
+	})
+
+	return systems
+}
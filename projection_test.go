@@ -0,0 +1,62 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"testing"
+)
+
+// The expected coordinates were computed independently with the Snyder (USGS Professional Paper
+// 1395) transverse Mercator series and the same ED50 translation, they agree with the Krüger
+// series to a tenth of a millimetre this close to the central meridian
+func TestTransformKnownPoints(t *testing.T) {
+	tests := []struct {
+		name     string
+		code     int
+		lon, lat float64
+		x, y     float64
+	}{
+		{"Antalya ITRF96 / TM30", 5254, 30.7133, 36.8969, 563578.1100, 4085306.8892},
+		{"Antalya ED50 / TM30", 2320, 30.7133, 36.8969, 563619.9392, 4085483.1813},
+		{"Konya ED50 / UTM 36N", 23036, 32.4846, 37.8746, 454706.6495, 4192202.7073},
+		{"Konya ITRF96 / TM33", 5255, 32.4846, 37.8746, 454654.0212, 4193704.4257},
+	}
+
+	wgs84 := supportedCRS[EPSGWGS84]
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			crs, err := parseCRS(fmt.Sprintf("EPSG:%d", test.code))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			x, y, err := transformPoint(wgs84, crs, test.lon, test.lat)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if math.Abs(x-test.x) > 0.01 || math.Abs(y-test.y) > 0.01 {
+				t.Errorf("forward = %.4f, %.4f, want %.4f, %.4f", x, y, test.x, test.y)
+			}
+
+			// Back to WGS84 within a centimetre, about 1e-7 degrees
+			lon, lat, err := transformPoint(crs, wgs84, x, y)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if math.Abs(lon-test.lon) > 1e-7 || math.Abs(lat-test.lat) > 1e-7 {
+				t.Errorf("round trip = %.9f, %.9f, want %.9f, %.9f", lon, lat, test.lon, test.lat)
+			}
+		})
+	}
+}
+
+func TestTMZoneForLongitude(t *testing.T) {
+	tests := map[float64]int{26.1: 5253, 30.7133: 5254, 32.4846: 5255, 44.9: 5259, 46: 5259}
+
+	for lon, code := range tests {
+		if got := tmZoneForLongitude(lon).Code; got != code {
+			t.Errorf("tmZoneForLongitude(%v) = EPSG:%d, want EPSG:%d", lon, got, code)
+		}
+	}
+}
@@ -2,24 +2,11 @@ package main
 
 import (
 	"fmt"
-	"strconv"
 	"strings"
 
 	"github.com/wailsapp/wails/v2/pkg/runtime"
 )
 
-func parseHeaderChangePattern(pattern string) map[string]string {
-	mapping := make(map[string]string)
-	pairs := strings.Split(pattern, ",")
-	for _, pair := range pairs {
-		kv := strings.Split(pair, ":")
-		if len(kv) == 2 {
-			mapping[kv[0]] = kv[1]
-		}
-	}
-	return mapping
-}
-
 func parceCellChangePattern(pattern string) map[string]string {
 	mapping := make(map[string]string)
 	pairs := strings.Split(pattern, ",")
@@ -32,20 +19,35 @@ func parceCellChangePattern(pattern string) map[string]string {
 	return mapping
 }
 
-func (app *App) ModifyExcelWithTakbis(excelPath string, takbisPaths []string, headerMatchPattern string, cellChangeRule string) string {
-	excelHeaders, excelRows, excel, err := ReadExcel(excelPath)
+func (app *App) ModifyExcelWithTakbis(excelPath string, takbisPaths []string, mapping TakbisMapping) string {
+	if message := firstTakbisMappingError(mapping.validate()); message != "" {
+		runtime.LogError(app.ctx, message)
+		return message
+	}
+
+	if err := checkExcelSavable(excelPath); err != nil {
+		runtime.LogError(app.ctx, err.Error())
+		return excelSaveError(err)
+	}
+
+	checkpoint, readPath := newExcelCheckpoint(ExcelTaskTakbis, excelPath)
+
+	excelHeaders, excelRows, excel, err := ReadExcel(readPath)
 	if err != nil {
 		return err.Error()
 	}
 	sheetName := excel.GetSheetList()[0]
 
-	headerMatchMap := parseHeaderChangePattern(headerMatchPattern)
-	cellChangeMap := parceCellChangePattern(cellChangeRule)
+	if *config.TakbisReportSheet == sheetName {
+		return "Rapor sayfası hedef Excel'in ilk sayfasıyla aynı olamaz"
+	}
+
+	cellRules := mapping.Cells
 
 	runtime.LogInfo(app.ctx, "Modifying Excel with Takbis")
 
-	runtime.LogInfo(app.ctx, "Header match map: "+fmt.Sprint(headerMatchMap))
-	runtime.LogInfo(app.ctx, "Cell change map: "+fmt.Sprint(cellChangeMap))
+	runtime.LogInfo(app.ctx, fmt.Sprintf("Match rules: %+v", mapping.Match))
+	runtime.LogInfo(app.ctx, fmt.Sprintf("Cell change rules: %+v", cellRules))
 
 	// Create reverse maps for quick lookup
 	excelHeaderIdx := make(map[string]int)
@@ -56,6 +58,12 @@ func (app *App) ModifyExcelWithTakbis(excelPath string, takbisPaths []string, he
 
 	runtime.LogInfo(app.ctx, "Excel header index: "+fmt.Sprint(excelHeaderIdx))
 
+	writer, err := newExcelCellWriter(excel, sheetName, excelHeaders)
+	if err != nil {
+		runtime.LogError(app.ctx, err.Error())
+		return err.Error()
+	}
+
 	// Put takbis rows and headers into a map
 	takbisHeadersList := make([][]string, 0)
 	takbisRowsList := make([][][]string, 0)
@@ -77,115 +85,133 @@ func (app *App) ModifyExcelWithTakbis(excelPath string, takbisPaths []string, he
 
 	runtime.LogDebug(app.ctx, "Takbis headers and rows are read")
 
+	issues := mapping.validateHeaders(excelHeaders, takbisPaths, takbisHeadersList)
+	for _, issue := range issues {
+		runtime.LogWarning(app.ctx, issue.Message)
+	}
+	if message := firstTakbisMappingError(issues); message != "" {
+		return message
+	}
+
+	matcher := newTakbisMatcher(mapping.Match, takbisHeadersList, takbisRowsList)
+
+	runtime.LogDebug(app.ctx, "Takbis files are indexed")
+
+	// A row is filled once every target column of the cell change rule has a value
+	filled := func(excelRow []string) bool {
+		for _, cellRule := range cellRules {
+			targetIdx, ok := excelHeaderIdx[cellRule.Target]
+			if !ok || targetIdx >= len(excelRow) || strings.TrimSpace(excelRow[targetIdx]) == "" {
+				return false
+			}
+		}
+		return len(cellRules) > 0
+	}
+
+	var report []TakbisRowResult
+	defer func() {
+		lastTakbisReport = report
+	}()
+
+	skipped, unmatched, ambiguous, relaxed := 0, 0, 0, 0
+
+	aggregated := false
+	for _, cellRule := range cellRules {
+		if cellRule.Aggregate != "" {
+			aggregated = true
+		}
+	}
+
 	for excelRowNumber := 0; excelRowNumber < len(excelRows); excelRowNumber++ {
 		runtime.WindowExecJS(appContext, `window.setTakbisMessage("`+fmt.Sprintf("%d/%d", excelRowNumber, len(excelRows))+`");`)
 		excelRow := excelRows[excelRowNumber]
 
-		for i := 0; i < 2; i++ {
+		if checkpoint.isDone(excelRowNumber) || (*config.ExcelResume && filled(excelRow)) {
+			skipped++
+			continue
+		}
 
-			for takbisNumber := 0; takbisNumber < len(takbisHeadersList); takbisNumber++ {
-				breakOut := false
-				takbisHeaders := takbisHeadersList[takbisNumber]
-				takbisRows := takbisRowsList[takbisNumber]
+		match, ok := matcher.match(excelRow, excelHeaderIdx)
 
-				takbisHeaderIdx := make(map[string]int)
-				for i, header := range takbisHeaders {
-					takbisHeaderIdx[header] = i
-				}
+		result := newTakbisRowResult(excelRowNumber, matcher.describe(excelRow, excelHeaderIdx), match, ok, aggregated, takbisPaths)
+		report = append(report, result)
 
-				for takbisRowNumber := 0; takbisRowNumber < len(takbisRows); takbisRowNumber++ {
-					takbisRow := takbisRows[takbisRowNumber]
-					rowMatch := true
-
-					for targetHeader, takbisHeader := range headerMatchMap {
-						targetIdx, ok1 := excelHeaderIdx[targetHeader]
-						takbisIdx, ok2 := takbisHeaderIdx[takbisHeader]
-
-						if strings.TrimSpace(excelRow[targetIdx]) == "" {
-							rowMatch = false
-							break
-						}
-
-						if ok1 && ok2 && targetIdx < len(excelRow) && takbisIdx < len(takbisRow) {
-							if i == 1 {
-								if !LooseEqualWithoutLastWord(takbisRow[takbisIdx], excelRow[targetIdx]) {
-									rowMatch = false
-									break
-								}
-							} else {
-								if !LooseEqual(excelRow[targetIdx], takbisRow[takbisIdx]) {
-									rowMatch = false
-									break
-								}
-							}
-						} else {
-							rowMatch = false
-							break
-						}
-					}
-
-					if rowMatch {
-						i++
-						runtime.LogDebugf(app.ctx, "Matched row in target Excel: %s\nwith row in Takbis Excel: %s", excelRow, takbisRow)
-
-						// Print comparison values
-						for targetHeader, takbisHeader := range headerMatchMap {
-							targetIdx, ok1 := excelHeaderIdx[targetHeader]
-							takbisIdx, ok2 := takbisHeaderIdx[takbisHeader]
-
-							if ok1 && ok2 && targetIdx < len(excelRow) && takbisIdx < len(takbisRow) {
-								runtime.LogDebugf(app.ctx, "%s:%s", excelRow[targetIdx], takbisRow[takbisIdx])
-							}
-						}
-
-						// Update the cells in the target Excel row based on cellChangeRule
-						for takbisHeader, targetHeader := range cellChangeMap {
-							takbisIdx, ok1 := takbisHeaderIdx[takbisHeader]
-							targetIdx, ok2 := excelHeaderIdx[targetHeader]
-
-							if ok1 && ok2 && takbisIdx < len(takbisRow) && targetIdx < len(excelRow) {
-								isFloat := false
-
-								temp := takbisRow[takbisIdx]
-								temp = strings.ReplaceAll(temp, ",", ".")
-
-								floatValue, err := strconv.ParseFloat(temp, 64)
-								if err == nil {
-									isFloat = true
-								}
-
-								if isFloat {
-									excel.SetCellFloat(sheetName, alphabet[targetIdx]+fmt.Sprintf("%v", excelRowNumber+2), floatValue, 2, 64)
-								} else {
-									excel.SetCellDefault(sheetName, alphabet[targetIdx]+fmt.Sprintf("%v", excelRowNumber+2), takbisRow[takbisIdx])
-								}
-							}
-						}
-
-						breakOut = true
-						break
-					}
-				}
+		if !ok {
+			unmatched++
+			runtime.LogWarningf(app.ctx, "No takbis row matched row %d", excelRowNumber+2)
+			continue
+		}
+
+		if len(match.candidates) > 1 && !aggregated {
+			ambiguous++
+			runtime.LogWarningf(app.ctx, "%d takbis rows matched row %d: %s", len(match.candidates), excelRowNumber+2, strings.Join(result.Candidates, ", "))
+		}
+		if match.relaxed {
+			relaxed++
+		}
+
+		takbisRow := match.values()
+		runtime.LogDebugf(app.ctx, "Matched row in target Excel: %s\nwith row %d of Takbis Excel %d: %s", excelRow, match.row+2, match.file+1, takbisRow)
 
-				if breakOut {
-					break
+		// Update the cells in the target Excel row based on cellChangeRule, aggregated rules use
+		// every candidate row
+		for _, cellRule := range cellRules {
+			targetIdx, ok := excelHeaderIdx[cellRule.Target]
+			if !ok {
+				continue
+			}
+
+			value, err := cellRule.value(matcher, match)
+			if err != nil {
+				runtime.LogWarningf(app.ctx, "Row %d: %s", excelRowNumber+2, err.Error())
+				continue
+			}
+			if value == nil {
+				continue
+			}
+
+			// Whole numbers like ada/parsel numbers are written as numbers when that loses
+			// nothing, other text only becomes a number through a type rule of the column
+			if text, ok := value.(string); ok {
+				if number, ok := excelNumberText(text); ok {
+					value = number
 				}
 			}
+			if err := writer.set(targetIdx, excelRowNumber+2, value); err != nil {
+				runtime.LogError(app.ctx, err.Error())
+			}
+		}
 
+		if err := checkpoint.markDone(excel, excelRowNumber); err != nil {
+			runtime.LogError(app.ctx, err.Error())
+			return excelSaveError(err)
 		}
+	}
 
+	if *config.TakbisReportSheet != "" {
+		if err := writeTakbisReportSheet(excel, *config.TakbisReportSheet, report, checkpoint.resumed); err != nil {
+			runtime.LogError(app.ctx, err.Error())
+			return err.Error()
+		}
 	}
 
 	runtime.LogInfo(app.ctx, "Attempting to save Excel file")
 
-	err = excel.SaveAs(excelPath)
+	savedPath, err := checkpoint.finish(excel)
 
 	if err != nil {
 		runtime.LogError(app.ctx, err.Error())
-		return err.Error()
+		return excelSaveError(err)
+	}
+
+	runtime.LogInfo(app.ctx, "Saved "+savedPath)
+
+	message := "Excel dosyası güncellendi" + resumeMessage(skipped)
+	if unmatched+ambiguous+relaxed > 0 {
+		message += fmt.Sprintf(" (eşleşmeyen: %d, birden fazla aday: %d, son kelime hariç: %d)", unmatched, ambiguous, relaxed)
 	}
 
-	runtime.WindowExecJS(appContext, `window.setTakbisMessage("Excel dosyası güncellendi");`)
+	runtime.WindowExecJS(appContext, `window.setTakbisMessage("`+message+`");`)
 	return ""
 }
 
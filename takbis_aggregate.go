@@ -0,0 +1,172 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Aggregations of a TAKBIS cell change rule, applied to every TAKBIS row matching a target row
+const (
+	TakbisAggregateJoin  = "join"  // distinct values joined with a separator, ", " by default
+	TakbisAggregateSum   = "sum"   // sum of the numbers
+	TakbisAggregateCount = "count" // number of rows with a value
+	TakbisAggregateFirst = "first" // first value
+	TakbisAggregateLast  = "last"  // last value
+)
+
+// TakbisCellRule copies a TAKBIS column to a target column. Without an aggregation only the
+// chosen TAKBIS row is copied.
+type TakbisCellRule struct {
+	Takbis    string `json:"takbis"`
+	Target    string `json:"target"`
+	Aggregate string `json:"aggregate"` // empty, join, sum, count, first or last
+	Separator string `json:"separator"` // for join, ", " if empty
+}
+
+// splitOutsideQuotes splits s on sep, ignoring separators inside double quotes
+func splitOutsideQuotes(s string, sep rune) []string {
+	var parts []string
+	var current strings.Builder
+	quoted := false
+
+	for _, r := range s {
+		switch {
+		case r == '"':
+			quoted = !quoted
+		case r == sep && !quoted:
+			parts = append(parts, current.String())
+			current.Reset()
+			continue
+		}
+		current.WriteRune(r)
+	}
+
+	return append(parts, current.String())
+}
+
+// parseTakbisCellRules reads rules like `Malik->Malikler | join:"; ",Hisse Alan->Alan | sum`.
+// Without "->" the target column has the name of the TAKBIS column.
+func parseTakbisCellRules(rule string) ([]TakbisCellRule, error) {
+	var rules []TakbisCellRule
+
+	for _, pair := range splitOutsideQuotes(rule, ',') {
+		if strings.TrimSpace(pair) == "" {
+			continue
+		}
+
+		parts := splitOutsideQuotes(pair, '|')
+		if len(parts) > 2 {
+			return nil, fmt.Errorf("invalid cell change rule: %s", pair)
+		}
+
+		columns := strings.Split(parts[0], "->")
+		if len(columns) > 2 || strings.TrimSpace(columns[0]) == "" {
+			return nil, fmt.Errorf("invalid cell change rule: %s", pair)
+		}
+
+		cellRule := TakbisCellRule{Takbis: strings.TrimSpace(columns[0]), Target: strings.TrimSpace(columns[0])}
+		if len(columns) == 2 {
+			cellRule.Target = strings.TrimSpace(columns[1])
+		}
+		if cellRule.Target == "" {
+			return nil, fmt.Errorf("invalid cell change rule: %s", pair)
+		}
+
+		if len(parts) == 2 {
+			name, argument, hasArgument := strings.Cut(strings.TrimSpace(parts[1]), ":")
+			cellRule.Aggregate = strings.ToLower(strings.TrimSpace(name))
+
+			switch cellRule.Aggregate {
+			case TakbisAggregateJoin:
+				cellRule.Separator = ", "
+				if hasArgument {
+					cellRule.Separator = unquoteRuleArgument(argument)
+				}
+			case TakbisAggregateSum, TakbisAggregateCount, TakbisAggregateFirst, TakbisAggregateLast:
+				if hasArgument {
+					return nil, fmt.Errorf("%s takes no argument: %s", cellRule.Aggregate, pair)
+				}
+			default:
+				return nil, fmt.Errorf("unknown aggregation %s, expected one of join, sum, count, first, last", cellRule.Aggregate)
+			}
+		}
+
+		rules = append(rules, cellRule)
+	}
+
+	return rules, nil
+}
+
+// unquoteRuleArgument strips the quotes of an argument like "; ", unquoted arguments are trimmed
+func unquoteRuleArgument(argument string) string {
+	argument = strings.TrimSpace(argument)
+	if unquoted, err := strconv.Unquote(argument); err == nil {
+		return unquoted
+	}
+	return argument
+}
+
+// value returns what the rule writes for a match, nil if there's nothing to write
+func (r TakbisCellRule) value(matcher *takbisMatcher, match takbisMatch) (interface{}, error) {
+	if r.Aggregate == "" {
+		column, ok := match.index.headers[r.Takbis]
+		if !ok || column >= len(match.values()) {
+			return nil, nil
+		}
+		return match.values()[column], nil
+	}
+
+	var values []string
+	for _, candidate := range match.candidates {
+		index := matcher.indexes[candidate.file]
+		row := index.rows[candidate.row]
+
+		column, ok := index.headers[r.Takbis]
+		if !ok || column >= len(row) || strings.TrimSpace(row[column]) == "" {
+			continue
+		}
+		values = append(values, strings.TrimSpace(row[column]))
+	}
+
+	switch r.Aggregate {
+	case TakbisAggregateCount:
+		return len(values), nil
+	case TakbisAggregateSum:
+		sum := 0.0
+		for _, value := range values {
+			number, err := excelNumber(value)
+			if err != nil {
+				return nil, fmt.Errorf("can't sum %s, %s is not a number", r.Takbis, value)
+			}
+			sum += number
+		}
+		return sum, nil
+	}
+
+	if len(values) == 0 {
+		return nil, nil
+	}
+
+	switch r.Aggregate {
+	case TakbisAggregateFirst:
+		return values[0], nil
+	case TakbisAggregateLast:
+		return values[len(values)-1], nil
+	default:
+		// Values differing only in case or surrounding spaces are the same, the first one is kept
+		var distinct []string
+		seen := make(map[string]bool)
+		for _, value := range values {
+			if key := takbisLooseKey(value); !seen[key] {
+				seen[key] = true
+				distinct = append(distinct, value)
+			}
+		}
+		separator := r.Separator
+		if separator == "" {
+			separator = ", "
+		}
+		return strings.Join(distinct, separator), nil
+	}
+}
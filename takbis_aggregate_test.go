@@ -0,0 +1,83 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestSplitOutsideQuotes(t *testing.T) {
+	got := splitOutsideQuotes(`Malik | join:", ",Hisse Alan | sum`, ',')
+	want := []string{`Malik | join:", "`, `Hisse Alan | sum`}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("splitOutsideQuotes = %q, want %q", got, want)
+	}
+}
+
+func TestParseTakbisCellRules(t *testing.T) {
+	rules, err := parseTakbisCellRules(`Malik->Malikler | join:"; ",Hisse Alan->Alan | SUM,Cilt,Malik->İlk | first,Malik->Virgül | join:", "`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := []TakbisCellRule{
+		{Takbis: "Malik", Target: "Malikler", Aggregate: TakbisAggregateJoin, Separator: "; "},
+		{Takbis: "Hisse Alan", Target: "Alan", Aggregate: TakbisAggregateSum},
+		{Takbis: "Cilt", Target: "Cilt"},
+		{Takbis: "Malik", Target: "İlk", Aggregate: TakbisAggregateFirst},
+		{Takbis: "Malik", Target: "Virgül", Aggregate: TakbisAggregateJoin, Separator: ", "},
+	}
+	if !reflect.DeepEqual(rules, want) {
+		t.Errorf("rules = %+v, want %+v", rules, want)
+	}
+
+	for _, rule := range []string{
+		"Malik->Malikler | avg",
+		"Malik | sum:2",
+		"Malik | join | sum",
+		"->Malikler",
+		"A->B->C",
+	} {
+		if _, err := parseTakbisCellRules(rule); err == nil {
+			t.Errorf("parseTakbisCellRules(%q) was accepted", rule)
+		}
+	}
+}
+
+func TestTakbisCellRuleValue(t *testing.T) {
+	headers := []string{"Ada", "Malik", "Hisse Alan"}
+	matcher := newTakbisMatcher([]TakbisMatchRule{{Target: "Ada", Takbis: "Ada"}},
+		[][]string{headers, headers},
+		[][][]string{
+			{{"12", "Ali Veli", "0.125"}, {"12", "AYŞE", "1234.567"}},
+			{{"12", "ali veli", "1.000,5"}, {"12", "", ""}},
+		})
+
+	match, ok := matcher.match([]string{"12"}, map[string]int{"Ada": 0})
+	if !ok {
+		t.Fatal("target row didn't match")
+	}
+
+	tests := []struct {
+		rule TakbisCellRule
+		want interface{}
+	}{
+		{TakbisCellRule{Takbis: "Malik"}, "Ali Veli"},
+		{TakbisCellRule{Takbis: "Hisse Alan", Aggregate: TakbisAggregateSum}, 0.125 + 1234.567 + 1000.5},
+		{TakbisCellRule{Takbis: "Malik", Aggregate: TakbisAggregateCount}, 3},
+		{TakbisCellRule{Takbis: "Malik", Aggregate: TakbisAggregateFirst}, "Ali Veli"},
+		{TakbisCellRule{Takbis: "Malik", Aggregate: TakbisAggregateLast}, "ali veli"},
+		{TakbisCellRule{Takbis: "Malik", Aggregate: TakbisAggregateJoin, Separator: "; "}, "Ali Veli; AYŞE"},
+		{TakbisCellRule{Takbis: "Yok", Aggregate: TakbisAggregateFirst}, nil},
+	}
+
+	for _, tt := range tests {
+		got, err := tt.rule.value(matcher, match)
+		if err != nil || !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%+v = %#v, %v, want %#v", tt.rule, got, err, tt.want)
+		}
+	}
+
+	if _, err := (TakbisCellRule{Takbis: "Malik", Aggregate: TakbisAggregateSum}).value(matcher, match); err == nil {
+		t.Error("sum of names was accepted")
+	}
+}
@@ -0,0 +1,189 @@
+package main
+
+import (
+	"strings"
+)
+
+// takbisKeySeparator joins the values of the matched columns into one key, it can't appear in a cell
+const takbisKeySeparator = "\x00"
+
+// takbisLooseKey normalizes a value so two values have the same key exactly when LooseEqual
+// holds for them
+func takbisLooseKey(value string) string {
+	return toTitleCaseWord(strings.TrimSpace(value))
+}
+
+// takbisRelaxedKey is the key of a TAKBIS value for the relaxed pass, it equals the loose key of
+// a target value exactly when LooseEqualWithoutLastWord(value, target) holds
+func takbisRelaxedKey(value string) string {
+	value = strings.TrimSpace(value)
+
+	words := strings.Split(value, " ")
+	if len(words) > 1 {
+		value = strings.Join(words[:len(words)-1], " ")
+	}
+
+	return takbisLooseKey(value)
+}
+
+// takbisIndex maps the match keys of a TAKBIS file to its rows with that key in file order, once
+// for the exact pass and once for the relaxed one
+type takbisIndex struct {
+	headers map[string]int
+	rows    [][]string
+	exact   map[string][]int
+	relaxed map[string][]int
+}
+
+// newTakbisIndex indexes rows by the columns in sources, rows missing one of them are left out.
+// Returns nil if the file doesn't have every column, no row of it can match then.
+func newTakbisIndex(headers []string, rows [][]string, sources []string) *takbisIndex {
+	index := &takbisIndex{
+		headers: make(map[string]int, len(headers)),
+		rows:    rows,
+		exact:   make(map[string][]int, len(rows)),
+		relaxed: make(map[string][]int, len(rows)),
+	}
+
+	for i, header := range headers {
+		index.headers[header] = i
+	}
+
+	columns := make([]int, len(sources))
+	for i, source := range sources {
+		column, ok := index.headers[source]
+		if !ok {
+			return nil
+		}
+		columns[i] = column
+	}
+
+	exact := make([]string, len(columns))
+	relaxed := make([]string, len(columns))
+
+	for rowNumber, row := range rows {
+		complete := true
+		for i, column := range columns {
+			if column >= len(row) {
+				complete = false
+				break
+			}
+			exact[i] = takbisLooseKey(row[column])
+			relaxed[i] = takbisRelaxedKey(row[column])
+		}
+		if !complete {
+			continue
+		}
+
+		exactKey := strings.Join(exact, takbisKeySeparator)
+		index.exact[exactKey] = append(index.exact[exactKey], rowNumber)
+
+		relaxedKey := strings.Join(relaxed, takbisKeySeparator)
+		index.relaxed[relaxedKey] = append(index.relaxed[relaxedKey], rowNumber)
+	}
+
+	return index
+}
+
+// takbisRowRef is a row of a TAKBIS file
+type takbisRowRef struct {
+	file int // index in the TAKBIS files
+	row  int // 0-based data row of the file
+}
+
+// takbisMatch is the TAKBIS row a target row matched. The first candidate is used, like the scan
+// it replaces, the rest are other rows with the same key in the same pass.
+type takbisMatch struct {
+	takbisRowRef
+	relaxed    bool // matched only after dropping the last word of the TAKBIS values
+	candidates []takbisRowRef
+	index      *takbisIndex
+}
+
+// values returns the matched TAKBIS row
+func (m takbisMatch) values() []string {
+	return m.index.rows[m.row]
+}
+
+// takbisMatcher finds the TAKBIS row of a target row by the columns of the header match rule.
+// Every file is tried with the exact pass before any file is tried with the relaxed one.
+type takbisMatcher struct {
+	targets []string // target columns, in the same order as sources
+	sources []string // TAKBIS columns
+	indexes []*takbisIndex
+}
+
+// newTakbisMatcher indexes every TAKBIS file once by the columns of the match rules
+func newTakbisMatcher(match []TakbisMatchRule, takbisHeadersList [][]string, takbisRowsList [][][]string) *takbisMatcher {
+	m := &takbisMatcher{}
+
+	for _, rule := range match {
+		m.targets = append(m.targets, rule.Target)
+		m.sources = append(m.sources, rule.Takbis)
+	}
+
+	for i := range takbisHeadersList {
+		m.indexes = append(m.indexes, newTakbisIndex(takbisHeadersList[i], takbisRowsList[i], m.sources))
+	}
+
+	return m
+}
+
+// match looks up the TAKBIS rows of a target row, rows with an empty or missing match column
+// don't match
+func (m *takbisMatcher) match(excelRow []string, excelHeaderIdx map[string]int) (takbisMatch, bool) {
+	keys := make([]string, len(m.targets))
+
+	for i, target := range m.targets {
+		column, ok := excelHeaderIdx[target]
+		if !ok || column >= len(excelRow) || strings.TrimSpace(excelRow[column]) == "" {
+			return takbisMatch{}, false
+		}
+		keys[i] = takbisLooseKey(excelRow[column])
+	}
+
+	key := strings.Join(keys, takbisKeySeparator)
+
+	for _, relaxed := range []bool{false, true} {
+		var match takbisMatch
+
+		for file, index := range m.indexes {
+			if index == nil {
+				continue
+			}
+
+			lookup := index.exact
+			if relaxed {
+				lookup = index.relaxed
+			}
+
+			for _, row := range lookup[key] {
+				if match.index == nil {
+					match = takbisMatch{takbisRowRef: takbisRowRef{file, row}, relaxed: relaxed, index: index}
+				}
+				match.candidates = append(match.candidates, takbisRowRef{file, row})
+			}
+		}
+
+		if match.index != nil {
+			return match, true
+		}
+	}
+
+	return takbisMatch{}, false
+}
+
+// describe returns the values of the target row's match columns, like "Mahalle=Merkez, Ada=12"
+func (m *takbisMatcher) describe(excelRow []string, excelHeaderIdx map[string]int) string {
+	values := make([]string, len(m.targets))
+
+	for i, target := range m.targets {
+		value := ""
+		if column, ok := excelHeaderIdx[target]; ok && column < len(excelRow) {
+			value = strings.TrimSpace(excelRow[column])
+		}
+		values[i] = target + "=" + value
+	}
+
+	return strings.Join(values, ", ")
+}
@@ -0,0 +1,94 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestTakbisKeysMatchLooseEqual(t *testing.T) {
+	// takbis is the TAKBIS value, target the target Excel's
+	pairs := []struct {
+		takbis, target string
+		exact, relaxed bool
+	}{
+		{"Yeni Mahallesi", "yeni", false, true},
+		{"YENİ MAHALLESİ", "Yeni Mahallesi", true, false},
+		{"  Merkez ", "merkez", true, true},
+		{"Yeni  Mahallesi", "Yeni Mahallesi", false, false},
+		{"Yeni Mahallesi", "Yeni  Mahallesi", false, false},
+		{"İnönü", "inönü", true, true},
+		{"ILGIN", "ılgın", true, true},
+		{"ISPARTA", "İsparta", false, false},
+		{"Kızılca Köyü", "KIZILCA", false, true},
+		{"Tek", "tek", true, true},
+		{"Ada 12", "Ada", false, true},
+		{"a b c", "A B", false, true},
+	}
+
+	for _, p := range pairs {
+		exact := takbisLooseKey(p.takbis) == takbisLooseKey(p.target)
+		if want := LooseEqual(p.target, p.takbis); exact != want {
+			t.Errorf("%q, %q: exact keys equal = %v, LooseEqual = %v", p.takbis, p.target, exact, want)
+		}
+		if exact != p.exact {
+			t.Errorf("%q, %q: exact keys equal = %v, want %v", p.takbis, p.target, exact, p.exact)
+		}
+
+		relaxed := takbisRelaxedKey(p.takbis) == takbisLooseKey(p.target)
+		if want := LooseEqualWithoutLastWord(p.takbis, p.target); relaxed != want {
+			t.Errorf("%q, %q: relaxed keys equal = %v, LooseEqualWithoutLastWord = %v", p.takbis, p.target, relaxed, want)
+		}
+		if relaxed != p.relaxed {
+			t.Errorf("%q, %q: relaxed keys equal = %v, want %v", p.takbis, p.target, relaxed, p.relaxed)
+		}
+	}
+}
+
+func TestTakbisMatcherOrder(t *testing.T) {
+	headers := []string{"Mahalle Ad", "Ada No"}
+	rules := []TakbisMatchRule{{Target: "Mahalle", Takbis: "Mahalle Ad"}, {Target: "Ada", Takbis: "Ada No"}}
+	target := map[string]int{"Mahalle": 0, "Ada": 1}
+
+	matcher := newTakbisMatcher(rules, [][]string{headers, headers, {"Başka"}}, [][][]string{
+		{
+			{"Yeni Mahallesi", "12"},
+			{"Merkez", "7"},
+			{"MERKEZ", "7"},
+		},
+		{
+			{"Yeni", "12"},
+			{"merkez", "7"},
+		},
+		{{"Yeni"}},
+	})
+
+	// Every file's exact pass comes before any relaxed pass
+	match, ok := matcher.match([]string{"yeni", "12"}, target)
+	if !ok {
+		t.Fatal("yeni/12 didn't match")
+	}
+	if match.relaxed || match.takbisRowRef != (takbisRowRef{file: 1, row: 0}) {
+		t.Errorf("yeni/12 matched %+v relaxed %v, want the exact row of the second file", match.takbisRowRef, match.relaxed)
+	}
+
+	// The first candidate in file and row order is chosen, the others are listed after it
+	match, ok = matcher.match([]string{"Merkez ", "7"}, target)
+	if !ok {
+		t.Fatal("merkez/7 didn't match")
+	}
+	want := []takbisRowRef{{0, 1}, {0, 2}, {1, 1}}
+	if match.takbisRowRef != want[0] || !reflect.DeepEqual(match.candidates, want) {
+		t.Errorf("merkez/7 matched %+v with candidates %+v, want %+v", match.takbisRowRef, match.candidates, want)
+	}
+
+	// Only the relaxed pass finds the first file's "Yeni Mahallesi" for a target without the suffix
+	matcher = newTakbisMatcher(rules, [][]string{headers}, [][][]string{{{"Yeni Mahallesi", "12"}}})
+	match, ok = matcher.match([]string{"Yeni", "12"}, target)
+	if !ok || !match.relaxed {
+		t.Errorf("Yeni/12 matched %v relaxed %v, want a relaxed match", ok, match.relaxed)
+	}
+
+	if _, ok := matcher.match([]string{"", "12"}, target); ok {
+		t.Error("a row with an empty match column matched")
+	}
+}
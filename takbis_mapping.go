@@ -0,0 +1,306 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/xuri/excelize/v2"
+)
+
+// TakbisMatchRule pairs a column of the target Excel with the TAKBIS column it has to equal
+type TakbisMatchRule struct {
+	Target string `json:"target"`
+	Takbis string `json:"takbis"`
+}
+
+// TakbisMapping is how ModifyExcelWithTakbis matches target rows to TAKBIS rows and which
+// columns it fills
+type TakbisMapping struct {
+	Match []TakbisMatchRule `json:"match"` // a row matches when every rule holds
+	Cells []TakbisCellRule  `json:"cells"`
+}
+
+// TakbisMappingIssue is a problem ValidateTakbisMapping found, errors stop the merge and warnings
+// don't
+type TakbisMappingIssue struct {
+	Level   string `json:"level"` // error, warning
+	Message string `json:"message"`
+}
+
+// initialTakbisMapping is the mapping of a new config
+func initialTakbisMapping() TakbisMapping {
+	return TakbisMapping{
+		Match: []TakbisMatchRule{
+			{Target: "Mahalle", Takbis: "Mahalle Ad"},
+			{Target: "Ada", Takbis: "Ada No"},
+			{Target: "Parsel", Takbis: "Parsel No"},
+		},
+		Cells: []TakbisCellRule{
+			{Takbis: "Cins", Target: "Cins"},
+			{Takbis: "Mevki", Target: "Mevki"},
+			{Takbis: "Yüzölçüm", Target: "Alan (m2)"},
+			{Takbis: "Cilt No", Target: "Cilt"},
+			{Takbis: "Sayfa No", Target: "Sayfa"},
+			{Takbis: "Kadastro Pafta", Target: "Pafta"},
+		},
+	}
+}
+
+// migrateTakbisMapping turns the excelHeaderMatchPattern and excelCellModifyPattern strings of
+// configs written before TakbisMapping into a mapping. Pairs keep their order and are taken as
+// plain column names, the ones that can't be read are left out with a config warning.
+func migrateTakbisMapping(data []byte) {
+	if config.TakbisMapping != nil {
+		return
+	}
+
+	var legacy struct {
+		ExcelHeaderMatchPattern *string `json:"excelHeaderMatchPattern"`
+		ExcelCellModifyPattern  *string `json:"excelCellModifyPattern"`
+	}
+	if err := json.Unmarshal(data, &legacy); err != nil || legacy.ExcelHeaderMatchPattern == nil || legacy.ExcelCellModifyPattern == nil {
+		return
+	}
+
+	mapping := TakbisMapping{Match: []TakbisMatchRule{}, Cells: []TakbisCellRule{}}
+
+	for _, pair := range strings.Split(*legacy.ExcelHeaderMatchPattern, ",") {
+		if strings.TrimSpace(pair) == "" {
+			continue
+		}
+		target, takbis, ok := splitLegacyTakbisPair(pair, ":")
+		if !ok {
+			configWarnings = append(configWarnings, fmt.Sprintf("TAKBIS match pair %q couldn't be migrated, expected Target:TAKBIS", pair))
+			continue
+		}
+		mapping.Match = append(mapping.Match, TakbisMatchRule{Target: target, Takbis: takbis})
+	}
+
+	for _, pair := range strings.Split(*legacy.ExcelCellModifyPattern, ",") {
+		if strings.TrimSpace(pair) == "" {
+			continue
+		}
+		takbis, target, ok := splitLegacyTakbisPair(pair, "->")
+		if !ok {
+			configWarnings = append(configWarnings, fmt.Sprintf("TAKBIS cell pair %q couldn't be migrated, expected TAKBIS->Target", pair))
+			continue
+		}
+		mapping.Cells = append(mapping.Cells, TakbisCellRule{Takbis: takbis, Target: target})
+	}
+
+	config.TakbisMapping = &mapping
+}
+
+// splitLegacyTakbisPair splits a pair of the old pattern strings into two trimmed column names
+func splitLegacyTakbisPair(pair, sep string) (string, string, bool) {
+	kv := strings.Split(pair, sep)
+	if len(kv) != 2 || strings.TrimSpace(kv[0]) == "" || strings.TrimSpace(kv[1]) == "" {
+		return "", "", false
+	}
+	return strings.TrimSpace(kv[0]), strings.TrimSpace(kv[1]), true
+}
+
+// validate checks the mapping on its own, without looking at any file
+func (m TakbisMapping) validate() []TakbisMappingIssue {
+	var issues []TakbisMappingIssue
+	fail := func(format string, args ...interface{}) {
+		issues = append(issues, TakbisMappingIssue{Level: "error", Message: fmt.Sprintf(format, args...)})
+	}
+
+	if len(m.Match) == 0 {
+		fail("En az bir eşleştirme sütunu gerekli")
+	}
+	if len(m.Cells) == 0 {
+		fail("En az bir hücre değişimi gerekli")
+	}
+
+	matched := make(map[string]bool)
+	for i, rule := range m.Match {
+		if strings.TrimSpace(rule.Target) == "" || strings.TrimSpace(rule.Takbis) == "" {
+			fail("%d. eşleştirme kuralında sütun adı eksik", i+1)
+			continue
+		}
+		if matched[rule.Target] {
+			fail("%s sütunu birden fazla eşleştirme kuralında", rule.Target)
+		}
+		matched[rule.Target] = true
+	}
+
+	filled := make(map[string]bool)
+	for i, rule := range m.Cells {
+		if strings.TrimSpace(rule.Target) == "" || strings.TrimSpace(rule.Takbis) == "" {
+			fail("%d. hücre değişimi kuralında sütun adı eksik", i+1)
+			continue
+		}
+		if filled[rule.Target] {
+			fail("%s sütunu birden fazla hücre değişimi kuralında", rule.Target)
+		}
+		filled[rule.Target] = true
+
+		switch rule.Aggregate {
+		case "", TakbisAggregateJoin, TakbisAggregateSum, TakbisAggregateCount, TakbisAggregateFirst, TakbisAggregateLast:
+		default:
+			fail("%s için bilinmeyen birleştirme: %s", rule.Target, rule.Aggregate)
+		}
+	}
+
+	return issues
+}
+
+// validateHeaders checks the mapping against the headers of the target Excel and of every
+// TAKBIS file, the target Excel is skipped if targetHeaders is nil
+func (m TakbisMapping) validateHeaders(targetHeaders []string, takbisPaths []string, takbisHeadersList [][]string) []TakbisMappingIssue {
+	var issues []TakbisMappingIssue
+	add := func(level, format string, args ...interface{}) {
+		issues = append(issues, TakbisMappingIssue{Level: level, Message: fmt.Sprintf(format, args...)})
+	}
+
+	has := func(headers []string, header string) bool {
+		for _, h := range headers {
+			if h == header {
+				return true
+			}
+		}
+		return false
+	}
+
+	if targetHeaders != nil {
+		for _, rule := range m.Match {
+			if !has(targetHeaders, rule.Target) {
+				add("error", "Hedef Excel'de %s sütunu yok", rule.Target)
+			}
+		}
+		for _, rule := range m.Cells {
+			if !has(targetHeaders, rule.Target) {
+				add("warning", "Hedef Excel'de %s sütunu yok, doldurulmayacak", rule.Target)
+			}
+		}
+	}
+
+	usable := 0
+	for i, headers := range takbisHeadersList {
+		name := filepath.Base(takbisPaths[i])
+
+		missing := false
+		for _, rule := range m.Match {
+			if !has(headers, rule.Takbis) {
+				add("warning", "%s dosyasında %s sütunu yok, bu dosyadan eşleşme yapılamaz", name, rule.Takbis)
+				missing = true
+			}
+		}
+		if !missing {
+			usable++
+		}
+	}
+	if len(takbisHeadersList) > 0 && usable == 0 {
+		add("error", "Hiçbir TAKBIS dosyasında eşleştirme sütunlarının hepsi yok")
+	}
+
+	for _, rule := range m.Cells {
+		found := false
+		for _, headers := range takbisHeadersList {
+			if has(headers, rule.Takbis) {
+				found = true
+				break
+			}
+		}
+		if !found && len(takbisHeadersList) > 0 {
+			add("warning", "TAKBIS dosyalarında %s sütunu yok, %s doldurulmayacak", rule.Takbis, rule.Target)
+		}
+	}
+
+	return issues
+}
+
+// firstTakbisMappingError returns the message of the first error, empty if there's none
+func firstTakbisMappingError(issues []TakbisMappingIssue) string {
+	for _, issue := range issues {
+		if issue.Level == "error" {
+			return issue.Message
+		}
+	}
+	return ""
+}
+
+// readExcelHeaders reads only the first row of the first sheet, empty if the sheet has no rows
+func readExcelHeaders(path string) ([]string, error) {
+	excel, err := excelize.OpenFile(path)
+	if err != nil {
+		return nil, err
+	}
+	defer excel.Close()
+
+	rows, err := excel.Rows(excel.GetSheetList()[0])
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	if !rows.Next() {
+		return []string{}, rows.Error()
+	}
+
+	return rows.Columns()
+}
+
+// GetExcelHeaders returns the column names of an Excel file for the mapping editor
+func (app *App) GetExcelHeaders(path string) ([]string, error) {
+	return readExcelHeaders(path)
+}
+
+// ValidateTakbisMapping checks the mapping and, for the files given, their headers
+func (app *App) ValidateTakbisMapping(excelPath string, takbisPaths []string, mapping TakbisMapping) []TakbisMappingIssue {
+	issues := mapping.validate()
+
+	// Without a target Excel only the TAKBIS files are checked
+	var targetHeaders []string
+	if excelPath != "" {
+		headers, err := readExcelHeaders(excelPath)
+		if err != nil {
+			return append(issues, TakbisMappingIssue{Level: "error", Message: err.Error()})
+		}
+		targetHeaders = headers
+	}
+
+	takbisHeadersList := make([][]string, len(takbisPaths))
+	for i, takbisPath := range takbisPaths {
+		headers, err := readExcelHeaders(takbisPath)
+		if err != nil {
+			return append(issues, TakbisMappingIssue{Level: "error", Message: err.Error()})
+		}
+		takbisHeadersList[i] = headers
+	}
+
+	return append(issues, mapping.validateHeaders(targetHeaders, takbisPaths, takbisHeadersList)...)
+}
+
+// SetTakbisMapping stores the mapping being edited, it's validated when the merge runs
+func (app *App) SetTakbisMapping(mapping TakbisMapping) {
+	config.TakbisMapping = &mapping
+}
+
+// SaveTakbisMappingPreset saves the mapping under name, replacing a preset of the same name
+func (app *App) SaveTakbisMappingPreset(name string, mapping TakbisMapping) error {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return fmt.Errorf("preset name is empty")
+	}
+
+	if message := firstTakbisMappingError(mapping.validate()); message != "" {
+		return fmt.Errorf("%s", message)
+	}
+
+	if *config.TakbisMappingPresets == nil {
+		*config.TakbisMappingPresets = make(map[string]TakbisMapping)
+	}
+	(*config.TakbisMappingPresets)[name] = mapping
+
+	return nil
+}
+
+// DeleteTakbisMappingPreset removes the preset called name
+func (app *App) DeleteTakbisMappingPreset(name string) {
+	delete(*config.TakbisMappingPresets, name)
+}
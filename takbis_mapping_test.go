@@ -0,0 +1,55 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestMigrateTakbisMapping(t *testing.T) {
+	saved, savedWarnings := config, configWarnings
+	defer func() { config, configWarnings = saved, savedWarnings }()
+
+	config = Config{}
+	configWarnings = nil
+
+	migrateTakbisMapping([]byte(`{
+		"excelHeaderMatchPattern": "Mahalle:Mahalle Ad, Ada : Ada No,Parsel,Parsel:Parsel No",
+		"excelCellModifyPattern": "Cins->Cins,Hisse | Pay->Hisse,\"Not\"->Açıklama,Cilt No,Yüzölçüm->Alan (m2)"
+	}`))
+
+	want := &TakbisMapping{
+		Match: []TakbisMatchRule{
+			{Target: "Mahalle", Takbis: "Mahalle Ad"},
+			{Target: "Ada", Takbis: "Ada No"},
+			{Target: "Parsel", Takbis: "Parsel No"},
+		},
+		Cells: []TakbisCellRule{
+			{Takbis: "Cins", Target: "Cins"},
+			{Takbis: "Hisse | Pay", Target: "Hisse"},
+			{Takbis: `"Not"`, Target: "Açıklama"},
+			{Takbis: "Yüzölçüm", Target: "Alan (m2)"},
+		},
+	}
+	if !reflect.DeepEqual(config.TakbisMapping, want) {
+		t.Errorf("mapping = %+v, want %+v", config.TakbisMapping, want)
+	}
+
+	// "Parsel" and "Cilt No" have no pair
+	if len(configWarnings) != 2 {
+		t.Errorf("warnings = %q, want one for each pair that couldn't be read", configWarnings)
+	}
+}
+
+func TestMigrateTakbisMappingKeepsNewMapping(t *testing.T) {
+	saved := config
+	defer func() { config = saved }()
+
+	mapping := initialTakbisMapping()
+	config = Config{TakbisMapping: &mapping}
+
+	migrateTakbisMapping([]byte(`{"excelHeaderMatchPattern":"A:B","excelCellModifyPattern":"C->D"}`))
+
+	if !reflect.DeepEqual(*config.TakbisMapping, initialTakbisMapping()) {
+		t.Errorf("mapping = %+v, want the saved one", config.TakbisMapping)
+	}
+}
@@ -0,0 +1,155 @@
+package main
+
+import (
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/xuri/excelize/v2"
+)
+
+// TakbisRowResult is how a row of the target Excel fared in the TAKBIS merge
+type TakbisRowResult struct {
+	Row        int      `json:"row"` // 1-based row number in the Excel
+	Key        string   `json:"key"` // values of the match columns, like "Mahalle=Merkez, Ada=12"
+	Matched    bool     `json:"matched"`
+	Relaxed    bool     `json:"relaxed"`    // matched only without the last word of the TAKBIS values
+	Chosen     string   `json:"chosen"`     // TAKBIS row written to the Excel
+	Candidates []string `json:"candidates"` // every TAKBIS row matching in the same pass, Chosen first
+	Message    string   `json:"message"`
+}
+
+var lastTakbisReport []TakbisRowResult
+
+func (app *App) GetTakbisReport() []TakbisRowResult {
+	return lastTakbisReport
+}
+
+// takbisRowName names a TAKBIS row by its file and 1-based Excel row, like "Takbis1.xlsx:12"
+func takbisRowName(takbisPaths []string, ref takbisRowRef) string {
+	return fmt.Sprintf("%s:%d", filepath.Base(takbisPaths[ref.file]), ref.row+2)
+}
+
+// newTakbisRowResult reports the match of the 0-based data row, aggregated tells whether the cell
+// change rules combine the candidates rather than copy the chosen row
+func newTakbisRowResult(row int, key string, match takbisMatch, matched, aggregated bool, takbisPaths []string) TakbisRowResult {
+	result := TakbisRowResult{Row: row + 2, Key: key, Matched: matched}
+
+	if !matched {
+		result.Message = "Eşleşen TAKBIS satırı bulunamadı"
+		return result
+	}
+
+	result.Relaxed = match.relaxed
+	result.Chosen = takbisRowName(takbisPaths, match.takbisRowRef)
+	for _, candidate := range match.candidates {
+		result.Candidates = append(result.Candidates, takbisRowName(takbisPaths, candidate))
+	}
+
+	if len(match.candidates) > 1 && aggregated {
+		result.Message = fmt.Sprintf("%d satır birleştirildi", len(match.candidates))
+	} else if len(match.candidates) > 1 {
+		result.Message = fmt.Sprintf("%d aday bulundu, ilki kullanıldı", len(match.candidates))
+	}
+
+	return result
+}
+
+// takbisReportStatus is the Durum column of the report
+func takbisReportStatus(result TakbisRowResult) string {
+	switch {
+	case !result.Matched:
+		return "Eşleşmedi"
+	case len(result.Candidates) > 1:
+		return "Birden fazla aday"
+	default:
+		return "Eşleşti"
+	}
+}
+
+// writeTakbisReportSheet writes the report to a sheet of the workbook, replacing the sheet of an
+// earlier run. With keepEarlier, for a resumed run, the rows the interrupted run left in the sheet
+// are kept unless this run reported them again, so the rows it skipped aren't lost.
+func writeTakbisReportSheet(excel *excelize.File, sheetName string, report []TakbisRowResult, keepEarlier bool) error {
+	header := []interface{}{"Satır", "Anahtar", "Durum", "Eşleşme", "Seçilen", "Aday Sayısı", "Adaylar", "Açıklama"}
+
+	rows := make(map[int][]interface{})
+
+	if index, err := excel.GetSheetIndex(sheetName); err == nil && index != -1 {
+		var earlier [][]string
+		if keepEarlier {
+			earlier, err = excel.GetRows(sheetName)
+			if err != nil {
+				return err
+			}
+		}
+
+		for i, row := range earlier {
+			if i == 0 || len(row) == 0 {
+				continue
+			}
+			number, ok := excelNumberText(row[0])
+			if !ok {
+				continue
+			}
+
+			values := make([]interface{}, len(row))
+			for j, value := range row {
+				values[j] = value
+				if n, ok := excelNumberText(value); ok {
+					values[j] = n
+				}
+			}
+			rows[number] = values
+		}
+
+		if err := excel.DeleteSheet(sheetName); err != nil {
+			return err
+		}
+	}
+
+	if _, err := excel.NewSheet(sheetName); err != nil {
+		return err
+	}
+
+	for _, result := range report {
+		pass := ""
+		if result.Matched {
+			pass = "Tam"
+			if result.Relaxed {
+				pass = "Son kelime hariç"
+			}
+		}
+
+		rows[result.Row] = []interface{}{
+			result.Row,
+			result.Key,
+			takbisReportStatus(result),
+			pass,
+			result.Chosen,
+			len(result.Candidates),
+			strings.Join(result.Candidates, ", "),
+			result.Message,
+		}
+	}
+
+	numbers := make([]int, 0, len(rows))
+	for number := range rows {
+		numbers = append(numbers, number)
+	}
+	sort.Ints(numbers)
+
+	if err := excel.SetSheetRow(sheetName, "A1", &header); err != nil {
+		return err
+	}
+	for i, number := range numbers {
+		row := rows[number]
+		cell, _ := excelize.CoordinatesToCellName(1, i+2)
+		if err := excel.SetSheetRow(sheetName, cell, &row); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,74 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/xuri/excelize/v2"
+)
+
+func TestWriteTakbisReportSheetKeepsEarlierRows(t *testing.T) {
+	excel := excelize.NewFile()
+	defer excel.Close()
+
+	first := []TakbisRowResult{
+		{Row: 2, Key: "Ada=1", Matched: true, Chosen: "T.xlsx:2", Candidates: []string{"T.xlsx:2"}},
+		{Row: 3, Key: "Ada=2"},
+	}
+	if err := writeTakbisReportSheet(excel, "Rapor", first, false); err != nil {
+		t.Fatal(err)
+	}
+
+	// A resumed run only reports the rows it didn't skip
+	resumed := []TakbisRowResult{
+		{Row: 5, Key: "Ada=4"},
+		{Row: 3, Key: "Ada=2", Matched: true, Chosen: "T.xlsx:3", Candidates: []string{"T.xlsx:3"}},
+	}
+	if err := writeTakbisReportSheet(excel, "Rapor", resumed, true); err != nil {
+		t.Fatal(err)
+	}
+
+	rows, err := excel.GetRows("Rapor")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var got [][]string
+	for _, row := range rows[1:] {
+		got = append(got, row[:3])
+	}
+	want := [][]string{
+		{"2", "Ada=1", "Eşleşti"},
+		{"3", "Ada=2", "Eşleşti"},
+		{"5", "Ada=4", "Eşleşmedi"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("report rows = %v, want %v", got, want)
+	}
+
+	if cellType, _ := excel.GetCellType("Rapor", "A2"); cellType == excelize.CellTypeSharedString || cellType == excelize.CellTypeInlineString {
+		t.Errorf("kept row number A2 was written as text")
+	}
+}
+
+func TestWriteTakbisReportSheetReplacesFinishedRun(t *testing.T) {
+	excel := excelize.NewFile()
+	defer excel.Close()
+
+	// The workbook still has the report of a finished run, a new run doesn't carry it over
+	earlier := []TakbisRowResult{{Row: 2, Key: "Ada=1"}, {Row: 7, Key: "Ada=9"}}
+	if err := writeTakbisReportSheet(excel, "Rapor", earlier, false); err != nil {
+		t.Fatal(err)
+	}
+	if err := writeTakbisReportSheet(excel, "Rapor", []TakbisRowResult{{Row: 3, Key: "Ada=2"}}, false); err != nil {
+		t.Fatal(err)
+	}
+
+	rows, err := excel.GetRows("Rapor")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(rows) != 2 || rows[1][0] != "3" {
+		t.Errorf("report rows = %v, want only row 3", rows[1:])
+	}
+}
@@ -2,74 +2,126 @@ package main
 
 import (
 	"archive/zip"
+	"encoding/json"
 	"fmt"
 	"io"
 	"net/http"
 	"os"
 	"os/exec"
 	"path/filepath"
-	"strconv"
 	"strings"
 	"time"
 
-	r "runtime"
-
 	"github.com/wailsapp/wails/v2/pkg/runtime"
 )
 
-type Tapu struct {
-	Cilt  int
-	Sayfa int
-	Mevki string
-	Alan  float64
-}
-
-func (app *App) AddTapuToExcel(excelPath string, path string, tapuPathPattern string, ciltHeader string, sayfaHeader string, mevkiHeader string, alanHeader string) string {
+func (app *App) AddTapuToExcel(excelPath string, path string, tapuPathPattern string, fieldMapping string, adaHeader string, parselHeader string, mahalleHeader string) string {
 	runtime.LogInfo(app.ctx, "Adding tapu to "+excelPath)
 
-	headers, rows, excel, err := ReadExcel(excelPath)
-	sheetName := excel.GetSheetList()[0]
+	if err := checkExcelSavable(excelPath); err != nil {
+		runtime.LogError(app.ctx, err.Error())
+		return excelSaveError(err)
+	}
+
+	checkpoint, readPath := newExcelCheckpoint(ExcelTaskTapu, excelPath)
+
+	headers, rows, excel, err := ReadExcel(readPath)
 
 	if err != nil {
 		runtime.LogError(app.ctx, err.Error())
 		return err.Error()
 	}
 
-	ciltIndex := -1
-	sayfaIndex := -1
-	mevkiIndex := -1
-	alanIndex := -1
+	sheetName := excel.GetSheetList()[0]
+
+	// Tapu field -> column index
+	fieldMap := parceCellChangePattern(fieldMapping)
+	fieldIndexes := make(map[string]int)
+
+	for field, column := range fieldMap {
+		field = strings.TrimSpace(field)
+		column = strings.TrimSpace(column)
+
+		if tapuFieldName(field) == "" {
+			runtime.LogWarning(app.ctx, "Unknown tapu field: "+field)
+			continue
+		}
+
+		for i, header := range headers {
+			if strings.TrimSpace(header) == column {
+				fieldIndexes[field] = i
+				break
+			}
+		}
+
+		if _, ok := fieldIndexes[field]; !ok {
+			runtime.LogWarning(app.ctx, "Column not found for tapu field "+field+": "+column)
+		}
+	}
+
+	runtime.LogInfo(app.ctx, "Indexes: "+fmt.Sprint(fieldIndexes))
+
+	adaIndex, parselIndex, mahalleIndex := -1, -1, -1
 
 	for i, header := range headers {
 		header = strings.TrimSpace(header)
 
-		if header == ciltHeader {
-			ciltIndex = i
+		if header == adaHeader {
+			adaIndex = i
+		} else if header == parselHeader {
+			parselIndex = i
+		} else if header == mahalleHeader {
+			mahalleIndex = i
 		}
-		if header == sayfaHeader {
-			sayfaIndex = i
-		}
-		if header == mevkiHeader {
-			mevkiIndex = i
+	}
+
+	cellValue := func(row []string, index int) string {
+		if index == -1 || index >= len(row) {
+			return ""
 		}
-		if header == alanHeader {
-			alanIndex = i
+		return row[index]
+	}
+
+	writer, err := newExcelCellWriter(excel, sheetName, headers)
+	if err != nil {
+		runtime.LogError(app.ctx, err.Error())
+		return err.Error()
+	}
+
+	minConfidence := float64(*config.TapuMinConfidence) / 100
+
+	mappedFields := make([]string, 0, len(fieldIndexes))
+	for field := range fieldIndexes {
+		mappedFields = append(mappedFields, field)
+	}
+
+	// A row is filled once every mapped column has a value
+	filled := func(row []string) bool {
+		for _, index := range fieldIndexes {
+			if strings.TrimSpace(cellValue(row, index)) == "" {
+				return false
+			}
 		}
+		return len(fieldIndexes) > 0
 	}
 
-	runtime.LogInfo(app.ctx, "Indexes: Cilt: "+fmt.Sprint(ciltIndex)+" Sayfa: "+fmt.Sprint(sayfaIndex)+" Mevki: "+fmt.Sprint(mevkiIndex)+" Alan: "+fmt.Sprint(alanIndex))
+	report := make([]TapuRowResult, 0, len(rows))
+	notFound, lowConfidence, conflicted, skipped := 0, 0, 0, 0
 
 	for i, row := range rows {
+		if checkpoint.isDone(i) || (*config.ExcelResume && filled(row)) {
+			skipped++
+			continue
+		}
+
 		runtime.WindowExecJS(appContext, `window.setCiltMessage("`+fmt.Sprintf("%d/%d", i+1, len(rows))+`");`)
 		runtime.LogDebug(app.ctx, "Generating pattern: "+tapuPathPattern)
-		newPattern := generatePatternName(tapuPathPattern, headers, row)
+		newPattern := globPatternName(tapuPathPattern, headers, row)
 		runtime.LogDebug(app.ctx, "Generated pattern: "+newPattern)
 
-		wholePath := filepath.Join(path, newPattern)
+		runtime.LogDebug(app.ctx, "Searching for: "+filepath.Join(path, newPattern))
 
-		runtime.LogDebug(app.ctx, "Searching for: "+wholePath)
-
-		matches, err := FilterDirs(wholePath)
+		matches, err := FilterDirs(path, newPattern)
 
 		if err != nil {
 			app.SendNotification("", strings.ReplaceAll(err.Error(), "\\", "\\\\"), "", "error")
@@ -79,98 +131,143 @@ func (app *App) AddTapuToExcel(excelPath string, path string, tapuPathPattern st
 
 		if len(matches) == 0 {
 			runtime.LogInfo(app.ctx, "Tapu not found for row: "+fmt.Sprint(row))
+			report = append(report, TapuRowResult{Row: i + 2, Best: -1, Message: "Tapu bulunamadı"})
+			notFound++
 			continue
 		}
 
 		runtime.LogDebug(app.ctx, "Found matches: "+fmt.Sprint(matches))
 
+		keys := tapuRowKeys{
+			ada:     cellValue(row, adaIndex),
+			parsel:  cellValue(row, parselIndex),
+			mahalle: cellValue(row, mahalleIndex),
+		}
+
+		result := TapuRowResult{Row: i + 2, Best: -1}
+
 		for _, match := range matches {
 			tapu, err := app.ParseTapu(match)
 
 			if err != nil {
+				result.Matches = append(result.Matches, TapuMatch{Path: match, Error: err.Error()})
 				continue
 			}
 
-			if ciltIndex != -1 {
-				err = excel.SetCellInt(sheetName, alphabet[ciltIndex]+fmt.Sprint(i+2), tapu.Cilt)
+			confidence := tapuConfidence(tapu, keys)
+			runtime.LogDebug(app.ctx, fmt.Sprintf("Confidence of %s: %.2f", match, confidence))
 
-				if err != nil {
-					runtime.LogError(app.ctx, err.Error())
-				}
-			}
-			if sayfaIndex != -1 {
-				err = excel.SetCellInt(sheetName, alphabet[sayfaIndex]+fmt.Sprint(i+2), tapu.Sayfa)
+			result.Matches = append(result.Matches, TapuMatch{Path: match, Tapu: tapu, Confidence: confidence})
+		}
 
-				if err != nil {
-					runtime.LogError(app.ctx, err.Error())
-				}
-			}
-			if mevkiIndex != -1 {
-				err = excel.SetCellStr(sheetName, alphabet[mevkiIndex]+fmt.Sprint(i+2), tapu.Mevki)
+		result.Best = selectBestTapu(result.Matches, minConfidence)
+		result.Conflicts = findTapuConflicts(result.Matches, mappedFields, minConfidence)
+
+		if len(result.Conflicts) > 0 {
+			conflicted++
+			runtime.LogWarning(app.ctx, fmt.Sprintf("Tapu conflicts in row %d: %+v", i+2, result.Conflicts))
+		}
+
+		if result.Best == -1 {
+			result.Message = "Eşleşen tapu bulunamadı"
+			lowConfidence++
+			runtime.LogWarning(app.ctx, fmt.Sprintf("No tapu reached the minimum confidence for row %d", i+2))
+			report = append(report, result)
+			continue
+		}
 
-				if err != nil {
-					runtime.LogError(app.ctx, err.Error())
-				}
+		best := result.Matches[result.Best]
+		runtime.LogInfo(app.ctx, fmt.Sprintf("Using %s for row %d (confidence %.2f)", best.Path, i+2, best.Confidence))
+
+		for field, index := range fieldIndexes {
+			value, ok := best.Tapu.Field(field)
+			if !ok {
+				continue
 			}
-			if alanIndex != -1 {
-				err = excel.SetCellFloat(sheetName, alphabet[alanIndex]+fmt.Sprint(i+2), tapu.Alan, 2, 64)
 
-				if err != nil {
-					runtime.LogError(app.ctx, err.Error())
-				}
+			if err := writer.set(index, i+2, value); err != nil {
+				runtime.LogError(app.ctx, err.Error())
 			}
 		}
+
+		report = append(report, result)
+
+		if err := checkpoint.markDone(excel, i); err != nil {
+			runtime.LogError(app.ctx, err.Error())
+			lastTapuReport = report
+			return excelSaveError(err)
+		}
 	}
 
+	lastTapuReport = report
+
 	// Save
-	runtime.LogInfo(app.ctx, "Saving "+excelPath)
-	err = excel.SaveAs(excelPath)
+	savedPath, err := checkpoint.finish(excel)
 
 	if err != nil {
 		runtime.LogError(app.ctx, err.Error())
-		return err.Error()
+		return excelSaveError(err)
+	}
+
+	runtime.LogInfo(app.ctx, "Saved "+savedPath)
+
+	message := "Excel dosyası başarıyla güncellendi" + resumeMessage(skipped)
+	if notFound+lowConfidence+conflicted > 0 {
+		message += fmt.Sprintf(" (bulunamayan: %d, düşük güven: %d, çakışma: %d)", notFound, lowConfidence, conflicted)
 	}
 
-	runtime.WindowExecJS(appContext, `window.setCiltMessage("Excel dosyası başarıyla güncellendi");`)
+	runtime.WindowExecJS(appContext, `window.setCiltMessage("`+message+`");`)
 
 	return ""
 }
 
-func FilterDirs(path string) ([]string, error) {
-	var matches []string
-
-	base := filepath.Base(path)
-	dir := filepath.Dir(path)
+// migrateTapuFieldMapping turns the ciltCellName, sayfaCellName, mevkiCellName and alanCellNameTapu
+// settings of configs written before TapuFieldMapping into a mapping, unset ones keep their defaults
+func migrateTapuFieldMapping(data []byte) {
+	if config.TapuFieldMapping != nil {
+		return
+	}
 
-	files, err := os.ReadDir(dir)
-	if err != nil {
-		return nil, err
+	var legacy struct {
+		Cilt  *string `json:"ciltCellName"`
+		Sayfa *string `json:"sayfaCellName"`
+		Mevki *string `json:"mevkiCellName"`
+		Alan  *string `json:"alanCellNameTapu"`
+	}
+	if err := json.Unmarshal(data, &legacy); err != nil {
+		return
+	}
+	if legacy.Cilt == nil && legacy.Sayfa == nil && legacy.Mevki == nil && legacy.Alan == nil {
+		return
 	}
 
-	for _, file := range files {
-		match, err := filepath.Match(base, file.Name())
-		if err != nil {
-			return nil, err
+	var pairs []string
+	for _, field := range []struct {
+		name     string
+		column   *string
+		fallback string
+	}{
+		{TapuFieldCilt, legacy.Cilt, "Cilt"},
+		{TapuFieldSayfa, legacy.Sayfa, "Sayfa"},
+		{TapuFieldMevki, legacy.Mevki, "Mevki"},
+		{TapuFieldAlan, legacy.Alan, "Alan (m2)"},
+	} {
+		column := field.fallback
+		if field.column != nil {
+			column = *field.column
 		}
-		if match {
-			matches = append(matches, filepath.Join(dir, file.Name()))
+		if strings.TrimSpace(column) != "" {
+			pairs = append(pairs, field.name+"->"+column)
 		}
 	}
 
-	return matches, nil
+	mapping := strings.Join(pairs, ",")
+	config.TapuFieldMapping = &mapping
 }
 
 func (app *App) ParseTapu(path string) (Tapu, error) {
-	var tapu Tapu
-
 	runtime.LogInfo(app.ctx, "Parsing "+path)
 
-	err := installXpdf()
-	if err != nil {
-		runtime.LogError(app.ctx, err.Error())
-		return Tapu{}, err
-	}
-
 	content, err := ReadPlainTextFromPDF(path)
 
 	if err != nil {
@@ -178,94 +275,20 @@ func (app *App) ParseTapu(path string) (Tapu, error) {
 		return Tapu{}, err
 	}
 
-	found := false
-
-	for _, line := range strings.Split(content, "\n") {
-		if strings.Contains(line, "Cilt") {
-			split := strings.Split(line, ":")
-			splitRight := strings.Split(split[1], "/")
-
-			cilt := strings.TrimSpace(splitRight[0])
-			sayfa := strings.TrimSpace(splitRight[1])
-
-			runtime.LogInfo(app.ctx, "Cilt: "+cilt+" Sayfa: "+sayfa)
-
-			numberSayfa, err := strconv.Atoi(sayfa)
-			if err != nil {
-				runtime.LogError(app.ctx, err.Error())
-				return Tapu{}, err
-			}
-
-			numberCilt, err := strconv.Atoi(cilt)
-			if err != nil {
-				runtime.LogError(app.ctx, err.Error())
-				return Tapu{}, err
-			}
-
-			tapu.Cilt = numberCilt
-			tapu.Sayfa = numberSayfa
-
-			found = true
-		} else if strings.Contains(line, "Mevki") {
-			split := strings.Split(line, ":")
+	tapu, warnings, err := parseTapuText(content)
 
-			tapu.Mevki = strings.TrimSpace(split[1])
-			tapu.Mevki = toTitleCaseWord(tapu.Mevki)
-
-			found = true
-		} else if strings.Contains(line, "Yüzölçüm") {
-			splitSpace := strings.Split(line, " ")
-
-			// find Yüzölçüm in split
-			for i, word := range splitSpace {
-				if word == "Yüzölçüm" && i+2 < len(splitSpace) {
-					alanString := strings.TrimSpace(splitSpace[i+2])
-					alanString = strings.ReplaceAll(alanString, "m2", "")
-					alanString = strings.TrimSpace(alanString)
-					alanString = strings.ReplaceAll(alanString, ".", "")
-					alanString = strings.ReplaceAll(alanString, ",", ".")
-
-					alan, err := strconv.ParseFloat(alanString, 64)
-
-					if err != nil {
-						runtime.LogError(app.ctx, err.Error())
-						return Tapu{}, err
-					}
-
-					tapu.Alan = alan
-
-					found = true
-				}
-			}
-
-		}
-	}
-
-	if !found {
-		return tapu, fmt.Errorf("Tapu bilgisi bulunamadı")
-	}
-
-	return tapu, nil
-}
-
-func ReadPlainTextFromPDF(pdfpath string) (text string, err error) {
-	runtime.LogInfo(appContext, "Reading text from PDF")
-	cmd := exec.Command(pdfToTextPath, "-simple2", "-enc", "UTF-8", pdfpath, filepath.Join(appFolder, "temp.txt"))
-	err = cmd.Run()
-
-	if err != nil {
-		return "", err
+	for _, warning := range warnings {
+		runtime.LogWarning(app.ctx, path+": "+warning)
 	}
 
-	bytes, err := os.ReadFile(filepath.Join(appFolder, "temp.txt"))
-
 	if err != nil {
-		return "", err
+		runtime.LogError(app.ctx, err.Error())
+		return Tapu{}, err
 	}
 
-	defer os.Remove(filepath.Join(appFolder, "temp.txt"))
+	runtime.LogInfo(app.ctx, fmt.Sprintf("Tapu: %+v", tapu))
 
-	return string(bytes), nil
+	return tapu, nil
 }
 
 // installXpdf checks if Xpdf (pdftotext) is installed and installs it if not.
@@ -277,19 +300,14 @@ func installXpdf() error {
 		return nil
 	}
 
-	app.SendNotification("Xpdf kuruluyor", "Bu işlem birkaç dakika sürebilir", "", "info")
-
-	// Determine download URL based on architecture
-	var downloadURL string
-	switch r.GOARCH {
-	case "amd64":
-		downloadURL = "https://dl.xpdfreader.com/xpdf-tools-win-4.05.zip"
-	case "386":
-		downloadURL = "https://dl.xpdfreader.com/xpdf-tools-mac-4.05.tar.gz"
-	default:
-		return fmt.Errorf("unsupported architecture: %s", r.GOARCH)
+	// Determine download URL based on platform
+	downloadURL, err := xpdfDownloadURL()
+	if err != nil {
+		return err
 	}
 
+	app.SendNotification("Xpdf kuruluyor", "Bu işlem birkaç dakika sürebilir", "", "info")
+
 	// Download and extract Xpdf tools
 	zipFilePath := filepath.Join(appFolder, "xpdf-tools.zip")
 	defer os.Remove(zipFilePath)
@@ -0,0 +1,141 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// TapuMatch is the result of parsing one PDF found for a row
+type TapuMatch struct {
+	Path       string  `json:"path"`
+	Tapu       Tapu    `json:"tapu"`
+	Confidence float64 `json:"confidence"` // 0-1, how well the PDF fits the row
+	Error      string  `json:"error"`
+}
+
+// TapuConflict lists the different values matched PDFs report for a field
+type TapuConflict struct {
+	Field  string   `json:"field"`
+	Values []string `json:"values"`
+}
+
+// TapuRowResult collects every match of a row and the one written to the Excel
+type TapuRowResult struct {
+	Row       int            `json:"row"` // 1-based row number in the Excel
+	Matches   []TapuMatch    `json:"matches"`
+	Best      int            `json:"best"` // index into Matches, -1 when nothing was written
+	Conflicts []TapuConflict `json:"conflicts"`
+	Message   string         `json:"message"`
+}
+
+// tapuRowKeys are the values of a row the PDFs are checked against
+type tapuRowKeys struct {
+	ada     string
+	parsel  string
+	mahalle string
+}
+
+const (
+	tapuWeightAda     = 0.4
+	tapuWeightParsel  = 0.4
+	tapuWeightMahalle = 0.2
+)
+
+var lastTapuReport []TapuRowResult
+
+func (app *App) GetLastTapuReport() []TapuRowResult {
+	return lastTapuReport
+}
+
+// tapuConfidence scores a parsed tapu against the row. A matching field adds its weight,
+// a field missing on either side adds half of it and a differing field adds nothing.
+// A differing ada or parsel means the PDF belongs to another parcel and scores 0.
+func tapuConfidence(tapu Tapu, keys tapuRowKeys) float64 {
+	score := 0.0
+
+	compare := func(weight float64, rowValue, tapuValue string, equal func(a, b string) bool) bool {
+		if strings.TrimSpace(rowValue) == "" || strings.TrimSpace(tapuValue) == "" {
+			score += weight / 2
+		} else if equal(rowValue, tapuValue) {
+			score += weight
+		} else {
+			return false
+		}
+		return true
+	}
+
+	adaOk := compare(tapuWeightAda, keys.ada, tapu.Ada, equalParcelNumber)
+	parselOk := compare(tapuWeightParsel, keys.parsel, tapu.Parsel, equalParcelNumber)
+	compare(tapuWeightMahalle, keys.mahalle, tapu.Mahalle, func(a, b string) bool {
+		return LooseEqual(a, b) || LooseEqualWithoutLastWord(a, b) || LooseEqualWithoutLastWord(b, a)
+	})
+
+	if !adaOk || !parselOk {
+		return 0
+	}
+
+	return score
+}
+
+// equalParcelNumber compares ada/parsel numbers ignoring whitespace and leading zeros
+func equalParcelNumber(a, b string) bool {
+	a = strings.TrimLeft(strings.TrimSpace(a), "0")
+	b = strings.TrimLeft(strings.TrimSpace(b), "0")
+
+	return a == b
+}
+
+// selectBestTapu returns the index of the successfully parsed match with the highest
+// confidence, -1 if none reaches minConfidence. Ties keep the first match.
+func selectBestTapu(matches []TapuMatch, minConfidence float64) int {
+	best := -1
+
+	for i, match := range matches {
+		if match.Error != "" || match.Confidence < minConfidence {
+			continue
+		}
+		if best == -1 || match.Confidence > matches[best].Confidence {
+			best = i
+		}
+	}
+
+	return best
+}
+
+// findTapuConflicts compares the given fields between the successfully parsed matches
+func findTapuConflicts(matches []TapuMatch, fields []string, minConfidence float64) []TapuConflict {
+	var conflicts []TapuConflict
+
+	for _, field := range fields {
+		seen := make(map[string]bool)
+		var values []string
+
+		for _, match := range matches {
+			if match.Error != "" || match.Confidence < minConfidence {
+				continue
+			}
+
+			value, ok := match.Tapu.Field(field)
+			if !ok {
+				continue
+			}
+
+			str := fmt.Sprint(value)
+			if !seen[str] {
+				seen[str] = true
+				values = append(values, str)
+			}
+		}
+
+		if len(values) > 1 {
+			conflicts = append(conflicts, TapuConflict{Field: field, Values: values})
+		}
+	}
+
+	sort.Slice(conflicts, func(i, j int) bool {
+		return conflicts[i].Field < conflicts[j].Field
+	})
+
+	return conflicts
+}
@@ -0,0 +1,70 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestTapuConfidence(t *testing.T) {
+	keys := tapuRowKeys{ada: "123", parsel: "4", mahalle: "Yeni"}
+
+	tests := []struct {
+		name string
+		tapu Tapu
+		want float64
+	}{
+		{"every field matches", Tapu{Ada: "0123", Parsel: "4", Mahalle: "YENİ"}, 1},
+		{"mahalle with its suffix", Tapu{Ada: "123", Parsel: "4", Mahalle: "Yeni Mahallesi"}, 1},
+		{"mahalle missing", Tapu{Ada: "123", Parsel: "4"}, 0.9},
+		{"other mahalle", Tapu{Ada: "123", Parsel: "4", Mahalle: "Merkez"}, 0.8},
+		{"parsel missing", Tapu{Ada: "123", Mahalle: "Yeni"}, 0.8},
+		{"other parsel", Tapu{Ada: "123", Parsel: "5", Mahalle: "Yeni"}, 0},
+		{"other ada", Tapu{Ada: "12", Parsel: "4", Mahalle: "Yeni"}, 0},
+	}
+
+	for _, tt := range tests {
+		if got := tapuConfidence(tt.tapu, keys); got < tt.want-1e-9 || got > tt.want+1e-9 {
+			t.Errorf("%s: confidence = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestSelectBestTapu(t *testing.T) {
+	tests := []struct {
+		name    string
+		matches []TapuMatch
+		want    int
+	}{
+		{"highest confidence", []TapuMatch{{Confidence: 0.8}, {Confidence: 1}, {Confidence: 0.9}}, 1},
+		{"ties keep the first", []TapuMatch{{Confidence: 0.9}, {Confidence: 0.9}}, 0},
+		{"failed parses are skipped", []TapuMatch{{Confidence: 1, Error: "okunamadı"}, {Confidence: 0.6}}, 1},
+		{"below the minimum", []TapuMatch{{Confidence: 0.4}, {Confidence: 0}}, -1},
+		{"no matches", nil, -1},
+	}
+
+	for _, tt := range tests {
+		if got := selectBestTapu(tt.matches, 0.5); got != tt.want {
+			t.Errorf("%s: best = %d, want %d", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestFindTapuConflicts(t *testing.T) {
+	matches := []TapuMatch{
+		{Confidence: 1, Tapu: Tapu{Cilt: 12, Sayfa: 345, Nitelik: "Tarla"}},
+		{Confidence: 0.9, Tapu: Tapu{Cilt: 12, Sayfa: 346, Nitelik: "Tarla"}},
+		{Confidence: 0.9, Tapu: Tapu{Cilt: 13, Sayfa: 345}},
+		// Neither a failed parse nor a PDF of another parcel conflicts
+		{Confidence: 1, Error: "okunamadı", Tapu: Tapu{Nitelik: "Arsa"}},
+		{Confidence: 0, Tapu: Tapu{Nitelik: "Bahçe"}},
+	}
+
+	got := findTapuConflicts(matches, []string{TapuFieldSayfa, TapuFieldNitelik, TapuFieldCilt}, 0.5)
+	want := []TapuConflict{
+		{Field: TapuFieldCilt, Values: []string{"12", "13"}},
+		{Field: TapuFieldSayfa, Values: []string{"345", "346"}},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("conflicts = %+v, want %+v", got, want)
+	}
+}
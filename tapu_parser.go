@@ -0,0 +1,332 @@
+package main
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+type TapuMalik struct {
+	Ad    string `json:"ad"`
+	Hisse string `json:"hisse"`
+}
+
+type Tapu struct {
+	Il           string      `json:"il"`
+	Ilce         string      `json:"ilce"`
+	Mahalle      string      `json:"mahalle"`
+	Mevki        string      `json:"mevki"`
+	Pafta        string      `json:"pafta"`
+	Ada          string      `json:"ada"`
+	Parsel       string      `json:"parsel"`
+	Nitelik      string      `json:"nitelik"`
+	Alan         float64     `json:"alan"`
+	Cilt         int         `json:"cilt"`
+	Sayfa        int         `json:"sayfa"`
+	EdinmeSebebi string      `json:"edinmeSebebi"`
+	Tarih        string      `json:"tarih"`
+	Yevmiye      string      `json:"yevmiye"`
+	Malikler     []TapuMalik `json:"malikler"`
+}
+
+// Names of the Tapu fields usable in the tapu field mapping
+const (
+	TapuFieldIl           = "İl"
+	TapuFieldIlce         = "İlçe"
+	TapuFieldMahalle      = "Mahalle"
+	TapuFieldMevki        = "Mevki"
+	TapuFieldPafta        = "Pafta"
+	TapuFieldAda          = "Ada"
+	TapuFieldParsel       = "Parsel"
+	TapuFieldNitelik      = "Nitelik"
+	TapuFieldAlan         = "Alan"
+	TapuFieldCilt         = "Cilt"
+	TapuFieldSayfa        = "Sayfa"
+	TapuFieldEdinmeSebebi = "Edinme Sebebi"
+	TapuFieldTarih        = "Tarih"
+	TapuFieldYevmiye      = "Yevmiye"
+	TapuFieldMalikler     = "Malikler"
+	TapuFieldMalikAdlari  = "Malik Adları"
+	TapuFieldHisseler     = "Hisseler"
+)
+
+var TapuFields = []string{
+	TapuFieldIl, TapuFieldIlce, TapuFieldMahalle, TapuFieldMevki, TapuFieldPafta, TapuFieldAda, TapuFieldParsel,
+	TapuFieldNitelik, TapuFieldAlan, TapuFieldCilt, TapuFieldSayfa, TapuFieldEdinmeSebebi, TapuFieldTarih,
+	TapuFieldYevmiye, TapuFieldMalikler, TapuFieldMalikAdlari, TapuFieldHisseler,
+}
+
+// Field returns the value of the named field, typed as int, float64 or string.
+// ok is false when the field is unknown or wasn't found in the document.
+func (t Tapu) Field(name string) (value interface{}, ok bool) {
+	field := tapuFieldName(name)
+
+	switch field {
+	case TapuFieldIl:
+		return t.Il, t.Il != ""
+	case TapuFieldIlce:
+		return t.Ilce, t.Ilce != ""
+	case TapuFieldMahalle:
+		return t.Mahalle, t.Mahalle != ""
+	case TapuFieldMevki:
+		return t.Mevki, t.Mevki != ""
+	case TapuFieldPafta:
+		return t.Pafta, t.Pafta != ""
+	case TapuFieldAda:
+		return t.Ada, t.Ada != ""
+	case TapuFieldParsel:
+		return t.Parsel, t.Parsel != ""
+	case TapuFieldNitelik:
+		return t.Nitelik, t.Nitelik != ""
+	case TapuFieldAlan:
+		return t.Alan, t.Alan != 0
+	case TapuFieldCilt:
+		return t.Cilt, t.Cilt != 0
+	case TapuFieldSayfa:
+		return t.Sayfa, t.Sayfa != 0
+	case TapuFieldEdinmeSebebi:
+		return t.EdinmeSebebi, t.EdinmeSebebi != ""
+	case TapuFieldTarih:
+		return t.Tarih, t.Tarih != ""
+	case TapuFieldYevmiye:
+		return t.Yevmiye, t.Yevmiye != ""
+	case TapuFieldMalikler, TapuFieldMalikAdlari, TapuFieldHisseler:
+		var values []string
+		for _, malik := range t.Malikler {
+			switch field {
+			case TapuFieldMalikler:
+				if malik.Hisse != "" {
+					values = append(values, malik.Ad+" ("+malik.Hisse+")")
+				} else {
+					values = append(values, malik.Ad)
+				}
+			case TapuFieldMalikAdlari:
+				values = append(values, malik.Ad)
+			case TapuFieldHisseler:
+				values = append(values, malik.Hisse)
+			}
+		}
+		return strings.Join(values, "; "), len(values) > 0
+	}
+
+	return nil, false
+}
+
+// tapuFieldName returns the canonical name of a tapu field, or "" if name isn't one
+func tapuFieldName(name string) string {
+	for _, field := range TapuFields {
+		if LooseEqual(field, name) {
+			return field
+		}
+	}
+	return ""
+}
+
+// trLabel builds a case-insensitive pattern for a label, treating the dotted
+// and dotless i variants as the same letter since regexp doesn't fold them
+func trLabel(label string) string {
+	var builder strings.Builder
+	for _, r := range label {
+		switch r {
+		case 'i', 'İ', 'ı', 'I':
+			builder.WriteString("[iİıI]")
+		case ' ':
+			builder.WriteString(`\s*`)
+		case '/':
+			builder.WriteString(`\s*/\s*`)
+		default:
+			builder.WriteString(regexp.QuoteMeta(string(r)))
+		}
+	}
+	return builder.String()
+}
+
+// tapuLabelRegexp matches "label : value", where value ends at a wide gap, the next label or the end of line
+func tapuLabelRegexp(labels ...string) *regexp.Regexp {
+	alternatives := make([]string, len(labels))
+	for i, label := range labels {
+		alternatives[i] = trLabel(label)
+	}
+	return regexp.MustCompile(`(?i)(?:^|\s)(?:` + strings.Join(alternatives, "|") + `)\s*:\s*(.*?)\s*(?:\s{3,}|$)`)
+}
+
+var (
+	tapuIlRegexp           = tapuLabelRegexp("İli", "İl")
+	tapuIlceRegexp         = tapuLabelRegexp("İlçesi", "İlçe")
+	tapuMahalleRegexp      = tapuLabelRegexp("Mahallesi/Köyü", "Mahalle/Köy", "Mahallesi", "Mahalle", "Köyü")
+	tapuMevkiRegexp        = tapuLabelRegexp("Sokağı/Mevkii", "Sokak/Mevki", "Mevkii", "Mevkisi", "Mevki")
+	tapuPaftaRegexp        = tapuLabelRegexp("Pafta No", "Paftası", "Pafta")
+	tapuAdaRegexp          = tapuLabelRegexp("Ada No", "Adası", "Ada")
+	tapuParselRegexp       = tapuLabelRegexp("Parsel No", "Parseli", "Parsel")
+	tapuNitelikRegexp      = tapuLabelRegexp("Niteliği", "Nitelik", "Cinsi")
+	tapuAlanRegexp         = tapuLabelRegexp("Yüzölçümü", "Yüzölçüm", "Yüz Ölçümü", "Yüzölçümü (m2)")
+	tapuCiltSayfaRegexp    = tapuLabelRegexp("Cilt No/Sayfa No", "Cilt/Sayfa No", "Cilt/Sayfa")
+	tapuCiltRegexp         = tapuLabelRegexp("Cilt No", "Cilt")
+	tapuSayfaRegexp        = tapuLabelRegexp("Sayfa No", "Sayfa")
+	tapuEdinmeSebebiRegexp = tapuLabelRegexp("Edinme Sebebi", "Edinme Nedeni", "Edinim Sebebi")
+	tapuTarihYevmiyeRegexp = tapuLabelRegexp("Tarih/Yevmiye No", "Tarih/Yevmiye", "Tarih-Yevmiye")
+	tapuTarihRegexp        = tapuLabelRegexp("Tarihi", "Tarih")
+	tapuYevmiyeRegexp      = tapuLabelRegexp("Yevmiye No", "Yevmiye")
+	tapuMalikRegexp        = tapuLabelRegexp("Malik Adı Soyadı", "Malik Adı", "Maliki", "Malik")
+	tapuHisseRegexp        = tapuLabelRegexp("Hisse Oranı", "Hissesi", "Hisse Pay/Payda", "Hisse")
+
+	tapuNumberRegexp   = regexp.MustCompile(`\d[\d.]*(?:,\d+)?`)
+	tapuDateRegexp     = regexp.MustCompile(`\d{1,2}[./-]\d{1,2}[./-]\d{4}`)
+	tapuIntegerRegexp  = regexp.MustCompile(`\d+`)
+	tapuFractionRegexp = regexp.MustCompile(`\d+\s*/\s*\d+|(?i:\btam\b)`)
+)
+
+// parseTapuText extracts the tapu senedi fields from the plain text of the document. Values that
+// can't be read are left empty and described in warnings, the other fields are still parsed.
+func parseTapuText(content string) (tapu Tapu, warnings []string, err error) {
+	found := false
+
+	match := func(re *regexp.Regexp, line string) (string, bool) {
+		m := re.FindStringSubmatch(line)
+		if m == nil || strings.TrimSpace(m[1]) == "" {
+			return "", false
+		}
+		return strings.TrimSpace(m[1]), true
+	}
+
+	for _, line := range strings.Split(content, "\n") {
+		line = strings.TrimRight(line, "\r\f")
+
+		if value, ok := match(tapuIlRegexp, line); ok && tapu.Il == "" {
+			tapu.Il = toTitleCaseWord(value)
+			found = true
+		}
+		if value, ok := match(tapuIlceRegexp, line); ok && tapu.Ilce == "" {
+			tapu.Ilce = toTitleCaseWord(value)
+			found = true
+		}
+		if value, ok := match(tapuMahalleRegexp, line); ok && tapu.Mahalle == "" {
+			tapu.Mahalle = toTitleCaseWord(value)
+			found = true
+		}
+		if value, ok := match(tapuMevkiRegexp, line); ok && tapu.Mevki == "" {
+			tapu.Mevki = toTitleCaseWord(value)
+			found = true
+		}
+		if value, ok := match(tapuPaftaRegexp, line); ok && tapu.Pafta == "" {
+			tapu.Pafta = value
+			found = true
+		}
+		if value, ok := match(tapuAdaRegexp, line); ok && tapu.Ada == "" {
+			tapu.Ada = tapuIntegerRegexp.FindString(value)
+			found = true
+		}
+		if value, ok := match(tapuParselRegexp, line); ok && tapu.Parsel == "" {
+			tapu.Parsel = tapuIntegerRegexp.FindString(value)
+			found = true
+		}
+		if value, ok := match(tapuNitelikRegexp, line); ok && tapu.Nitelik == "" {
+			tapu.Nitelik = toTitleCaseWord(value)
+			found = true
+		}
+		if value, ok := match(tapuAlanRegexp, line); ok && tapu.Alan == 0 {
+			alan, err := parseTurkishNumber(tapuNumberRegexp.FindString(value))
+			if err != nil {
+				warnings = append(warnings, "invalid yüzölçümü: "+value)
+			} else {
+				tapu.Alan = alan
+			}
+			found = true
+		}
+
+		if value, ok := match(tapuCiltSayfaRegexp, line); ok && tapu.Cilt == 0 {
+			numbers := tapuIntegerRegexp.FindAllString(value, 2)
+			if len(numbers) == 2 {
+				tapu.Cilt, _ = strconv.Atoi(numbers[0])
+				tapu.Sayfa, _ = strconv.Atoi(numbers[1])
+			} else {
+				warnings = append(warnings, "invalid cilt/sayfa: "+value)
+			}
+			found = true
+		} else {
+			if value, ok := match(tapuCiltRegexp, line); ok && tapu.Cilt == 0 {
+				tapu.Cilt, _ = strconv.Atoi(tapuIntegerRegexp.FindString(value))
+				found = true
+			}
+			if value, ok := match(tapuSayfaRegexp, line); ok && tapu.Sayfa == 0 {
+				tapu.Sayfa, _ = strconv.Atoi(tapuIntegerRegexp.FindString(value))
+				found = true
+			}
+		}
+
+		if value, ok := match(tapuEdinmeSebebiRegexp, line); ok && tapu.EdinmeSebebi == "" {
+			tapu.EdinmeSebebi = value
+			found = true
+		}
+
+		if value, ok := match(tapuTarihYevmiyeRegexp, line); ok && tapu.Yevmiye == "" {
+			tapu.Tarih = tapuDateRegexp.FindString(value)
+			rest := strings.Replace(value, tapu.Tarih, "", 1)
+			tapu.Yevmiye = tapuIntegerRegexp.FindString(rest)
+			found = true
+		} else {
+			if value, ok := match(tapuTarihRegexp, line); ok && tapu.Tarih == "" {
+				tapu.Tarih = tapuDateRegexp.FindString(value)
+				found = true
+			}
+			if value, ok := match(tapuYevmiyeRegexp, line); ok && tapu.Yevmiye == "" {
+				tapu.Yevmiye = tapuIntegerRegexp.FindString(value)
+				found = true
+			}
+		}
+
+		// Every malik line adds an owner, a hisse without a malik belongs to the previous owner
+		hisse := ""
+		if value, ok := match(tapuHisseRegexp, line); ok {
+			hisse = normalizeHisse(tapuFractionRegexp.FindString(value))
+		}
+
+		if value, ok := match(tapuMalikRegexp, line); ok {
+			// The hisse is sometimes printed after the name on the same line
+			if hisse == "" {
+				if fraction := tapuFractionRegexp.FindString(value); fraction != "" {
+					hisse = normalizeHisse(fraction)
+					value = strings.Replace(value, fraction, "", 1)
+					value = strings.Trim(value, " ()-:")
+				}
+			}
+
+			tapu.Malikler = append(tapu.Malikler, TapuMalik{Ad: strings.Join(strings.Fields(value), " "), Hisse: hisse})
+			found = true
+		} else if hisse != "" && len(tapu.Malikler) > 0 && tapu.Malikler[len(tapu.Malikler)-1].Hisse == "" {
+			tapu.Malikler[len(tapu.Malikler)-1].Hisse = hisse
+		}
+	}
+
+	if !found {
+		return tapu, warnings, fmt.Errorf("Tapu bilgisi bulunamadı")
+	}
+
+	return tapu, warnings, nil
+}
+
+// parseTurkishNumber parses numbers like "1.234,56 m2". Without a comma a single dot followed
+// by exactly three digits is a thousands separator, any other single dot a decimal point.
+func parseTurkishNumber(value string) (float64, error) {
+	value = strings.TrimSpace(value)
+	value = strings.TrimSuffix(value, "m²")
+	value = strings.TrimSuffix(value, "m2")
+	value = strings.Join(strings.Fields(value), "")
+
+	if strings.Contains(value, ",") || strings.Count(value, ".") > 1 {
+		value = strings.ReplaceAll(value, ".", "")
+		value = strings.ReplaceAll(value, ",", ".")
+	} else if dot := strings.IndexByte(value, '.'); dot != -1 && len(value)-dot-1 == 3 {
+		value = strings.ReplaceAll(value, ".", "")
+	}
+
+	return strconv.ParseFloat(value, 64)
+}
+
+func normalizeHisse(hisse string) string {
+	if strings.EqualFold(strings.TrimSpace(hisse), "tam") {
+		return "1/1"
+	}
+	return strings.Join(strings.Fields(hisse), "")
+}
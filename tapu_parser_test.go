@@ -0,0 +1,109 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func readTapuFixture(t *testing.T, name string) string {
+	t.Helper()
+
+	data, err := os.ReadFile(filepath.Join("testdata", "tapu", name))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The fixtures are pdftotext output, the PDF readers normalize it the same way
+	return normalizePDFText(string(data))
+}
+
+func TestParseTapuText(t *testing.T) {
+	tests := []struct {
+		fixture  string
+		want     Tapu
+		warnings []string
+		wantErr  bool
+	}{
+		{
+			fixture: "etapu.txt",
+			want: Tapu{
+				Il: "Konya", Ilce: "Selçuklu", Mahalle: "Bosna Hersek", Mevki: "Çayırlık", Pafta: "27L-IV",
+				Ada: "12345", Parsel: "7", Nitelik: "Bahçeli Kargir Ev", Alan: 1234.56, Cilt: 45, Sayfa: 4412,
+				EdinmeSebebi: "Satış", Tarih: "12.05.2019", Yevmiye: "8765",
+				Malikler: []TapuMalik{{Ad: "AHMET YILMAZ", Hisse: "1/2"}, {Ad: "AYŞE YILMAZ", Hisse: "1/2"}},
+			},
+		},
+		{
+			// Ý, Þ and Ð of a Windows-1252 font, separate cilt and sayfa lines, hisse after the name
+			// and on the next line
+			fixture: "glyphs.txt",
+			want: Tapu{
+				Il: "Konya", Ilce: "Meram", Mahalle: "Şehitler", Mevki: "Dağbaşı", Pafta: "12",
+				Ada: "0", Parsel: "318", Nitelik: "Tarla", Alan: 25000, Cilt: 3, Sayfa: 287,
+				EdinmeSebebi: "Miras", Tarih: "03/11/1998", Yevmiye: "1422",
+				Malikler: []TapuMalik{{Ad: "ŞÜKRÜ DOĞAN", Hisse: "1/3"}, {Ad: "GÜLŞEN DOĞAN", Hisse: "2/3"}},
+			},
+		},
+		{
+			// Unreadable values are reported and the rest of the document is still parsed
+			fixture: "bad_area.txt",
+			want: Tapu{
+				Il: "Konya", Ilce: "Karatay", Mahalle: "Fetihkent", Mevki: "Orta",
+				Ada: "402", Parsel: "15", Nitelik: "Arsa",
+				EdinmeSebebi: "Tapu Tahsis", Tarih: "01.02.2021", Yevmiye: "333",
+				Malikler: []TapuMalik{{Ad: "HAZİNE", Hisse: "1/1"}},
+			},
+			warnings: []string{"invalid yüzölçümü: OKUNAMADI", "invalid cilt/sayfa: 7"},
+		},
+		{
+			fixture: "empty.txt",
+			wantErr: true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.fixture, func(t *testing.T) {
+			tapu, warnings, err := parseTapuText(readTapuFixture(t, test.fixture))
+
+			if (err != nil) != test.wantErr {
+				t.Fatalf("err = %v, want error %v", err, test.wantErr)
+			}
+			if test.wantErr {
+				return
+			}
+			if !reflect.DeepEqual(tapu, test.want) {
+				t.Errorf("tapu = %+v\nwant   %+v", tapu, test.want)
+			}
+			if !reflect.DeepEqual(warnings, test.warnings) {
+				t.Errorf("warnings = %q, want %q", warnings, test.warnings)
+			}
+		})
+	}
+}
+
+func TestParseTurkishNumber(t *testing.T) {
+	tests := []struct {
+		value string
+		want  float64
+	}{
+		{"1.234,56 m²", 1234.56},
+		{"25.000 m2", 25000},
+		{"1.234.567", 1234567},
+		{"12,5", 12.5},
+		{"12.5", 12.5},
+		{"850", 850},
+	}
+
+	for _, test := range tests {
+		got, err := parseTurkishNumber(test.value)
+		if err != nil || got != test.want {
+			t.Errorf("parseTurkishNumber(%q) = %v, %v, want %v", test.value, got, err, test.want)
+		}
+	}
+
+	if _, err := parseTurkishNumber("OKUNAMADI"); err == nil {
+		t.Error("parseTurkishNumber(\"OKUNAMADI\") should fail")
+	}
+}
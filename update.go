@@ -43,6 +43,12 @@ func (app *App) CheckForUpdate() UpdateInfo {
 	lastUpdateCheck := int(time.Now().Unix())
 	config.LastUpdateCheck = &lastUpdateCheck
 
+	assetName, err := updateAssetName()
+	if err != nil {
+		runtime.LogInfo(app.ctx, "Skipping update check: "+err.Error())
+		return updateInfo
+	}
+
 	repoOwner := "beyenilmez"
 	repoName := "folder-creator"
 
@@ -87,7 +93,7 @@ func (app *App) CheckForUpdate() UpdateInfo {
 	releaseNotes := release.ReleaseNotes
 	prerelease := release.Prerelease
 	name := release.Name
-	downloadUrl := fmt.Sprintf("https://github.com/%s/%s/releases/download/%s/folder-creator.exe", repoOwner, repoName, latestVersion)
+	downloadUrl := fmt.Sprintf("https://github.com/%s/%s/releases/download/%s/%s", repoOwner, repoName, latestVersion, assetName)
 
 	// Parse current and latest versions
 	parsedVersion, err := semver.ParseTolerant(version)
@@ -127,6 +133,13 @@ func (app *App) CheckForUpdate() UpdateInfo {
 }
 
 func (app *App) Update(downloadUrl string) error {
+	// The release binary only runs on the platform it was built for
+	if _, err := updateAssetName(); err != nil {
+		runtime.LogError(app.ctx, err.Error())
+		app.SendNotification("settings.setting.update.failed_to_apply_update", err.Error(), "", "error")
+		return err
+	}
+
 	// Log the download URL
 	runtime.LogInfo(app.ctx, "Starting update download from: "+downloadUrl)
 